@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// ParseEntries() : 1行に1つ「公開鍵 ハッシュ r s」を空白区切りの16進数で書いた署名のダンプを読む
+// 公開鍵は SEC1 の圧縮形式か非圧縮形式で、空行と # で始まる行は無視する
+// r, s 以外は1バイトずつ2桁で書く
+func ParseEntries(ec *models.EllipticCurve, r io.Reader) ([]*Entry, error) {
+	n := ec.Params().N
+	var entries []*Entry
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("%w: line %d: want 4 fields, got %d", ErrInvalidEntry, line, len(fields))
+		}
+		pubBytes, err := hex.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: public key: %v", ErrInvalidEntry, line, err)
+		}
+		hash, err := hex.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: hash: %v", ErrInvalidEntry, line, err)
+		}
+		pub, err := ecdsa.ParsePublicKey(ec, pubBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidEntry, line, err)
+		}
+
+		// r, s は先頭の0が省かれていてもよい
+		rv, ok1 := new(big.Int).SetString(fields[2], 16)
+		sv, ok2 := new(big.Int).SetString(fields[3], 16)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("%w: line %d: r or s is not a hex number", ErrInvalidEntry, line)
+		}
+		if rv.Sign() <= 0 || rv.Cmp(n) >= 0 || sv.Sign() <= 0 || sv.Cmp(n) >= 0 {
+			return nil, fmt.Errorf("%w: line %d: r or s is not in [1, N)", ErrInvalidEntry, line)
+		}
+		sig := &ecdsa.Signature{R: models.NewFiniteField(rv, n), S: models.NewFiniteField(sv, n)}
+
+		entries = append(entries, &Entry{PublicKey: pub, Hash: hash, Signature: sig})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
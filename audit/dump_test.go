@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+)
+
+// formatEntry() : ParseEntries() が読む形式の1行
+func formatEntry(e *Entry) string {
+	return fmt.Sprintf("%x %x %x %x", e.PublicKey.Q.MarshalCompressed(), e.Hash, e.Signature.R.Value, e.Signature.S.Value)
+}
+
+func Test_ParseEntries(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv := mustKey(ec, 0x5eed)
+	k := mustKey(ec, 0x1234).D.Value
+	e1, e2 := signWithNonce(t, priv, "a", k), signWithNonce(t, priv, "b", k)
+	uncompressed := fmt.Sprintf("%x %x %x %x", priv.Q.MarshalUncompressed(), e2.Hash, e2.Signature.R.Value, e2.Signature.S.Value)
+
+	pub := hex.EncodeToString(priv.Q.MarshalCompressed())
+	hash := hex.EncodeToString(e1.Hash)
+	n := fmt.Sprintf("%x", ec.Params().N)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr error
+	}{
+		{name: "empty", input: "", want: 0},
+		{name: "entries", input: "# pubkey hash r s\n" + formatEntry(e1) + "\n\n  " + uncompressed + "  \n", want: 2},
+		{name: "r and s without leading zeros", input: pub + " " + hash + " 1 abc\n", want: 1},
+		{name: "too few fields", input: pub + " " + hash + " 01\n", wantErr: ErrInvalidEntry},
+		{name: "invalid hex", input: pub + " " + hash + " 0g 01\n", wantErr: ErrInvalidEntry},
+		{name: "odd length hash", input: pub + " " + hash[1:] + " 01 01\n", wantErr: ErrInvalidEntry},
+		{name: "invalid public key", input: "02" + strings.Repeat("00", 32) + " " + hash + " 01 01\n", wantErr: ErrInvalidEntry},
+		{name: "r = 0", input: pub + " " + hash + " 00 01\n", wantErr: ErrInvalidEntry},
+		{name: "s = N", input: pub + " " + hash + " 01 " + n + "\n", wantErr: ErrInvalidEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEntries(ec, strings.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : ParseEntries() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("%v : ParseEntries() returned %v entries, want %v", tt.name, len(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_ParseEntries_FindNonceReuse(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv := mustKey(ec, 0x5eed)
+	k := mustKey(ec, 0x1234).D.Value
+
+	dump := strings.Join([]string{
+		formatEntry(signWithNonce(t, priv, "a", k)),
+		formatEntry(signRandom(t, priv, "b")),
+		formatEntry(signWithNonce(t, priv, "c", k)),
+	}, "\n")
+	entries, err := ParseEntries(ec, strings.NewReader(dump))
+	if err != nil {
+		t.Fatalf("ParseEntries() err = %v", err)
+	}
+
+	findings := FindNonceReuse(entries)
+	if len(findings) != 1 || len(findings[0].Keys) != 1 || !findings[0].Keys[0].D.Equals(priv.D) {
+		t.Fatalf("FindNonceReuse() = %+v", findings)
+	}
+	if fmt.Sprint(findings[0].Entries) != "[0 2]" {
+		t.Errorf("FindNonceReuse()[0].Entries = %v, want [0 2]", findings[0].Entries)
+	}
+}
@@ -0,0 +1,217 @@
+package audit
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/lattice"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// HNPOption : RecoverFromBiasedNonces() の挙動を変更するオプション
+type HNPOption func(*hnpConfig)
+
+type hnpConfig struct {
+	blockSize int
+}
+
+// WithBKZ() : LLL で秘密鍵が求まらなかった場合に使う BKZ のブロックサイズを指定する (既定値は 10、0 なら BKZ を使わない)
+func WithBKZ(blockSize int) HNPOption {
+	return func(c *hnpConfig) {
+		c.blockSize = blockSize
+	}
+}
+
+// RecoverFromBiasedNonces() : 同じ公開鍵の署名で、nonce k の上位 bits ビットが0だと分かっている場合に、
+// 隠れた数の問題 (Hidden Number Problem) を格子の短いベクトルを求める問題にして解き、秘密鍵を求める
+//
+// s_i*k_i = z_i + r_i*d より、t_i = r_i/s_i, u_i = z_i/s_i として k_i ≡ t_i*d + u_i (mod N)
+// 1つ目の署名で d を消すと、t'_i = t_i/t_0, u'_i = u_i - t'_i*u_0 として k_i ≡ t'_i*k_0 + u'_i (mod N) (i >= 1)
+// low-S に正規化された署名では k_i が -k_i になっていることがあるので、|k_i| < B = 2^(bitLen(N) - bits) とする
+// 次の格子は、短いベクトル (k_1, ..., k_(m-1), k_0, B) を含む
+//
+//	| N                            |
+//	|      ...                     |
+//	|           N                  |
+//	| t'_1 ... t'_(m-1)   1        |
+//	| u'_1 ... u'_(m-1)   0     B  |
+//
+// 符号の分の1ビットを除いて、署名の数 m が bitLen(N) / (bits - 1) より十分大きくないと求まらない
+// 使うのは先頭の maxHNPSignatures() 個までで、求まらなければ ErrNotRecovered
+func RecoverFromBiasedNonces(entries []*Entry, bits int, opts ...HNPOption) (*ecdsa.PrivateKey, error) {
+	c := hnpConfig{blockSize: 10}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if len(entries) < 2 {
+		return nil, fmt.Errorf("%w: need at least 2 signatures", ErrInvalidEntry)
+	}
+	for _, e := range entries {
+		if _, _, _, err := e.values(); err != nil {
+			return nil, err
+		}
+		if !sameKey(e.PublicKey, entries[0].PublicKey) {
+			return nil, fmt.Errorf("%w: the signatures are from different public keys", ErrInvalidEntry)
+		}
+	}
+	n := entries[0].PublicKey.Curve.Params().N
+	if bits <= 1 || bits >= n.BitLen() {
+		return nil, fmt.Errorf("%w: bits must be in [2, %d)", ErrInvalidEntry, n.BitLen())
+	}
+	if limit := maxHNPSignatures(n.BitLen(), bits); len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	basis, B := hnpBasis(entries, bits)
+	if err := lattice.LLL(basis); err != nil {
+		return nil, err
+	}
+	if priv, ok := keyFromBasis(entries[0], basis, B); ok {
+		return priv, nil
+	}
+
+	if c.blockSize >= 2 {
+		if err := lattice.BKZ(basis, c.blockSize); err != nil {
+			return nil, err
+		}
+		if priv, ok := keyFromBasis(entries[0], basis, B); ok {
+			return priv, nil
+		}
+	}
+	return nil, ErrNotRecovered
+}
+
+// maxHNPSignatures() : 格子に使う署名の数の上限
+// 整数だけで計算する LLL() は次元が大きくなるとすぐに遅くなるので、必要な数の見積もりより8個多いところまでにする
+func maxHNPSignatures(nBits, bits int) int {
+	return nBits/(bits-1) + 1 + 8
+}
+
+// hnpBasis() : RecoverFromBiasedNonces() の格子の基底と B = 2^(bitLen(N) - bits)
+func hnpBasis(entries []*Entry, bits int) (basis [][]*big.Int, B *big.Int) {
+	n := entries[0].PublicKey.Curve.Params().N
+	m := len(entries)
+	B = new(big.Int).Lsh(big.NewInt(1), uint(n.BitLen()-bits))
+
+	basis = make([][]*big.Int, m+1)
+	for i := range basis {
+		basis[i] = make([]*big.Int, m+1)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Int)
+		}
+	}
+
+	z0, r0, s0, _ := entries[0].values()
+	t0 := new(models.FiniteField).Div(r0, s0)
+	u0 := new(models.FiniteField).Div(z0, s0)
+	for i, e := range entries[1:] {
+		z, r, s, _ := e.values()
+		t := new(models.FiniteField).Div(r, s)
+		t.Div(t, t0)
+		u := new(models.FiniteField).Div(z, s)
+		u.Sub(u, new(models.FiniteField).Mul(t, u0))
+
+		basis[i][i].Set(n)
+		basis[m-1][i].Set(t.Value)
+		basis[m][i].Set(u.Value)
+	}
+	basis[m-1][m-1].SetInt64(1)
+	basis[m][m].Set(B)
+	return basis, B
+}
+
+// keyFromBasis() : 簡約した基底から最後の成分が ±B の行を探し、その行の k_0 から d = (s_0*k_0 - z_0) / r_0 を求める
+func keyFromBasis(e0 *Entry, basis [][]*big.Int, B *big.Int) (*ecdsa.PrivateKey, bool) {
+	z, r, s, _ := e0.values()
+	n := r.Prime
+	m := len(basis) - 1
+	for _, row := range basis {
+		if new(big.Int).Abs(row[m]).Cmp(B) != 0 {
+			continue
+		}
+		k := new(big.Int).Set(row[m-1])
+		if row[m].Sign() < 0 {
+			k.Neg(k)
+		}
+		k0 := models.NewFiniteField(k.Mod(k, n), n)
+		d := new(models.FiniteField).Mul(s, k0)
+		d.Sub(d, z)
+		d.Div(d, r)
+		if priv, ok := matchKey(e0.PublicKey, d); ok {
+			return priv, true
+		}
+	}
+	return nil, false
+}
+
+// BiasedFinding : nonceの上位ビットが0だったことから秘密鍵が求まった公開鍵
+type BiasedFinding struct {
+	Entries []int // 格子に使った署名の entries での添字 (昇順)
+	Key     *ecdsa.PrivateKey
+}
+
+// FindBiasedNonces() : 公開鍵ごとに、すべての署名のnonceの上位 bits ビットが0だと仮定して RecoverFromBiasedNonces() で秘密鍵を求める
+// 署名が2つ未満の公開鍵と、不正なエントリは無視する
+func FindBiasedNonces(entries []*Entry, bits int, opts ...HNPOption) []BiasedFinding {
+	var findings []BiasedFinding
+	for _, indices := range groupByKey(entries) {
+		if len(indices) < 2 {
+			continue
+		}
+		group := make([]*Entry, len(indices))
+		for i, x := range indices {
+			group[i] = entries[x]
+		}
+		priv, err := RecoverFromBiasedNonces(group, bits, opts...)
+		if err != nil {
+			continue
+		}
+		if limit := maxHNPSignatures(priv.Curve.Params().N.BitLen(), bits); len(indices) > limit {
+			indices = indices[:limit]
+		}
+		findings = append(findings, BiasedFinding{Entries: indices, Key: priv})
+	}
+	return findings
+}
+
+// GenerateBiasedSignatures() : 上位 bits ビットが0のnonceで、ランダムなメッセージに count 個の署名を作る
+// RecoverFromBiasedNonces() のテストや実演のためのもので、nonceが偏った署名は秘密鍵が漏洩する
+func GenerateBiasedSignatures(priv *ecdsa.PrivateKey, count, bits int, random io.Reader) ([]*Entry, error) {
+	n := priv.Curve.Params().N
+	if bits < 0 || bits >= n.BitLen() {
+		return nil, fmt.Errorf("audit: bits must be in [0, %d)", n.BitLen())
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(n.BitLen()-bits))
+
+	entries := make([]*Entry, 0, count)
+	for len(entries) < count {
+		msg := make([]byte, 32)
+		if _, err := io.ReadFull(random, msg); err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(msg)
+
+		k, err := rand.Int(random, bound)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() == 0 {
+			continue
+		}
+		sig, err := ecdsa.Sign(priv, hash[:], ecdsa.WithNonce(k))
+		if err != nil {
+			// r == 0 または s == 0 となるkだった場合は、選び直す
+			continue
+		}
+		entries = append(entries, &Entry{PublicKey: &priv.PublicKey, Hash: hash[:], Signature: sig})
+	}
+	return entries, nil
+}
@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// biased() : 再現できるように、シードを固定した乱数で偏ったnonceの署名を作る
+func biased(t testing.TB, priv *ecdsa.PrivateKey, count, bits int, seed int64) []*Entry {
+	t.Helper()
+	entries, err := GenerateBiasedSignatures(priv, count, bits, mrand.New(mrand.NewSource(seed)))
+	if err != nil {
+		t.Fatalf("GenerateBiasedSignatures() err = %v", err)
+	}
+	return entries
+}
+
+func Test_GenerateBiasedSignatures(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	priv := mustKey(ec, 0x5eed)
+
+	for _, bits := range []int{0, 8, 64, 200} {
+		entries := biased(t, priv, 8, bits, int64(bits))
+		if len(entries) != 8 {
+			t.Fatalf("bits = %v : GenerateBiasedSignatures() returned %v entries, want 8", bits, len(entries))
+		}
+		bound := new(big.Int).Lsh(big.NewInt(1), uint(n.BitLen()-bits))
+		for i, e := range entries {
+			// k = (z + r*d) / s は low-S への正規化で N - k になっていることがある
+			z, r, s, err := e.values()
+			if err != nil {
+				t.Fatalf("bits = %v : GenerateBiasedSignatures()[%d] err = %v", bits, i, err)
+			}
+			k := new(models.FiniteField).Mul(r, priv.D)
+			k.Add(k, z)
+			k.Div(k, s)
+			if err := checkNonce(e, priv, k.Value); err != nil {
+				t.Errorf("bits = %v : GenerateBiasedSignatures()[%d] : %v", bits, i, err)
+			}
+			small := new(big.Int).Sub(n, k.Value)
+			if k.Value.Cmp(small) < 0 {
+				small = k.Value
+			}
+			if small.Cmp(bound) >= 0 {
+				t.Errorf("bits = %v : GenerateBiasedSignatures()[%d] nonce = %x, want < %x", bits, i, small, bound)
+			}
+		}
+	}
+
+	if _, err := GenerateBiasedSignatures(priv, 1, n.BitLen(), nil); err == nil {
+		t.Errorf("GenerateBiasedSignatures(bits = %v) err = nil, want error", n.BitLen())
+	}
+}
+
+func Test_RecoverFromBiasedNonces(t *testing.T) {
+	secp256k1, p256 := curves.Secp256k1(), curves.P256()
+	priv := mustKey(secp256k1, 0x5eed1234)
+	other := mustKey(secp256k1, 0x5eed5678)
+	privP256 := mustKey(p256, 0x5eed1234)
+
+	var unbiased []*Entry
+	for _, msg := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		unbiased = append(unbiased, signRandom(t, priv, msg))
+	}
+
+	tests := []struct {
+		name    string
+		entries []*Entry
+		bits    int
+		opts    []HNPOption
+		slow    bool
+		want    *ecdsa.PrivateKey
+		wantErr error
+	}{
+		{name: "32 bits", entries: biased(t, priv, 10, 32, 1), bits: 32, want: priv},
+		{name: "32 bits without BKZ", entries: biased(t, priv, 10, 32, 2), bits: 32, opts: []HNPOption{WithBKZ(0)}, want: priv},
+		{name: "more than enough signatures", entries: biased(t, priv, 30, 32, 3), bits: 32, want: priv},
+		{name: "P-256", entries: biased(t, privP256, 10, 32, 4), bits: 32, want: privP256},
+		{name: "16 bits", entries: biased(t, priv, 18, 16, 5), bits: 16, slow: true, want: priv},
+		{name: "too few signatures", entries: biased(t, priv, 6, 32, 6), bits: 32, wantErr: ErrNotRecovered},
+		{name: "bias overestimated", entries: biased(t, priv, 10, 16, 7), bits: 32, wantErr: ErrNotRecovered},
+		{name: "unbiased", entries: unbiased, bits: 32, wantErr: ErrNotRecovered},
+		{name: "one signature", entries: biased(t, priv, 1, 32, 8), bits: 32, wantErr: ErrInvalidEntry},
+		{name: "different keys", entries: append(biased(t, priv, 5, 32, 9), biased(t, other, 5, 32, 9)...), bits: 32, wantErr: ErrInvalidEntry},
+		{name: "bits too small", entries: biased(t, priv, 10, 32, 10), bits: 1, wantErr: ErrInvalidEntry},
+		{name: "bits too large", entries: biased(t, priv, 10, 32, 11), bits: 256, wantErr: ErrInvalidEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.slow && testing.Short() {
+				t.Skip("skipping in short mode")
+			}
+			got, err := RecoverFromBiasedNonces(tt.entries, tt.bits, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : RecoverFromBiasedNonces() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !got.D.Equals(tt.want.D) {
+				t.Errorf("%v : RecoverFromBiasedNonces() d = %v, want %v", tt.name, got.D, tt.want.D)
+			}
+		})
+	}
+}
+
+func Test_FindBiasedNonces(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv1, priv2, other := mustKey(ec, 0x1111), mustKey(ec, 0x2222), mustKey(ec, 0x3333)
+
+	es1, es2 := biased(t, priv1, 10, 32, 1), biased(t, priv2, 12, 32, 2)
+	var entries []*Entry
+	var want1, want2 []int
+	for i := 0; i < 12; i++ {
+		if i < len(es1) {
+			want1 = append(want1, len(entries))
+			entries = append(entries, es1[i])
+		}
+		want2 = append(want2, len(entries))
+		entries = append(entries, es2[i])
+		if i < 4 {
+			entries = append(entries, signRandom(t, other, string(rune('a'+i))))
+		}
+	}
+	// 不正なエントリは無視される
+	entries = append(entries, &Entry{PublicKey: &priv1.PublicKey})
+
+	got := FindBiasedNonces(entries, 32)
+	want := []struct {
+		entries []int
+		key     *ecdsa.PrivateKey
+	}{
+		{entries: want1, key: priv1},
+		{entries: want2, key: priv2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("FindBiasedNonces() returned %v findings, want %v", len(got), len(want))
+	}
+	for i, f := range got {
+		if fmt.Sprint(f.Entries) != fmt.Sprint(want[i].entries) {
+			t.Errorf("FindBiasedNonces()[%d].Entries = %v, want %v", i, f.Entries, want[i].entries)
+		}
+		if !f.Key.D.Equals(want[i].key.D) {
+			t.Errorf("FindBiasedNonces()[%d].Key = %v, want %v", i, f.Key.D, want[i].key.D)
+		}
+	}
+}
+
+func BenchmarkRecoverFromBiasedNonces(b *testing.B) {
+	priv := mustKey(curves.Secp256k1(), 0x5eed1234)
+	for _, bc := range []struct {
+		name        string
+		count, bits int
+	}{
+		{name: "32bits-10sigs", count: 10, bits: 32},
+		{name: "16bits-18sigs", count: 18, bits: 16},
+	} {
+		entries := biased(b, priv, bc.count, bc.bits, 1)
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := RecoverFromBiasedNonces(entries, bc.bits); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+// audit : ECDSA署名のダンプから、nonce kの使い方の誤りで漏洩した秘密鍵を探す
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+var (
+	ErrInvalidEntry = errors.New("audit: invalid entry")
+	ErrNotRecovered = errors.New("audit: private key not recovered")
+)
+
+// Entry : 監査する署名1つ (公開鍵, メッセージのハッシュ, 署名 (r, s))
+type Entry struct {
+	PublicKey *ecdsa.PublicKey
+	Hash      []byte
+	Signature *ecdsa.Signature
+}
+
+// values() : 位数Nを法とする z, r, s
+func (e *Entry) values() (z, r, s *models.FiniteField, err error) {
+	if e == nil || e.PublicKey == nil || e.PublicKey.Curve == nil || e.PublicKey.Q == nil || e.Signature == nil {
+		return nil, nil, nil, fmt.Errorf("%w: missing public key or signature", ErrInvalidEntry)
+	}
+	n := e.PublicKey.Curve.Params().N
+	if !e.Signature.InRange(n) {
+		return nil, nil, nil, fmt.Errorf("%w: r or s is not in [1, N)", ErrInvalidEntry)
+	}
+	return models.NewFiniteField(ecdsa.HashToInt(e.Hash, n), n), e.Signature.R, e.Signature.S, nil
+}
+
+// RecoverFromNonceReuse() : 同じ公開鍵で同じnonce kを使った2つの署名から、秘密鍵dと1つ目の署名のkを求める
+// s_i = (z_i + r*d) / k なので
+//
+//	k = (z1 - z2) / (s1 - s2)
+//	d = (s1*k - z1) / r
+//
+// low-S に正規化された署名では s2 の符号が反転していることがあるので、s1 + s2 の場合も試す
+// 求めたdが公開鍵と一致しなければ ErrNotRecovered
+func RecoverFromNonceReuse(e1, e2 *Entry) (priv *ecdsa.PrivateKey, k *big.Int, err error) {
+	if err := checkPair(e1, e2); err != nil {
+		return nil, nil, err
+	}
+	if !e1.Signature.R.Equals(e2.Signature.R) {
+		return nil, nil, fmt.Errorf("%w: r differs, the nonces are not the same", ErrNotRecovered)
+	}
+	n := e1.PublicKey.Curve.Params().N
+	return recoverRelated(e1, e2, models.NewFiniteField(big.NewInt(1), n), models.NewFiniteField(big.NewInt(0), n))
+}
+
+// RecoverFromRelatedNonces() : 同じ公開鍵で k2 = a*k1 + b (mod N) となるnonceを使った2つの署名から、
+// 秘密鍵dと1つ目の署名のk1を求める (a == 1, b == 0 ならnonceの使い回し)
+// k_i = (z_i + r_i*d) / s_i を k2 = a*k1 + b に代入すると
+//
+//	d = (s1*z2 - a*s2*z1 - b*s1*s2) / (a*s2*r1 - s1*r2)
+//
+// s1, s2 の符号の組み合わせ4通りを試し、求めたdが公開鍵と一致しなければ ErrNotRecovered
+func RecoverFromRelatedNonces(e1, e2 *Entry, a, b *big.Int) (priv *ecdsa.PrivateKey, k1 *big.Int, err error) {
+	if err := checkPair(e1, e2); err != nil {
+		return nil, nil, err
+	}
+	n := e1.PublicKey.Curve.Params().N
+	return recoverRelated(e1, e2,
+		models.NewFiniteField(new(big.Int).Mod(a, n), n),
+		models.NewFiniteField(new(big.Int).Mod(b, n), n),
+	)
+}
+
+// checkPair() : 2つの署名が同じ曲線の同じ公開鍵のものかどうか
+func checkPair(e1, e2 *Entry) error {
+	for _, e := range []*Entry{e1, e2} {
+		if _, _, _, err := e.values(); err != nil {
+			return err
+		}
+	}
+	if !sameKey(e1.PublicKey, e2.PublicKey) {
+		return fmt.Errorf("%w: the signatures are from different public keys", ErrInvalidEntry)
+	}
+	return nil
+}
+
+func recoverRelated(e1, e2 *Entry, a, b *models.FiniteField) (*ecdsa.PrivateKey, *big.Int, error) {
+	z1, r1, s1, _ := e1.values()
+	z2, r2, s2, _ := e2.values()
+
+	for _, sign1 := range []bool{false, true} {
+		for _, sign2 := range []bool{false, true} {
+			t1, t2 := s1, s2
+			if sign1 {
+				t1 = new(models.FiniteField).Neg(s1)
+			}
+			if sign2 {
+				t2 = new(models.FiniteField).Neg(s2)
+			}
+
+			// 分子 s1*z2 - a*s2*z1 - b*s1*s2
+			num := new(models.FiniteField).Mul(t1, z2)
+			num.Sub(num, new(models.FiniteField).Mul(new(models.FiniteField).Mul(a, t2), z1))
+			num.Sub(num, new(models.FiniteField).Mul(new(models.FiniteField).Mul(b, t1), t2))
+
+			// 分母 a*s2*r1 - s1*r2
+			den := new(models.FiniteField).Mul(new(models.FiniteField).Mul(a, t2), r1)
+			den.Sub(den, new(models.FiniteField).Mul(t1, r2))
+
+			d, err := new(models.FiniteField).DivChecked(num, den)
+			if err != nil {
+				continue
+			}
+			if priv, ok := matchKey(e1.PublicKey, d); ok {
+				// 符号を反転したかどうかにかかわらず、与えられた署名で s1*k1 == z1 + r1*d となる k1 を返す
+				k := new(models.FiniteField).Mul(r1, d)
+				k.Add(k, z1)
+				k.Div(k, s1)
+				return priv, k.Value, nil
+			}
+		}
+	}
+	return nil, nil, ErrNotRecovered
+}
+
+// matchKey() : dから作った鍵の公開鍵が pub と一致すればそれを返す
+func matchKey(pub *ecdsa.PublicKey, d *models.FiniteField) (*ecdsa.PrivateKey, bool) {
+	priv, err := ecdsa.NewPrivateKey(pub.Curve, d.Value)
+	if err != nil || !sameKey(&priv.PublicKey, pub) {
+		return nil, false
+	}
+	return priv, true
+}
+
+// sameKey() : 2つの公開鍵が同じ曲線の同じ点かどうか
+func sameKey(p1, p2 *ecdsa.PublicKey) bool {
+	params1, params2 := p1.Curve.Params(), p2.Curve.Params()
+	if params1.P.Cmp(params2.P) != 0 || params1.N.Cmp(params2.N) != 0 {
+		return false
+	}
+	if p1.Q.IsZero || p2.Q.IsZero {
+		return false
+	}
+	return p1.Q.X.Equals(p2.Q.X) && p1.Q.Y.Equals(p2.Q.Y)
+}
+
+// Finding : 同じ r を持つ (同じnonce kを使った) 署名のまとまり
+type Finding struct {
+	R       *big.Int
+	Entries []int // entries での添字 (昇順)
+
+	// 同じ公開鍵の署名が2つ以上あれば、kとその公開鍵の秘密鍵が求まる
+	// kが求まれば、同じkを使った別の公開鍵の署名からも秘密鍵が求まる
+	// 求まらなかった場合は Nonce == nil で Keys は空
+	Nonce *big.Int
+	Keys  []*ecdsa.PrivateKey
+}
+
+// FindNonceReuse() : 同じ r を持つ署名をまとめ、求まるkと秘密鍵をすべて求める
+// 不正なエントリは無視する
+func FindNonceReuse(entries []*Entry) []Finding {
+	groups := make(map[string][]int)
+	var order []string
+	for i, e := range entries {
+		if _, _, _, err := e.values(); err != nil {
+			continue
+		}
+		params := e.PublicKey.Curve.Params()
+		key := fmt.Sprintf("%x:%x", params.N, e.Signature.R.Value)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	var findings []Finding
+	for _, key := range order {
+		indices := groups[key]
+		if len(indices) < 2 {
+			continue
+		}
+		f := Finding{R: new(big.Int).Set(entries[indices[0]].Signature.R.Value), Entries: indices}
+		f.Nonce = findNonce(entries, indices)
+		if f.Nonce != nil {
+			f.Keys = keysFromNonce(entries, indices, f.Nonce)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// findNonce() : 同じ公開鍵の署名の組からkを求める
+func findNonce(entries []*Entry, indices []int) *big.Int {
+	for i, x := range indices {
+		for _, y := range indices[i+1:] {
+			if !sameKey(entries[x].PublicKey, entries[y].PublicKey) {
+				continue
+			}
+			if _, k, err := RecoverFromNonceReuse(entries[x], entries[y]); err == nil {
+				return k
+			}
+		}
+	}
+	return nil
+}
+
+// keysFromNonce() : kが分かっている署名から d = (s*k - z) / r で秘密鍵を求める
+// low-S で s の符号が反転している署名もあるので、-k も試す
+func keysFromNonce(entries []*Entry, indices []int, nonce *big.Int) []*ecdsa.PrivateKey {
+	var keys []*ecdsa.PrivateKey
+	seen := make(map[string]bool)
+	for _, i := range indices {
+		e := entries[i]
+		pub := e.PublicKey
+		id := fmt.Sprintf("%x:%x", pub.Q.X.Value, pub.Q.Y.Value)
+		if seen[id] {
+			continue
+		}
+
+		z, r, s, _ := e.values()
+		k := models.NewFiniteField(nonce, pub.Curve.Params().N)
+		for _, kk := range []*models.FiniteField{k, new(models.FiniteField).Neg(k)} {
+			d := new(models.FiniteField).Mul(s, kk)
+			d.Sub(d, z)
+			d.Div(d, r)
+			if priv, ok := matchKey(pub, d); ok {
+				keys = append(keys, priv)
+				seen[id] = true
+				break
+			}
+		}
+	}
+	return keys
+}
+
+// RelatedFinding : k2 = a*k1 + b の関係にあるnonceを使っていた、同じ公開鍵の署名の組
+type RelatedFinding struct {
+	Entries [2]int
+	Nonce   *big.Int // 1つ目の署名のk
+	Key     *ecdsa.PrivateKey
+}
+
+// FindRelatedNonces() : 公開鍵ごとに、entries での順番が隣り合う署名の組について k2 = a*k1 + b を仮定して秘密鍵を求める
+// 線形合同法のような弱い乱数でnonceを作ると、続けて作った署名のnonceがこの関係になる
+// 不正なエントリは無視する
+func FindRelatedNonces(entries []*Entry, a, b *big.Int) []RelatedFinding {
+	var findings []RelatedFinding
+	for _, indices := range groupByKey(entries) {
+		for j := 0; j+1 < len(indices); j++ {
+			x, y := indices[j], indices[j+1]
+			if priv, k, err := RecoverFromRelatedNonces(entries[x], entries[y], a, b); err == nil {
+				findings = append(findings, RelatedFinding{Entries: [2]int{x, y}, Nonce: k, Key: priv})
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Entries[0] < findings[j].Entries[0]
+	})
+	return findings
+}
+
+// groupByKey() : 正しいエントリの添字を公開鍵ごとにまとめる (最初に現れた順)
+func groupByKey(entries []*Entry) [][]int {
+	byKey := make(map[string]int)
+	var groups [][]int
+	for i, e := range entries {
+		if _, _, _, err := e.values(); err != nil {
+			continue
+		}
+		pub := e.PublicKey
+		id := fmt.Sprintf("%x:%x:%x", pub.Curve.Params().N, pub.Q.X.Value, pub.Q.Y.Value)
+		g, ok := byKey[id]
+		if !ok {
+			g = len(groups)
+			byKey[id] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
@@ -0,0 +1,252 @@
+package audit
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func sha256Hash(msg string) []byte {
+	h := sha256.Sum256([]byte(msg))
+	return h[:]
+}
+
+func mustKey(ec *models.EllipticCurve, d int64) *ecdsa.PrivateKey {
+	priv, err := ecdsa.NewPrivateKey(ec, big.NewInt(d))
+	if err != nil {
+		panic(err)
+	}
+	return priv
+}
+
+// signWithNonce() : nonce k を使って msg に署名する
+func signWithNonce(t testing.TB, priv *ecdsa.PrivateKey, msg string, k *big.Int) *Entry {
+	t.Helper()
+	hash := sha256Hash(msg)
+	sig, err := ecdsa.Sign(priv, hash, ecdsa.WithNonce(new(big.Int).Mod(k, priv.Curve.Params().N)))
+	if err != nil {
+		t.Fatalf("Sign() err = %v", err)
+	}
+	return &Entry{PublicKey: &priv.PublicKey, Hash: hash, Signature: sig}
+}
+
+func signRandom(t testing.TB, priv *ecdsa.PrivateKey, msg string) *Entry {
+	t.Helper()
+	hash := sha256Hash(msg)
+	sig, err := ecdsa.Sign(priv, hash, ecdsa.WithRandomNonce(rand.Reader))
+	if err != nil {
+		t.Fatalf("Sign() err = %v", err)
+	}
+	return &Entry{PublicKey: &priv.PublicKey, Hash: hash, Signature: sig}
+}
+
+// checkNonce() : k*G のx座標が署名のrと一致し、s*k == z + r*d となるか
+func checkNonce(e *Entry, priv *ecdsa.PrivateKey, k *big.Int) error {
+	ec := e.PublicKey.Curve
+	n := ec.Params().N
+	R := ec.ScalarBaseMultP(k.Bytes())
+	if R.IsZero || new(big.Int).Mod(R.X.Value, n).Cmp(e.Signature.R.Value) != 0 {
+		return fmt.Errorf("k*G = %v does not match r = %v", R, e.Signature.R)
+	}
+	z, r, s, _ := e.values()
+	lhs := new(models.FiniteField).Mul(s, models.NewFiniteField(k, n))
+	rhs := new(models.FiniteField).Mul(r, priv.D)
+	rhs.Add(rhs, z)
+	if !lhs.Equals(rhs) {
+		return fmt.Errorf("s*k != z + r*d")
+	}
+	return nil
+}
+
+func Test_RecoverFromNonceReuse(t *testing.T) {
+	secp256k1, p256 := curves.Secp256k1(), curves.P256()
+	priv := mustKey(secp256k1, 0xc0ffee)
+	other := mustKey(secp256k1, 0xbeef)
+	privP256 := mustKey(p256, 0xc0ffee)
+	k := big.NewInt(0x1234567)
+	// N - k を使っても r は同じになる
+	negK := new(big.Int).Sub(secp256k1.Params().N, k)
+
+	tests := []struct {
+		name    string
+		e1, e2  *Entry
+		want    *ecdsa.PrivateKey
+		wantErr error
+	}{
+		{name: "same nonce", e1: signWithNonce(t, priv, "a", k), e2: signWithNonce(t, priv, "b", k), want: priv},
+		{name: "k and N - k", e1: signWithNonce(t, priv, "a", k), e2: signWithNonce(t, priv, "c", negK), want: priv},
+		{name: "P-256", e1: signWithNonce(t, privP256, "a", k), e2: signWithNonce(t, privP256, "b", k), want: privP256},
+		{name: "different nonces", e1: signWithNonce(t, priv, "a", k), e2: signRandom(t, priv, "b"), wantErr: ErrNotRecovered},
+		{name: "same message", e1: signWithNonce(t, priv, "a", k), e2: signWithNonce(t, priv, "a", k), wantErr: ErrNotRecovered},
+		{name: "different keys", e1: signWithNonce(t, priv, "a", k), e2: signWithNonce(t, other, "b", k), wantErr: ErrInvalidEntry},
+		{name: "nil signature", e1: signWithNonce(t, priv, "a", k), e2: &Entry{PublicKey: &priv.PublicKey}, wantErr: ErrInvalidEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotK, err := RecoverFromNonceReuse(tt.e1, tt.e2)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : RecoverFromNonceReuse() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !got.D.Equals(tt.want.D) {
+				t.Errorf("%v : RecoverFromNonceReuse() d = %v, want %v", tt.name, got.D, tt.want.D)
+			}
+			if err := checkNonce(tt.e1, got, gotK); err != nil {
+				t.Errorf("%v : RecoverFromNonceReuse() k = %x : %v", tt.name, gotK, err)
+			}
+		})
+	}
+}
+
+func Test_RecoverFromRelatedNonces(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	priv := mustKey(ec, 0xdeadbeef)
+	k1 := new(big.Int).SetBytes(sha256Hash("k1"))
+
+	related := func(a, b int64) *big.Int {
+		k2 := new(big.Int).Mul(big.NewInt(a), k1)
+		k2.Add(k2, big.NewInt(b))
+		return k2.Mod(k2, n)
+	}
+
+	tests := []struct {
+		name    string
+		e2      *Entry
+		a, b    int64
+		wantErr error
+	}{
+		{name: "k2 = k1", e2: signWithNonce(t, priv, "m2", k1), a: 1, b: 0},
+		{name: "k2 = k1 + 1", e2: signWithNonce(t, priv, "m2", related(1, 1)), a: 1, b: 1},
+		{name: "k2 = 3*k1 + 12345", e2: signWithNonce(t, priv, "m2", related(3, 12345)), a: 3, b: 12345},
+		{name: "k2 = -k1 - 7", e2: signWithNonce(t, priv, "m2", related(-1, -7)), a: -1, b: -7},
+		{name: "wrong relation", e2: signWithNonce(t, priv, "m2", related(3, 12345)), a: 3, b: 12344, wantErr: ErrNotRecovered},
+	}
+
+	e1 := signWithNonce(t, priv, "m1", k1)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotK, err := RecoverFromRelatedNonces(e1, tt.e2, big.NewInt(tt.a), big.NewInt(tt.b))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : RecoverFromRelatedNonces() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !got.D.Equals(priv.D) {
+				t.Errorf("%v : RecoverFromRelatedNonces() d = %v, want %v", tt.name, got.D, priv.D)
+			}
+			if err := checkNonce(e1, got, gotK); err != nil {
+				t.Errorf("%v : RecoverFromRelatedNonces() k1 = %x : %v", tt.name, gotK, err)
+			}
+		})
+	}
+}
+
+func Test_FindNonceReuse(t *testing.T) {
+	ec := curves.Secp256k1()
+	keys := make([]*ecdsa.PrivateKey, 6)
+	for i := range keys {
+		keys[i] = mustKey(ec, int64(1000+i))
+	}
+	k1, k2, k3 := big.NewInt(111), big.NewInt(222), big.NewInt(333)
+
+	entries := []*Entry{
+		signWithNonce(t, keys[0], "m0", k1),
+		signRandom(t, keys[0], "m1"),
+		signWithNonce(t, keys[1], "m2", k1),
+		signWithNonce(t, keys[2], "m3", k2),
+		nil,
+		signWithNonce(t, keys[0], "m5", k1),
+		signWithNonce(t, keys[2], "m6", k2),
+		// 別の公開鍵どうしで同じkを使っていても、それだけではkは求まらない
+		signWithNonce(t, keys[3], "m7", k3),
+		signWithNonce(t, keys[4], "m8", k3),
+		signRandom(t, keys[5], "m9"),
+	}
+
+	want := []struct {
+		entries []int
+		keys    []*ecdsa.PrivateKey
+	}{
+		{entries: []int{0, 2, 5}, keys: []*ecdsa.PrivateKey{keys[0], keys[1]}},
+		{entries: []int{3, 6}, keys: []*ecdsa.PrivateKey{keys[2]}},
+		{entries: []int{7, 8}},
+	}
+
+	got := FindNonceReuse(entries)
+	if len(got) != len(want) {
+		t.Fatalf("FindNonceReuse() returned %v findings, want %v", len(got), len(want))
+	}
+	for i, f := range got {
+		if fmt.Sprint(f.Entries) != fmt.Sprint(want[i].entries) {
+			t.Errorf("FindNonceReuse()[%d].Entries = %v, want %v", i, f.Entries, want[i].entries)
+		}
+		if f.R.Cmp(entries[f.Entries[0]].Signature.R.Value) != 0 {
+			t.Errorf("FindNonceReuse()[%d].R = %x", i, f.R)
+		}
+		if (f.Nonce == nil) != (len(want[i].keys) == 0) {
+			t.Errorf("FindNonceReuse()[%d].Nonce = %v", i, f.Nonce)
+		}
+		if len(f.Keys) != len(want[i].keys) {
+			t.Fatalf("FindNonceReuse()[%d] recovered %v keys, want %v", i, len(f.Keys), len(want[i].keys))
+		}
+		for j, priv := range f.Keys {
+			if !priv.D.Equals(want[i].keys[j].D) {
+				t.Errorf("FindNonceReuse()[%d].Keys[%d] = %v, want %v", i, j, priv.D, want[i].keys[j].D)
+			}
+		}
+	}
+}
+
+func Test_FindRelatedNonces(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	priv, other := mustKey(ec, 0xabcdef), mustKey(ec, 0x123456)
+
+	// 線形合同法 k_(i+1) = a*k_i + b で作ったnonce
+	a, b := big.NewInt(6364136223846793005), big.NewInt(1442695040888963407)
+	k := big.NewInt(42)
+	next := func() *big.Int {
+		k = new(big.Int).Mul(a, k)
+		k.Add(k, b)
+		return k.Mod(k, n)
+	}
+
+	entries := []*Entry{
+		signWithNonce(t, priv, "m0", next()),
+		signRandom(t, other, "m1"),
+		signWithNonce(t, priv, "m2", next()),
+		signRandom(t, other, "m3"),
+		signWithNonce(t, priv, "m4", next()),
+		// ここで別の乱数に切り替わる
+		signRandom(t, priv, "m5"),
+	}
+
+	got := FindRelatedNonces(entries, a, b)
+	want := [][2]int{{0, 2}, {2, 4}}
+	if len(got) != len(want) {
+		t.Fatalf("FindRelatedNonces() returned %v findings, want %v", len(got), len(want))
+	}
+	for i, f := range got {
+		if f.Entries != want[i] {
+			t.Errorf("FindRelatedNonces()[%d].Entries = %v, want %v", i, f.Entries, want[i])
+		}
+		if !f.Key.D.Equals(priv.D) {
+			t.Errorf("FindRelatedNonces()[%d].Key = %v, want %v", i, f.Key.D, priv.D)
+		}
+		if err := checkNonce(entries[f.Entries[0]], f.Key, f.Nonce); err != nil {
+			t.Errorf("FindRelatedNonces()[%d].Nonce = %x : %v", i, f.Nonce, err)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package batch
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+	"github.com/matumoto1234/secp256k1/schnorr"
+)
+
+// BatchVerifier : 多数の (公開鍵, メッセージ, 署名) をまとめて検証する
+// Add*() と Verify() は複数のgoroutineから同時に呼んでもよい
+//
+// Schnorr署名はランダムな係数 a_i による線形結合で1つの式にまとめて検証する (BIP340 batch verification)
+//
+//	(Σ a_i*s_i)*G == Σ a_i*R_i + Σ (a_i*e_i)*P_i
+//
+// ECDSA署名はまとめて検証する方法がないので、goroutineのワーカーで並列に検証する
+type BatchVerifier struct {
+	mu      sync.Mutex
+	ecdsa   []ecdsaEntry
+	schnorr []schnorrEntry
+	size    int
+
+	workers int
+	rand    io.Reader
+}
+
+type ecdsaEntry struct {
+	index int
+	pub   *ecdsa.PublicKey
+	hash  []byte
+	sig   *ecdsa.Signature
+	opts  []ecdsa.VerifyOption
+}
+
+type schnorrEntry struct {
+	index int
+	pub   *schnorr.PublicKey
+	msg   []byte
+	sig   *schnorr.Signature
+}
+
+// Option : NewBatchVerifier() の挙動を変更するオプション
+type Option func(*BatchVerifier)
+
+// WithWorkers() : 検証に使うgoroutineの数を指定する (既定値は GOMAXPROCS)
+func WithWorkers(n int) Option {
+	return func(v *BatchVerifier) {
+		if n > 0 {
+			v.workers = n
+		}
+	}
+}
+
+// WithRand() : Schnorr署名の線形結合の係数に使う乱数を指定する (既定値は crypto/rand.Reader)
+// 係数が予測できると、不正な署名を組み合わせて検証を通すことができるので、テスト以外では指定しないこと
+func WithRand(r io.Reader) Option {
+	return func(v *BatchVerifier) {
+		v.rand = r
+	}
+}
+
+func NewBatchVerifier(opts ...Option) *BatchVerifier {
+	v := &BatchVerifier{
+		workers: runtime.GOMAXPROCS(0),
+		rand:    rand.Reader,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// AddECDSA() : ECDSA署名を追加し、そのエントリの番号を返す
+// 番号は AddECDSA() と AddSchnorr() を通して、追加した順に0から振られる
+func (v *BatchVerifier) AddECDSA(pub *ecdsa.PublicKey, hash []byte, sig *ecdsa.Signature, opts ...ecdsa.VerifyOption) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	index := v.size
+	v.size++
+	v.ecdsa = append(v.ecdsa, ecdsaEntry{index: index, pub: pub, hash: hash, sig: sig, opts: opts})
+	return index
+}
+
+// AddSchnorr() : BIP340 のSchnorr署名を追加し、そのエントリの番号を返す
+func (v *BatchVerifier) AddSchnorr(pub *schnorr.PublicKey, msg []byte, sig *schnorr.Signature) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	index := v.size
+	v.size++
+	v.schnorr = append(v.schnorr, schnorrEntry{index: index, pub: pub, msg: msg, sig: sig})
+	return index
+}
+
+// Len() : 追加されたエントリの数
+func (v *BatchVerifier) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.size
+}
+
+// Reset() : 追加されたエントリをすべて削除する
+func (v *BatchVerifier) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.ecdsa = nil
+	v.schnorr = nil
+	v.size = 0
+}
+
+// Verify() : 追加されたすべての署名を検証する
+// すべて正しければ ok == true、そうでなければ不正だったエントリの番号を昇順で返す
+// Schnorr署名のまとめた検証に失敗した場合は、1つずつ検証し直して不正なものを特定する
+func (v *BatchVerifier) Verify() (ok bool, failed []int) {
+	v.mu.Lock()
+	ecdsaEntries := append([]ecdsaEntry(nil), v.ecdsa...)
+	schnorrEntries := append([]schnorrEntry(nil), v.schnorr...)
+	size := v.size
+	v.mu.Unlock()
+
+	// エントリごとに別の要素に書き込むので、ロックは要らない
+	bad := make([]bool, size)
+
+	v.parallel(len(ecdsaEntries), func(i int) {
+		e := ecdsaEntries[i]
+		if !ecdsa.Verify(e.pub, e.hash, e.sig, e.opts...) {
+			bad[e.index] = true
+		}
+	})
+
+	v.verifySchnorr(schnorrEntries, bad)
+
+	for i, b := range bad {
+		if b {
+			failed = append(failed, i)
+		}
+	}
+	return len(failed) == 0, failed
+}
+
+// parallel() : f(0), f(1), ..., f(n-1) をワーカーで並列に実行する
+func (v *BatchVerifier) parallel(n int, f func(i int)) {
+	workers := v.workers
+	if workers > n {
+		workers = n
+	}
+
+	var next int64 = -1
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// schnorrTerm : 線形結合に使う、1つのSchnorr署名から求めた値
+type schnorrTerm struct {
+	entry schnorrEntry
+	R     *models.EllipticCurvePoint // lift_x(r)
+	P     *models.EllipticCurvePoint // lift_x(公開鍵)
+	e     *big.Int                   // hash_BIP0340/challenge(r || P.x || m) mod N
+	s     *big.Int
+}
+
+// newSchnorrTerm() : R, P が曲線上の点として存在しない場合などは ok == false を返す
+func newSchnorrTerm(e schnorrEntry) (term *schnorrTerm, ok bool) {
+	pub, sig := e.pub, e.sig
+	if pub == nil || pub.Curve == nil || pub.X == nil || sig == nil {
+		return nil, false
+	}
+
+	// schnorr.Verify() と同じく、secp256k1 で 0 <= r < p, 0 <= s < N の署名だけを受け付ける
+	ec := pub.Curve
+	if !ec.IsSecp256k1() {
+		return nil, false
+	}
+	params := ec.Params()
+	if !schnorr.InRange(sig.R, params.P) || !schnorr.InRange(sig.S, params.N) {
+		return nil, false
+	}
+
+	P, ok := ec.LiftX(pub.X.Value, false)
+	if !ok {
+		return nil, false
+	}
+	R, ok := ec.LiftX(sig.R.Value, false)
+	if !ok {
+		return nil, false
+	}
+
+	size := (params.P.BitLen() + 7) / 8
+	h := schnorr.TaggedHash(
+		"BIP0340/challenge",
+		sig.R.Value.FillBytes(make([]byte, size)),
+		P.X.Value.FillBytes(make([]byte, size)),
+		e.msg,
+	)
+	challenge := new(big.Int).SetBytes(h[:])
+	challenge.Mod(challenge, params.N)
+
+	return &schnorrTerm{entry: e, R: R, P: P, e: challenge, s: sig.S.Value}, true
+}
+
+// verifySchnorr() : Schnorr署名を曲線ごとにまとめて検証し、不正なものを bad に記録する
+func (v *BatchVerifier) verifySchnorr(entries []schnorrEntry, bad []bool) {
+	terms := make([]*schnorrTerm, len(entries))
+	v.parallel(len(entries), func(i int) {
+		term, ok := newSchnorrTerm(entries[i])
+		if !ok {
+			bad[entries[i].index] = true
+			return
+		}
+		terms[i] = term
+	})
+
+	groups := make(map[*models.EllipticCurve][]*schnorrTerm)
+	var curves []*models.EllipticCurve
+	for _, term := range terms {
+		if term == nil {
+			continue
+		}
+		ec := term.entry.pub.Curve
+		if _, ok := groups[ec]; !ok {
+			curves = append(curves, ec)
+		}
+		groups[ec] = append(groups[ec], term)
+	}
+
+	for _, ec := range curves {
+		group := groups[ec]
+		if v.verifySchnorrBatch(ec, group) {
+			continue
+		}
+
+		// どれが不正なのかを1つずつ検証して特定する
+		v.parallel(len(group), func(i int) {
+			e := group[i].entry
+			if !schnorr.Verify(e.pub, e.msg, e.sig) {
+				bad[e.index] = true
+			}
+		})
+	}
+}
+
+// coefficients() : a_0 = 1 と、[1, N) の一様乱数 a_1, ..., a_(count-1)
+func (v *BatchVerifier) coefficients(n *big.Int, count int) ([]*big.Int, error) {
+	// Verify() が同時に呼ばれても、乱数を同時に読まないようにする
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1))
+	coeffs := make([]*big.Int, count)
+	for i := range coeffs {
+		if i == 0 {
+			coeffs[i] = big.NewInt(1)
+			continue
+		}
+		a, err := rand.Int(v.rand, nMinus1)
+		if err != nil {
+			return nil, err
+		}
+		coeffs[i] = a.Add(a, big.NewInt(1))
+	}
+	return coeffs, nil
+}
+
+// verifySchnorrBatch() : (Σ a_i*s_i)*G == Σ (a_i*R_i + (a_i*e_i)*P_i) を確認する
+// 右辺は項をワーカーの数に分けて MultiScalarMult() で部分和を求めてから足し合わせる
+func (v *BatchVerifier) verifySchnorrBatch(ec *models.EllipticCurve, terms []*schnorrTerm) bool {
+	n := ec.Params().N
+	coeffs, err := v.coefficients(n, len(terms))
+	if err != nil {
+		return false
+	}
+
+	// 左辺のスカラー
+	sum := new(big.Int)
+	for i, term := range terms {
+		sum.Add(sum, new(big.Int).Mul(coeffs[i], term.s))
+	}
+	sum.Mod(sum, n)
+
+	// 右辺
+	workers := v.workers
+	if workers > len(terms) {
+		workers = len(terms)
+	}
+	chunk := (len(terms) + workers - 1) / workers
+	partials := make([]*models.EllipticCurvePoint, workers)
+	v.parallel(workers, func(w int) {
+		start, end := w*chunk, (w+1)*chunk
+		if end > len(terms) {
+			end = len(terms)
+		}
+
+		var points []*models.EllipticCurvePoint
+		var scalars [][]byte
+		for i := start; i < end; i++ {
+			ae := new(big.Int).Mul(coeffs[i], terms[i].e)
+			ae.Mod(ae, n)
+			points = append(points, terms[i].R, terms[i].P)
+			scalars = append(scalars, coeffs[i].Bytes(), ae.Bytes())
+		}
+		partials[w] = ec.MultiScalarMult(points, scalars)
+	})
+
+	rhs := models.NewEllipticCurvePoint(nil, nil, true)
+	for _, p := range partials {
+		rhs = ec.AddP(rhs, p)
+	}
+	lhs := ec.ScalarBaseMultP(sum.Bytes())
+
+	if lhs.IsZero || rhs.IsZero {
+		return lhs.IsZero == rhs.IsZero
+	}
+	return lhs.X.Equals(rhs.X) && lhs.Y.Equals(rhs.Y)
+}
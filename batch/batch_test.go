@@ -0,0 +1,272 @@
+package batch
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+	"github.com/matumoto1234/secp256k1/schnorr"
+)
+
+type ecdsaTuple struct {
+	pub  *ecdsa.PublicKey
+	hash []byte
+	sig  *ecdsa.Signature
+}
+
+type schnorrTuple struct {
+	pub *schnorr.PublicKey
+	msg []byte
+	sig *schnorr.Signature
+}
+
+func newECDSATuple(t testing.TB, ec *models.EllipticCurve, i int) ecdsaTuple {
+	priv, err := ecdsa.GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(fmt.Sprintf("ecdsa message %d", i)))
+	sig, err := ecdsa.Sign(priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ecdsaTuple{pub: &priv.PublicKey, hash: hash[:], sig: sig}
+}
+
+func newSchnorrTuple(t testing.TB, ec *models.EllipticCurve, i int) schnorrTuple {
+	priv, err := ecdsa.GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(fmt.Sprintf("schnorr message %d", i))
+	sig, err := schnorr.Sign(priv, msg, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schnorrTuple{pub: schnorr.NewPublicKey(priv), msg: msg, sig: sig}
+}
+
+func Test_BatchVerifier_Verify(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	p := ec.Params().P
+
+	ecdsaTuples := make([]ecdsaTuple, 4)
+	for i := range ecdsaTuples {
+		ecdsaTuples[i] = newECDSATuple(t, ec, i)
+	}
+	schnorrTuples := make([]schnorrTuple, 6)
+	for i := range schnorrTuples {
+		schnorrTuples[i] = newSchnorrTuple(t, ec, i)
+	}
+
+	// s を d だけずらした署名を2つ作り、片方は +d、もう片方は -d にする
+	// 係数がすべて1なら打ち消し合って検証を通ってしまう組み合わせ
+	d := big.NewInt(12345)
+	shifted := func(tu schnorrTuple, delta *big.Int) schnorrTuple {
+		s := new(big.Int).Add(tu.sig.S.Value, delta)
+		tu.sig = &schnorr.Signature{R: tu.sig.R, S: models.NewFiniteField(s, n)}
+		return tu
+	}
+
+	// 曲線上にx座標を持たない値 (x = 5)
+	noPoint := models.NewFiniteField(big.NewInt(5), p)
+
+	tests := []struct {
+		name       string
+		ecdsa      func(i int, tu ecdsaTuple) ecdsaTuple
+		schnorr    func(i int, tu schnorrTuple) schnorrTuple
+		wantFailed []int
+	}{
+		{name: "all valid"},
+		{
+			// 番号は ECDSA の4つ (0..3) の後に Schnorr の6つ (4..9)
+			name: "bad schnorr message",
+			schnorr: func(i int, tu schnorrTuple) schnorrTuple {
+				if i == 2 {
+					tu.msg = []byte("tampered")
+				}
+				return tu
+			},
+			wantFailed: []int{6},
+		},
+		{
+			name: "bad ecdsa and schnorr",
+			ecdsa: func(i int, tu ecdsaTuple) ecdsaTuple {
+				if i == 1 {
+					tu.sig = ecdsaTuples[0].sig
+				}
+				return tu
+			},
+			schnorr: func(i int, tu schnorrTuple) schnorrTuple {
+				if i == 5 {
+					tu.pub = schnorrTuples[0].pub
+				}
+				return tu
+			},
+			wantFailed: []int{1, 9},
+		},
+		{
+			name: "schnorr errors cancel out",
+			schnorr: func(i int, tu schnorrTuple) schnorrTuple {
+				switch i {
+				case 0:
+					return shifted(tu, d)
+				case 3:
+					return shifted(tu, new(big.Int).Neg(d))
+				}
+				return tu
+			},
+			wantFailed: []int{4, 7},
+		},
+		{
+			name: "schnorr points not on curve",
+			schnorr: func(i int, tu schnorrTuple) schnorrTuple {
+				switch i {
+				case 1:
+					tu.pub = &schnorr.PublicKey{Curve: ec, X: noPoint}
+				case 4:
+					tu.sig = &schnorr.Signature{R: noPoint, S: tu.sig.S}
+				}
+				return tu
+			},
+			wantFailed: []int{5, 8},
+		},
+		{
+			// ParseSignature() を通さずに作った範囲外の r, s と、secp256k1 以外の曲線の公開鍵
+			name: "schnorr out of range",
+			schnorr: func(i int, tu schnorrTuple) schnorrTuple {
+				switch i {
+				case 0:
+					tu.sig = &schnorr.Signature{R: tu.sig.R, S: &models.FiniteField{Value: new(big.Int).Add(tu.sig.S.Value, n), Prime: n}}
+				case 2:
+					tu.sig = &schnorr.Signature{R: &models.FiniteField{Value: new(big.Int).Add(tu.sig.R.Value, p), Prime: p}, S: tu.sig.S}
+				case 5:
+					tu.pub = &schnorr.PublicKey{Curve: curves.P256(), X: tu.pub.X}
+				}
+				return tu
+			},
+			wantFailed: []int{4, 6, 9},
+		},
+		{
+			name: "nil entries",
+			ecdsa: func(i int, tu ecdsaTuple) ecdsaTuple {
+				if i == 3 {
+					tu.pub = nil
+				}
+				return tu
+			},
+			schnorr: func(i int, tu schnorrTuple) schnorrTuple {
+				if i == 0 {
+					tu.sig = nil
+				}
+				return tu
+			},
+			wantFailed: []int{3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewBatchVerifier(WithWorkers(3))
+			for i, tu := range ecdsaTuples {
+				if tt.ecdsa != nil {
+					tu = tt.ecdsa(i, tu)
+				}
+				v.AddECDSA(tu.pub, tu.hash, tu.sig)
+			}
+			for i, tu := range schnorrTuples {
+				if tt.schnorr != nil {
+					tu = tt.schnorr(i, tu)
+				}
+				v.AddSchnorr(tu.pub, tu.msg, tu.sig)
+			}
+
+			ok, failed := v.Verify()
+			if ok != (len(tt.wantFailed) == 0) || !reflect.DeepEqual(failed, tt.wantFailed) {
+				t.Errorf("%v : BatchVerifier.Verify() = (%v, %v), want (%v, %v)", tt.name, ok, failed, len(tt.wantFailed) == 0, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func Test_BatchVerifier_Empty(t *testing.T) {
+	v := NewBatchVerifier()
+	if ok, failed := v.Verify(); !ok || failed != nil {
+		t.Errorf("BatchVerifier.Verify() = (%v, %v), want (true, nil)", ok, failed)
+	}
+}
+
+func Test_BatchVerifier_Concurrent(t *testing.T) {
+	ec := curves.Secp256k1()
+	v := NewBatchVerifier()
+
+	const goroutines = 4
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 3; i++ {
+				e := newECDSATuple(t, ec, g*10+i)
+				v.AddECDSA(e.pub, e.hash, e.sig)
+				s := newSchnorrTuple(t, ec, g*10+i)
+				v.AddSchnorr(s.pub, s.msg, s.sig)
+
+				// 追加している途中で検証してもよい
+				if ok, failed := v.Verify(); !ok {
+					t.Errorf("BatchVerifier.Verify() failed = %v", failed)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if got := v.Len(); got != goroutines*6 {
+		t.Errorf("BatchVerifier.Len() = %v, want %v", got, goroutines*6)
+	}
+	if ok, failed := v.Verify(); !ok {
+		t.Errorf("BatchVerifier.Verify() failed = %v", failed)
+	}
+
+	v.Reset()
+	if got := v.Len(); got != 0 {
+		t.Errorf("BatchVerifier.Len() after Reset() = %v, want 0", got)
+	}
+}
+
+func BenchmarkBatchVerifier_Schnorr(b *testing.B) {
+	ec := curves.Secp256k1()
+	tuples := make([]schnorrTuple, 64)
+	for i := range tuples {
+		tuples[i] = newSchnorrTuple(b, ec, i)
+	}
+
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			v := NewBatchVerifier()
+			for _, tu := range tuples {
+				v.AddSchnorr(tu.pub, tu.msg, tu.sig)
+			}
+			if ok, _ := v.Verify(); !ok {
+				b.Fatal("batch verification failed")
+			}
+		}
+	})
+	b.Run("one by one", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, tu := range tuples {
+				if !schnorr.Verify(tu.pub, tu.msg, tu.sig) {
+					b.Fatal("verification failed")
+				}
+			}
+		}
+	})
+}
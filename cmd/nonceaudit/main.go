@@ -0,0 +1,101 @@
+// nonceaudit : ECDSA署名のダンプから、nonceの使い回しや k2 = a*k1 + b の関係、nonceの上位ビットの偏りで漏洩した秘密鍵を探す
+//
+//	nonceaudit [-curve secp256k1] [-a A -b B] [-bits BITS [-bkz BLOCKSIZE]] [file ...]
+//
+// ファイルは1行に1つ「公開鍵 ハッシュ r s」(16進数) で、指定しなければ標準入力から読む
+// 問題が見つかった場合は終了コード1で終わる
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/matumoto1234/secp256k1/audit"
+	"github.com/matumoto1234/secp256k1/curves"
+)
+
+func main() {
+	curveName := flag.String("curve", "secp256k1", "curve name")
+	aFlag := flag.String("a", "", "check k2 = a*k1 + b for consecutive signatures of the same key (decimal or 0x-prefixed hex)")
+	bFlag := flag.String("b", "0", "see -a")
+	bitsFlag := flag.Int("bits", 0, "assume the top bits of every nonce are zero and try to recover each key with a lattice attack")
+	bkzFlag := flag.Int("bkz", 10, "BKZ block size used by -bits when LLL is not enough (0 to disable)")
+	flag.Parse()
+	log.SetFlags(0)
+
+	ec, err := curves.ByName(*curveName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var entries []*audit.Entry
+	var sources []string // entries の添字に対応する「ファイル名:番号」
+	read := func(name string, r io.Reader) {
+		es, err := audit.ParseEntries(ec, r)
+		if err != nil {
+			log.Fatalf("%v: %v", name, err)
+		}
+		for i := range es {
+			sources = append(sources, fmt.Sprintf("%v:#%d", name, i+1))
+		}
+		entries = append(entries, es...)
+	}
+	if flag.NArg() == 0 {
+		read("stdin", os.Stdin)
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		read(name, f)
+		f.Close()
+	}
+
+	found := false
+	for _, f := range audit.FindNonceReuse(entries) {
+		found = true
+		fmt.Printf("nonce reuse: r = %x\n", f.R)
+		for _, i := range f.Entries {
+			fmt.Printf("  %v\n", sources[i])
+		}
+		if f.Nonce == nil {
+			fmt.Println("  k not recovered (no two signatures from the same key)")
+			continue
+		}
+		fmt.Printf("  k = %x\n", f.Nonce)
+		for _, priv := range f.Keys {
+			fmt.Printf("  private key %x for public key %x\n", priv.Bytes(), priv.Q.MarshalCompressed())
+		}
+	}
+
+	if *aFlag != "" {
+		a, ok1 := new(big.Int).SetString(*aFlag, 0)
+		b, ok2 := new(big.Int).SetString(*bFlag, 0)
+		if !ok1 || !ok2 {
+			log.Fatalf("invalid -a or -b: %q, %q", *aFlag, *bFlag)
+		}
+		for _, f := range audit.FindRelatedNonces(entries, a, b) {
+			found = true
+			fmt.Printf("related nonces k2 = %v*k1 + %v: %v, %v\n", a, b, sources[f.Entries[0]], sources[f.Entries[1]])
+			fmt.Printf("  k1 = %x\n", f.Nonce)
+			fmt.Printf("  private key %x for public key %x\n", f.Key.Bytes(), f.Key.Q.MarshalCompressed())
+		}
+	}
+
+	if *bitsFlag > 0 {
+		for _, f := range audit.FindBiasedNonces(entries, *bitsFlag, audit.WithBKZ(*bkzFlag)) {
+			found = true
+			fmt.Printf("biased nonces (top %d bits zero): %d signatures from %v\n", *bitsFlag, len(f.Entries), sources[f.Entries[0]])
+			fmt.Printf("  private key %x for public key %x\n", f.Key.Bytes(), f.Key.Q.MarshalCompressed())
+		}
+	}
+
+	if found {
+		os.Exit(1)
+	}
+}
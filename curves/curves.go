@@ -0,0 +1,252 @@
+package curves
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+var ErrUnknownCurve = errors.New("curves: unknown curve")
+
+// curve : 名前付き曲線の定義
+// 曲線は最初に使われたときに1回だけ作り、以降は同じものを返す
+type curve struct {
+	name    string
+	aliases []string
+	oid     asn1.ObjectIdentifier // なければ nil
+	bitSize int
+
+	// 16進数
+	p, a, b, gx, gy, n string
+
+	// GLV法の定数 (なければ nil)
+	endomorphism *endomorphism
+
+	once sync.Once
+	ec   *models.EllipticCurve
+}
+
+// endomorphism : models.Endomorphism の定数 (16進数、負の値は先頭に '-')
+type endomorphism struct {
+	beta, lambda string
+	a1, b1       string
+	a2, b2       string
+}
+
+var (
+	secp256k1 = &curve{
+		name:    "secp256k1",
+		oid:     asn1.ObjectIdentifier{1, 3, 132, 0, 10},
+		bitSize: 256,
+		p:       "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F",
+		a:       "0",
+		b:       "7",
+		gx:      "79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		gy:      "483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8",
+		n:       "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141",
+		endomorphism: &endomorphism{
+			beta:   "7AE96A2B657C07106E64479EAC3434E99CF0497512F58995C1396C28719501EE",
+			lambda: "5363AD4CC05C30E0A5261C028812645A122E22EA20816678DF02967C1B23BD72",
+			a1:     "3086D221A7D46BCDE86C90E49284EB15",
+			b1:     "-E4437ED6010E88286F547FA90ABFE4C3",
+			a2:     "114CA50F7A8E2F3F657C1108D9D44CFD8",
+			b2:     "3086D221A7D46BCDE86C90E49284EB15",
+		},
+	}
+
+	p256 = &curve{
+		name:    "P-256",
+		aliases: []string{"secp256r1", "prime256v1"},
+		oid:     asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7},
+		bitSize: 256,
+		p:       "FFFFFFFF00000001000000000000000000000000FFFFFFFFFFFFFFFFFFFFFFFF",
+		a:       "FFFFFFFF00000001000000000000000000000000FFFFFFFFFFFFFFFFFFFFFFFC",
+		b:       "5AC635D8AA3A93E7B3EBBD55769886BC651D06B0CC53B0F63BCE3C3E27D2604B",
+		gx:      "6B17D1F2E12C4247F8BCE6E563A440F277037D812DEB33A0F4A13945D898C296",
+		gy:      "4FE342E2FE1A7F9B8EE7EB4A7C0F9E162BCE33576B315ECECBB6406837BF51F5",
+		n:       "FFFFFFFF00000000FFFFFFFFFFFFFFFFBCE6FAADA7179E84F3B9CAC2FC632551",
+	}
+
+	secp192k1 = &curve{
+		name:    "secp192k1",
+		oid:     asn1.ObjectIdentifier{1, 3, 132, 0, 31},
+		bitSize: 192,
+		p:       "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFEE37",
+		a:       "0",
+		b:       "3",
+		gx:      "DB4FF10EC057E9AE26B07D0280B7F4341DA5D1B1EAE06C7D",
+		gy:      "9B2F2F6D9C5628A7844163D015BE86344082AA88D95E2F9D",
+		n:       "FFFFFFFFFFFFFFFFFFFFFFFE26F2FC170F69466A74DEFD8D",
+		endomorphism: &endomorphism{
+			beta:   "BB85691939B869C1D087F601554B96B80CB4F55B35F433C2",
+			lambda: "3D84F26C12238D7B4F3D516613C1759033B1A5800175D0B1",
+			a1:     "71169BE7330B3038EDB025F1",
+			b1:     "-B3FB3400DEC5C4ADCEB8655C",
+			a2:     "12511CFE811D0F4E6BC688B4D",
+			b2:     "71169BE7330B3038EDB025F1",
+		},
+	}
+
+	secp224k1 = &curve{
+		name:    "secp224k1",
+		oid:     asn1.ObjectIdentifier{1, 3, 132, 0, 32},
+		bitSize: 224,
+		p:       "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFE56D",
+		a:       "0",
+		b:       "5",
+		gx:      "A1455B334DF099DF30FC28A169A467E9E47075A90F7E650EB6B7A45C",
+		gy:      "7E089FED7FBA344282CAFBD6F7E319F7C0B0BD59E2CA4BDB556D61A5",
+		n:       "010000000000000000000000000001DCE8D2EC6184CAF0A971769FB1F7",
+		endomorphism: &endomorphism{
+			beta:   "FE0E87005B4E83761908C5131D552A850B3F58B749C37CF5B84D6768",
+			lambda: "60DCD2104C4CBC0BE6EEEFC2BDD610739EC34E317F9B33046C9E4788",
+			a1:     "6B8CF07D4CA75C88957D9D670591",
+			b1:     "-B8ADF1378A6EB73409FA6C9C637D",
+			a2:     "1243AE1B4D71613BC9F780A03690E",
+			b2:     "6B8CF07D4CA75C88957D9D670591",
+		},
+	}
+
+	// 『Programming Bitcoin』などで使われる y^2 = x^3 + 7 over F_223
+	// 群の位数は 252 = 2^2 * 3^2 * 7 で巡回群ではないので、位数が素数7の点 (15, 86) を生成元にする
+	textbook223 = &curve{
+		name:    "textbook223",
+		bitSize: 8,
+		p:       "DF",
+		a:       "0",
+		b:       "7",
+		gx:      "F",
+		gy:      "56",
+		n:       "7",
+	}
+
+	all = []*curve{secp256k1, p256, secp192k1, secp224k1, textbook223}
+)
+
+// Secp256k1() : secp256k1 (SEC 2)
+// GLV法の定数が設定されている
+func Secp256k1() *models.EllipticCurve {
+	return secp256k1.get()
+}
+
+// P256() : NIST P-256 (secp256r1, prime256v1)
+func P256() *models.EllipticCurve {
+	return p256.get()
+}
+
+// Secp192k1() : secp192k1 (SEC 2)
+// GLV法の定数が設定されている
+func Secp192k1() *models.EllipticCurve {
+	return secp192k1.get()
+}
+
+// Secp224k1() : secp224k1 (SEC 2)
+// GLV法の定数が設定されている
+func Secp224k1() *models.EllipticCurve {
+	return secp224k1.get()
+}
+
+// Textbook223() : 学習用の y^2 = x^3 + 7 over F_223 (G = (15, 86), 位数7)
+// 安全ではないので、動作の確認以外には使わないこと
+func Textbook223() *models.EllipticCurve {
+	return textbook223.get()
+}
+
+// ByName() : 名前から曲線を探す (大文字・小文字は区別しない)
+// "P-256" は "secp256r1", "prime256v1" でも見つかる
+func ByName(name string) (*models.EllipticCurve, error) {
+	for _, c := range all {
+		if strings.EqualFold(c.name, name) {
+			return c.get(), nil
+		}
+		for _, alias := range c.aliases {
+			if strings.EqualFold(alias, name) {
+				return c.get(), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownCurve, name)
+}
+
+// ByOID() : ASN.1 のオブジェクト識別子から曲線を探す (RFC 5480 の namedCurve)
+func ByOID(oid asn1.ObjectIdentifier) (*models.EllipticCurve, error) {
+	for _, c := range all {
+		if c.oid != nil && c.oid.Equal(oid) {
+			return c.get(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnknownCurve, oid)
+}
+
+// OID() : この package の曲線のオブジェクト識別子
+// この package の曲線でない場合や、識別子が割り当てられていない曲線では ok == false
+func OID(ec *models.EllipticCurve) (oid asn1.ObjectIdentifier, ok bool) {
+	for _, c := range all {
+		if c.oid != nil && c.get() == ec {
+			return c.oid, true
+		}
+	}
+	return nil, false
+}
+
+func (c *curve) get() *models.EllipticCurve {
+	c.once.Do(func() {
+		c.ec = c.build()
+	})
+	return c.ec
+}
+
+// build() : 定数から曲線を作る
+// 定数はこの package に書かれたものだけなので、間違っていれば panic する
+func (c *curve) build() *models.EllipticCurve {
+	prime := mustHex(c.p)
+	G := models.NewEllipticCurvePoint(
+		models.NewFiniteField(mustHex(c.gx), prime),
+		models.NewFiniteField(mustHex(c.gy), prime),
+		false,
+	)
+	ec := models.NewEllipticCurve(
+		models.NewFiniteField(mustHex(c.a), prime),
+		models.NewFiniteField(mustHex(c.b), prime),
+		prime,
+		G,
+		c.bitSize,
+		c.name,
+		mustHex(c.n),
+	)
+	if !ec.IsOnCurveP(G) {
+		panic(fmt.Sprintf("curves: the generator of %v is not on the curve", c.name))
+	}
+	// n*G == 0 などを確かめておくと、ScalarBaseMultP() が k を n で割った余りで計算できる
+	if report := ec.Validate(); !report.OK() {
+		panic(fmt.Sprintf("curves: invalid parameters for %v: %v", c.name, report.Failed()))
+	}
+
+	if e := c.endomorphism; e != nil {
+		err := ec.SetEndomorphism(&models.Endomorphism{
+			Beta:   mustHex(e.beta),
+			Lambda: mustHex(e.lambda),
+			A1:     mustHex(e.a1),
+			B1:     mustHex(e.b1),
+			A2:     mustHex(e.a2),
+			B2:     mustHex(e.b2),
+		})
+		if err != nil {
+			panic(fmt.Sprintf("curves: %v: %v", c.name, err))
+		}
+	}
+	return ec
+}
+
+func mustHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("curves: invalid hex: " + s)
+	}
+	return n
+}
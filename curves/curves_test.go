@@ -0,0 +1,187 @@
+package curves
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// withoutEndomorphism() : 同じパラメータで、GLV法を使わない曲線を作る
+func withoutEndomorphism(ec *models.EllipticCurve) *models.EllipticCurve {
+	params := ec.Params()
+	G := models.NewEllipticCurvePoint(
+		models.NewFiniteField(params.Gx, params.P),
+		models.NewFiniteField(params.Gy, params.P),
+		false,
+	)
+	return models.NewEllipticCurve(
+		models.NewFiniteField(ec.A(), params.P),
+		models.NewFiniteField(params.B, params.P),
+		params.P,
+		G,
+		params.BitSize,
+		params.Name,
+		params.N,
+	)
+}
+
+func Test_Curves(t *testing.T) {
+	tests := []struct {
+		name        string
+		curve       func() *models.EllipticCurve
+		wantName    string
+		wantBitSize int
+		wantOID     asn1.ObjectIdentifier
+	}{
+		{name: "Secp256k1", curve: Secp256k1, wantName: "secp256k1", wantBitSize: 256, wantOID: asn1.ObjectIdentifier{1, 3, 132, 0, 10}},
+		{name: "P256", curve: P256, wantName: "P-256", wantBitSize: 256, wantOID: asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}},
+		{name: "Secp192k1", curve: Secp192k1, wantName: "secp192k1", wantBitSize: 192, wantOID: asn1.ObjectIdentifier{1, 3, 132, 0, 31}},
+		{name: "Secp224k1", curve: Secp224k1, wantName: "secp224k1", wantBitSize: 224, wantOID: asn1.ObjectIdentifier{1, 3, 132, 0, 32}},
+		{name: "Textbook223", curve: Textbook223, wantName: "textbook223", wantBitSize: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := tt.curve()
+			if ec != tt.curve() {
+				t.Errorf("%v() returned different curves", tt.name)
+			}
+
+			params := ec.Params()
+			if params.Name != tt.wantName || params.BitSize != tt.wantBitSize {
+				t.Errorf("%v : Params() Name, BitSize = %v, %v, want %v, %v", tt.name, params.Name, params.BitSize, tt.wantName, tt.wantBitSize)
+			}
+			if params.P.BitLen() != tt.wantBitSize {
+				t.Errorf("%v : P has %v bits, want %v", tt.name, params.P.BitLen(), tt.wantBitSize)
+			}
+			if !ec.IsOnCurve(params.Gx, params.Gy) {
+				t.Errorf("%v : G is not on the curve", tt.name)
+			}
+			if nG := ec.ScalarMultP(ec.Generator(), params.N.Bytes()); !nG.IsZero {
+				t.Errorf("%v : N*G = %v, want zero", tt.name, nG)
+			}
+
+			oid, ok := OID(ec)
+			if ok != (tt.wantOID != nil) || (ok && !oid.Equal(tt.wantOID)) {
+				t.Errorf("%v : OID() = %v, %v, want %v", tt.name, oid, ok, tt.wantOID)
+			}
+			if tt.wantOID != nil {
+				if got, err := ByOID(tt.wantOID); err != nil || got != ec {
+					t.Errorf("%v : ByOID(%v) = %v, %v", tt.name, tt.wantOID, got, err)
+				}
+			}
+			if got, err := ByName(tt.wantName); err != nil || got != ec {
+				t.Errorf("%v : ByName(%q) = %v, %v", tt.name, tt.wantName, got, err)
+			}
+		})
+	}
+}
+
+func Test_Curves_Endomorphism(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, curve := range []func() *models.EllipticCurve{Secp256k1, Secp192k1, Secp224k1} {
+		ec := curve()
+		plain := withoutEndomorphism(ec)
+		n := ec.Params().N
+		Q := plain.ScalarBaseMultP([]byte{0x2a})
+
+		for i := 0; i < 20; i++ {
+			k := new(big.Int).Rand(r, n).Bytes()
+			want := plain.ScalarMultP(Q, k)
+			if got := ec.ScalarMultP(Q, k); got.String() != want.String() {
+				t.Errorf("%v : ScalarMultP(Q, %x) = %v, want %v", ec.Params().Name, k, got, want)
+			}
+		}
+	}
+}
+
+func Test_P256_MatchesCryptoElliptic(t *testing.T) {
+	ec := P256()
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 10; i++ {
+		k := new(big.Int).Rand(r, ec.Params().N).Bytes()
+		wantX, wantY := elliptic.P256().ScalarBaseMult(k)
+		gotX, gotY := ec.ScalarBaseMult(k)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			t.Errorf("ScalarBaseMult(%x) = (%x, %x), want (%x, %x)", k, gotX, gotY, wantX, wantY)
+		}
+	}
+}
+
+func Test_ByName(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    func() *models.EllipticCurve
+		wantErr error
+	}{
+		{name: "alias secp256r1", arg: "secp256r1", want: P256},
+		{name: "alias prime256v1", arg: "prime256v1", want: P256},
+		{name: "case insensitive", arg: "SECP256K1", want: Secp256k1},
+		{name: "unknown", arg: "curve25519", wantErr: ErrUnknownCurve},
+		{name: "empty", arg: "", wantErr: ErrUnknownCurve},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ByName(tt.arg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : ByName(%q) err = %v, want %v", tt.name, tt.arg, err, tt.wantErr)
+			}
+			if tt.want != nil && got != tt.want() {
+				t.Errorf("%v : ByName(%q) = %v, want %v", tt.name, tt.arg, got.Params().Name, tt.want().Params().Name)
+			}
+		})
+	}
+}
+
+func Test_ByOID_Unknown(t *testing.T) {
+	// secp384r1
+	if _, err := ByOID(asn1.ObjectIdentifier{1, 3, 132, 0, 34}); !errors.Is(err, ErrUnknownCurve) {
+		t.Errorf("ByOID() err = %v, want %v", err, ErrUnknownCurve)
+	}
+	if _, ok := OID(withoutEndomorphism(Secp256k1())); ok {
+		t.Errorf("OID() of a curve not from this package = ok")
+	}
+}
+
+func Test_Secp256k1_Concurrent(t *testing.T) {
+	const goroutines = 8
+	got := make([]*models.EllipticCurve, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = Secp256k1()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < goroutines; i++ {
+		if got[i] != got[0] {
+			t.Fatalf("Secp256k1() returned different curves")
+		}
+	}
+}
+
+func Test_Curves_Validate(t *testing.T) {
+	for _, curve := range []func() *models.EllipticCurve{Secp256k1, P256, Secp192k1, Secp224k1} {
+		ec := curve()
+		if report := ec.Validate(models.WithMOVCheck(100), models.WithAnomalousCheck()); !report.OK() {
+			t.Errorf("%v : Validate() failed\n%v", ec.Params().Name, report)
+		}
+	}
+
+	// 学習用の曲線は小さすぎるので、MOV の検証には通らない
+	if report := Textbook223().Validate(); !report.OK() {
+		t.Errorf("textbook223 : Validate() failed\n%v", report)
+	}
+}
@@ -0,0 +1,97 @@
+package dlog
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// maxBSGSTable : BSGS で覚えておく baby step の数の上限 (n がおよそ 2^40 まで)
+const maxBSGSTable = 1 << 20
+
+// babyStep : j*P のy座標とj
+type babyStep struct {
+	j int64
+	y *big.Int
+}
+
+// BSGS() : k*P == Q となる 0 <= k < n を baby-step giant-step で求める
+// n は n*P == 0 となる正の整数 (Pの位数やその倍数) で、Pの位数が sqrt(n) 以下なら k はそれより小さくなる
+// sqrt(n) 個の点を覚えておくので
+// sqrt(n) が maxBSGSTable を超える場合は ErrTooLarge
+// Q が <P> に含まれない場合は ErrNotFound、ctx がキャンセルされた場合は ctx.Err() を返す
+func BSGS(ctx context.Context, ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, n *big.Int, opts ...Option) (*big.Int, error) {
+	if err := checkInput(ec, P, Q, n); err != nil {
+		return nil, err
+	}
+	c := newConfig(opts)
+	rep := newReporter(c, "bsgs", sqrtSteps(n, 2))
+	defer rep.report(true)
+	return bsgs(ctx, ec, P, Q, n, rep)
+}
+
+// bsgs() : m = ceil(sqrt(n)) として、baby step で j*P (1 <= j <= m) を覚えておき、
+// giant step で Q - i*m*P が ±j*P になるiを探す (k = i*m ± j)
+func bsgs(ctx context.Context, ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, n *big.Int, rep *reporter) (*big.Int, error) {
+	mm := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(mm, mm).Cmp(n) < 0 {
+		mm.Add(mm, big.NewInt(1))
+	}
+	if mm.Cmp(big.NewInt(maxBSGSTable)) > 0 {
+		return nil, fmt.Errorf("%w: BSGS needs %v baby steps", ErrTooLarge, mm)
+	}
+	m := mm.Int64()
+
+	// Pの位数が m 以下なら baby step で求まるので、それで割った値を返す
+	order := n
+	baby := make(map[string]babyStep, m)
+	jP := models.NewEllipticCurvePoint(nil, nil, true)
+	for j := int64(1); j <= m; j++ {
+		if j%progressInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			rep.add(progressInterval)
+		}
+		jP = ec.AddP(jP, P)
+		if jP.IsZero {
+			// Pの位数は j なので、これ以上覚えても同じ点になる
+			order = big.NewInt(j)
+			break
+		}
+		key := string(jP.X.Value.Bytes())
+		if _, ok := baby[key]; !ok {
+			baby[key] = babyStep{j: j, y: jP.Y.Value}
+		}
+	}
+
+	negMP := neg(ec.ScalarMultP(P, mm.Bytes()))
+	R := Q
+	for i := int64(0); i <= m; i++ {
+		if i > 0 && i%progressInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			rep.add(progressInterval)
+		}
+
+		k := new(big.Int).Mul(big.NewInt(i), mm)
+		if R.IsZero {
+			return k.Mod(k, order), nil
+		}
+		if s, ok := baby[string(R.X.Value.Bytes())]; ok {
+			if s.y.Cmp(R.Y.Value) == 0 {
+				// Q - i*m*P == j*P
+				k.Add(k, big.NewInt(s.j))
+			} else {
+				// Q - i*m*P == -j*P
+				k.Sub(k, big.NewInt(s.j))
+			}
+			return k.Mod(k, order), nil
+		}
+		R = ec.AddP(R, negMP)
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,93 @@
+package dlog
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func Test_BSGS(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	zero := models.NewEllipticCurvePoint(nil, nil, true)
+	k32, Q32 := prime32.randomLog(r)
+
+	tests := []struct {
+		name    string
+		ec      *models.EllipticCurve
+		P, Q    *models.EllipticCurvePoint
+		n       *big.Int
+		want    *big.Int
+		wantErr error
+	}{
+		{name: "k = 0", ec: toy223.ec, P: toy223.G, Q: zero, n: toy223.n, want: big.NewInt(0)},
+		{name: "k = 1", ec: toy223.ec, P: toy223.G, Q: toy223.G, n: toy223.n, want: big.NewInt(1)},
+		{name: "k = 41", ec: toy223.ec, P: toy223.G, Q: toy223.ec.ScalarMultP(toy223.G, []byte{41}), n: toy223.n, want: big.NewInt(41)},
+		{name: "n is a multiple of the order", ec: toy223.ec, P: toy223.G, Q: toy223.ec.ScalarMultP(toy223.G, []byte{30}), n: big.NewInt(252 * 42), want: big.NewInt(30)},
+		{name: "32-bit prime order", ec: prime32.ec, P: prime32.G, Q: Q32, n: prime32.n, want: k32},
+		{
+			// (15, 86) の位数は7で、(47, 71) はその部分群に含まれない
+			name: "Q not in <P>", ec: toy223.ec, P: point(toy223.ec, 15, 86), Q: point(toy223.ec, 47, 71), n: big.NewInt(7),
+			wantErr: ErrNotFound,
+		},
+		{
+			// (47, 71) の位数は21なので、21*Q は0になるが <(15, 86)> には含まれない
+			name: "Q not in <P> with n*Q = 0", ec: toy223.ec, P: point(toy223.ec, 15, 86), Q: point(toy223.ec, 47, 71), n: big.NewInt(21),
+			wantErr: ErrNotFound,
+		},
+		{name: "P not on curve", ec: toy223.ec, P: point(toy223.ec, 15, 85), Q: zero, n: toy223.n, wantErr: ErrInvalidInput},
+		{name: "n*P != 0", ec: toy223.ec, P: toy223.G, Q: zero, n: big.NewInt(41), wantErr: ErrInvalidInput},
+		{name: "too large", ec: smooth48.ec, P: smooth48.G, Q: smooth48.G, n: smooth48.n, wantErr: ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BSGS(context.Background(), tt.ec, tt.P, tt.Q, tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : BSGS() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if tt.want != nil && got.Cmp(tt.want) != 0 {
+				t.Errorf("%v : BSGS() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_BSGS_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, Q := prime36.randomLog(rand.New(rand.NewSource(2)))
+	if _, err := BSGS(ctx, prime36.ec, prime36.G, Q, prime36.n); !errors.Is(err, context.Canceled) {
+		t.Errorf("BSGS() err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func Test_BSGS_Progress(t *testing.T) {
+	var progress []Progress
+	k, Q := prime32.randomLog(rand.New(rand.NewSource(3)))
+	got, err := BSGS(context.Background(), prime32.ec, prime32.G, Q, prime32.n, WithProgress(func(p Progress) {
+		progress = append(progress, p)
+	}))
+	if err != nil || got.Cmp(k) != 0 {
+		t.Fatalf("BSGS() = %v, %v, want %v", got, err, k)
+	}
+
+	if len(progress) < 2 {
+		t.Fatalf("BSGS() reported %v times", len(progress))
+	}
+	for i, p := range progress {
+		if p.Algorithm != "bsgs" || p.Expected != progress[0].Expected || p.Expected == 0 {
+			t.Errorf("progress[%d] = %+v", i, p)
+		}
+		if i > 0 && p.Steps < progress[i-1].Steps {
+			t.Errorf("progress[%d].Steps = %v < %v", i, p.Steps, progress[i-1].Steps)
+		}
+		if p.Done != (i == len(progress)-1) {
+			t.Errorf("progress[%d].Done = %v", i, p.Done)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// dlog : 楕円曲線上の離散対数問題 k*P == Q を解いてkを求める
+// 小さい曲線や、位数が小さい素数の積になる曲線の秘密鍵がすぐに求まってしまうことを確かめるためのもので、
+// secp256k1 のように位数が大きな素数の曲線では現実的な時間では終わらない
+package dlog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+var (
+	ErrInvalidInput = errors.New("dlog: invalid input")
+	ErrNotFound     = errors.New("dlog: logarithm not found")
+	ErrTooLarge     = errors.New("dlog: order is too large")
+)
+
+// progressInterval : この回数の点の加算ごとに進捗を通知する
+const progressInterval = 1 << 12
+
+// Progress : 計算の進捗
+type Progress struct {
+	Algorithm string // "bsgs", "rho", "pohlig-hellman"
+	Steps     uint64 // ここまでの点の加算の回数
+	Expected  uint64 // 解が見つかるまでにかかる加算の回数の見積もり
+	Done      bool   // 計算が終わった (見つからなかった場合やキャンセルされた場合も含む)
+}
+
+// Option : 計算の挙動を変更するオプション
+type Option func(*config)
+
+type config struct {
+	workers  int
+	progress func(Progress)
+}
+
+// WithWorkers() : Pollard の rho 法で並列に walk させるgoroutineの数を指定する (既定値は GOMAXPROCS)
+func WithWorkers(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.workers = n
+		}
+	}
+}
+
+// WithProgress() : 進捗を通知する関数を指定する
+// f は progressInterval 回の加算ごとと、計算が終わったときに呼ばれる
+// 複数のgoroutineから同時に呼ばれることはない
+func WithProgress(f func(Progress)) Option {
+	return func(c *config) {
+		c.progress = f
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// reporter : 複数のgoroutineから加算の回数を集計して、進捗を通知する
+type reporter struct {
+	algorithm string
+	expected  uint64
+	steps     atomic.Uint64
+
+	mu sync.Mutex
+	f  func(Progress)
+}
+
+func newReporter(c *config, algorithm string, expected uint64) *reporter {
+	return &reporter{algorithm: algorithm, expected: expected, f: c.progress}
+}
+
+// add() : 加算の回数を増やし、progressInterval の倍数をまたいだら通知する
+func (r *reporter) add(n uint64) {
+	s := r.steps.Add(n)
+	if r.f != nil && s/progressInterval != (s-n)/progressInterval {
+		r.report(false)
+	}
+}
+
+func (r *reporter) report(done bool) {
+	if r.f == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.f(Progress{Algorithm: r.algorithm, Steps: r.steps.Load(), Expected: r.expected, Done: done})
+}
+
+// checkInput() : P, Q が曲線上の点で、n*P == 0 となるかを確認する
+// n*Q != 0 なら Q は <P> に含まれないので ErrNotFound を返す
+func checkInput(ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, n *big.Int) error {
+	if ec == nil || n == nil || n.Sign() <= 0 {
+		return fmt.Errorf("%w: missing curve or order", ErrInvalidInput)
+	}
+
+	nP, err := ec.ScalarMultPChecked(P, n.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w: P: %v", ErrInvalidInput, err)
+	}
+	if !nP.IsZero {
+		return fmt.Errorf("%w: n*P is not the point at infinity", ErrInvalidInput)
+	}
+
+	nQ, err := ec.ScalarMultPChecked(Q, n.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w: Q: %v", ErrInvalidInput, err)
+	}
+	if !nQ.IsZero {
+		return fmt.Errorf("%w: Q is not in the subgroup generated by P", ErrNotFound)
+	}
+	return nil
+}
+
+// isLog() : k*P == Q かどうか
+func isLog(ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, k *big.Int) bool {
+	kP := ec.ScalarMultP(P, k.Bytes())
+	if kP.IsZero || Q.IsZero {
+		return kP.IsZero == Q.IsZero
+	}
+	return kP.X.Equals(Q.X) && kP.Y.Equals(Q.Y)
+}
+
+// neg() : -P
+func neg(P *models.EllipticCurvePoint) *models.EllipticCurvePoint {
+	if P.IsZero {
+		return P
+	}
+	return models.NewEllipticCurvePoint(P.X, new(models.FiniteField).Neg(P.Y), false)
+}
+
+// sqrtSteps() : c*sqrt(n) を加算の回数の見積もりとして返す
+func sqrtSteps(n *big.Int, c float64) uint64 {
+	f, _ := new(big.Float).SetInt(n).Float64()
+	return uint64(c * math.Sqrt(f))
+}
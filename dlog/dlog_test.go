@@ -0,0 +1,91 @@
+package dlog
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// testCurve : テストに使う小さい曲線 y^2 = x^3 + a*x + b over F_p と、位数nの点G
+type testCurve struct {
+	ec *models.EllipticCurve
+	G  *models.EllipticCurvePoint
+	n  *big.Int
+}
+
+func newTestCurve(a, b, p, gx, gy, n int64) *testCurve {
+	prime := big.NewInt(p)
+	ec := models.NewEllipticCurve(
+		models.NewFiniteField(new(big.Int).Mod(big.NewInt(a), prime), prime),
+		models.NewFiniteField(big.NewInt(b), prime),
+		prime, nil, prime.BitLen(), "toy", nil,
+	)
+	return &testCurve{ec: ec, G: point(ec, gx, gy), n: big.NewInt(n)}
+}
+
+func point(ec *models.EllipticCurve, x, y int64) *models.EllipticCurvePoint {
+	p := ec.Params().P
+	return models.NewEllipticCurvePoint(models.NewFiniteField(big.NewInt(x), p), models.NewFiniteField(big.NewInt(y), p), false)
+}
+
+var (
+	// #E = 252 = 2^2 * 3^2 * 7 (Z/42 × Z/6), Gの位数は42
+	toy223 = newTestCurve(0, 7, 223, 17, 56, 42)
+	// #E = 4294959973 (素数)
+	prime32 = newTestCurve(-3, 31, 4294967291, 281908850, 1803511820, 4294959973)
+	// #E = 68719022539 (素数)
+	prime36 = newTestCurve(-3, 33, 68719476731, 57126981448, 44335163771, 68719022539)
+	// #E = 281474986698516 = 2^2 * 3 * 13 * 4273 * 15259 * 27673
+	// Gの位数は #E / 2 = 140737493349258
+	smooth48 = newTestCurve(-3, 13, 281474976710597, 181797657613938, 161449717170680, 140737493349258)
+)
+
+// randomLog() : ランダムな 0 <= k < n と Q = k*G
+func (c *testCurve) randomLog(r *rand.Rand) (*big.Int, *models.EllipticCurvePoint) {
+	k := new(big.Int).Rand(r, c.n)
+	return k, c.ec.ScalarMultP(c.G, k.Bytes())
+}
+
+func Test_crt(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, m, b, n int64
+		want       int64
+	}{
+		{name: "x = 2 mod 3, x = 3 mod 5", a: 2, m: 3, b: 3, n: 5, want: 8},
+		{name: "x = 0 mod 1, x = 4 mod 7", a: 0, m: 1, b: 4, n: 7, want: 4},
+		{name: "x = 5 mod 12, x = 6 mod 7", a: 5, m: 12, b: 6, n: 7, want: 41},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, mn := crt(big.NewInt(tt.a), big.NewInt(tt.m), big.NewInt(tt.b), big.NewInt(tt.n))
+			if x.Int64() != tt.want || mn.Int64() != tt.m*tt.n {
+				t.Errorf("%v : crt() = %v, %v, want %v, %v", tt.name, x, mn, tt.want, tt.m*tt.n)
+			}
+		})
+	}
+}
+
+func Test_lowWord(t *testing.T) {
+	x, _ := new(big.Int).SetString("123456789abcdef0fedcba9876543210", 16)
+	tests := []struct {
+		name string
+		x    *big.Int
+		want uint64
+	}{
+		{name: "zero", x: big.NewInt(0), want: 0},
+		{name: "small", x: big.NewInt(0x1234), want: 0x1234},
+		{name: "large", x: x, want: 0xfedcba9876543210},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lowWord(tt.x); got != tt.want {
+				t.Errorf("%v : lowWord() = %x, want %x", tt.name, got, tt.want)
+			}
+		})
+	}
+}
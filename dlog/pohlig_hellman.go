@@ -0,0 +1,111 @@
+package dlog
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// pohligHellmanBSGSBits : 部分問題の素因数がこのビット数以下なら BSGS を、それより大きければ rho 法を使う
+const pohligHellmanBSGSBits = 32
+
+// PohligHellman() : k*P == Q となる 0 <= k < ord(P) を Pohlig–Hellman のアルゴリズムで求める
+// n は n*P == 0 となる正の整数 (群の位数など) で、Pの位数 ord(P) = Π q^e を求めて
+// k mod q^e を位数qの部分群の離散対数 e 回で求め、中国剰余定理でまとめる
+// かかる時間は ord(P) の最大の素因数 q でおよそ sqrt(q) になるので、位数が素数でない曲線はこれで破られる
+//
+// Q が <P> に含まれない場合は ErrNotFound、ctx がキャンセルされた場合は ctx.Err() を返す
+func PohligHellman(ctx context.Context, ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, n *big.Int, opts ...Option) (*big.Int, error) {
+	if err := checkInput(ec, P, Q, n); err != nil {
+		return nil, err
+	}
+	c := newConfig(opts)
+
+	order, err := ec.PointOrder(P, n)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if !ec.ScalarMultP(Q, order.Bytes()).IsZero {
+		return nil, ErrNotFound
+	}
+	factors, err := models.Factorize(order)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	var expected uint64
+	for _, f := range factors {
+		expected += uint64(f.Exponent) * subproblemSteps(f.Prime)
+	}
+	rep := newReporter(c, "pohlig-hellman", expected)
+	defer rep.report(true)
+
+	k, m := new(big.Int), big.NewInt(1)
+	for _, f := range factors {
+		x, err := pohligHellmanPrimePower(ctx, ec, P, Q, order, f, c.workers, rep)
+		if err != nil {
+			return nil, err
+		}
+		qe := new(big.Int).Exp(f.Prime, big.NewInt(int64(f.Exponent)), nil)
+		k, m = crt(k, m, x, qe)
+	}
+
+	if !isLog(ec, P, Q, k) {
+		return nil, ErrNotFound
+	}
+	return k, nil
+}
+
+// pohligHellmanPrimePower() : ord(P) = q^e * m として、k mod q^e を q 進数で1桁ずつ求める
+// γ = (ord(P)/q)*P (位数q)、x = d_0 + d_1*q + ... + d_(i-1)*q^(i-1) として
+// (ord(P)/q^(i+1)) * (Q - x*P) == d_i * γ
+func pohligHellmanPrimePower(ctx context.Context, ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, order *big.Int, f models.PrimePower, workers int, rep *reporter) (*big.Int, error) {
+	q := f.Prime
+	gamma := ec.ScalarMultP(P, new(big.Int).Quo(order, q).Bytes())
+
+	x := new(big.Int)
+	qi := big.NewInt(1) // q^i
+	for i := 0; i < f.Exponent; i++ {
+		cofactor := new(big.Int).Quo(order, new(big.Int).Mul(qi, q))
+		R := ec.AddP(Q, neg(ec.ScalarMultP(P, x.Bytes())))
+		h := ec.ScalarMultP(R, cofactor.Bytes())
+
+		var d *big.Int
+		var err error
+		if q.BitLen() <= pohligHellmanBSGSBits {
+			d, err = bsgs(ctx, ec, gamma, h, q, rep)
+		} else {
+			d, err = rho(ctx, ec, gamma, h, q, workers, rep)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		x.Add(x, new(big.Int).Mul(d, qi))
+		qi.Mul(qi, q)
+	}
+	return x, nil
+}
+
+// subproblemSteps() : 位数qの部分問題にかかる加算の回数の見積もり
+func subproblemSteps(q *big.Int) uint64 {
+	if q.BitLen() <= pohligHellmanBSGSBits {
+		return sqrtSteps(q, 2)
+	}
+	return sqrtSteps(q, 1.25)
+}
+
+// crt() : x ≡ a (mod m), x ≡ b (mod n) となる x mod m*n を求める (gcd(m, n) == 1)
+func crt(a, m, b, n *big.Int) (x, mn *big.Int) {
+	// x = a + m*t, t = (b - a) * m^-1 (mod n)
+	t := new(big.Int).Sub(b, a)
+	t.Mul(t, new(big.Int).ModInverse(new(big.Int).Mod(m, n), n))
+	t.Mod(t, n)
+
+	mn = new(big.Int).Mul(m, n)
+	x = t.Mul(t, m)
+	x.Add(x, a)
+	return x.Mod(x, mn), mn
+}
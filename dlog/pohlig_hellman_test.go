@@ -0,0 +1,121 @@
+package dlog
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func Test_PohligHellman(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	zero := models.NewEllipticCurvePoint(nil, nil, true)
+
+	// n は ord(G) の倍数なら、群の位数 #E = 2 * ord(G) でもよい
+	groupOrder := new(big.Int).Lsh(smooth48.n, 1)
+
+	tests := []struct {
+		name    string
+		c       *testCurve
+		n       *big.Int
+		k       int64 // -1 ならランダム
+		wantErr error
+	}{
+		{name: "smooth 48-bit order", c: smooth48, n: smooth48.n, k: -1},
+		{name: "n is the group order", c: smooth48, n: groupOrder, k: -1},
+		{name: "toy curve", c: toy223, n: big.NewInt(252), k: 37},
+		{name: "k = 0", c: toy223, n: big.NewInt(252), k: 0},
+		{name: "32-bit prime order", c: prime32, n: prime32.n, k: -1},
+		{name: "36-bit prime order", c: prime36, n: prime36.n, k: -1},
+		{name: "n*P != 0", c: toy223, n: big.NewInt(100), k: 1, wantErr: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := big.NewInt(tt.k)
+			if tt.k < 0 {
+				k = new(big.Int).Rand(r, tt.c.n)
+			}
+			Q := zero
+			if k.Sign() != 0 {
+				Q = tt.c.ec.ScalarMultP(tt.c.G, k.Bytes())
+			}
+
+			got, err := PohligHellman(context.Background(), tt.c.ec, tt.c.G, Q, tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : PohligHellman() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && got.Cmp(k) != 0 {
+				t.Errorf("%v : PohligHellman() = %v, want %v", tt.name, got, k)
+			}
+		})
+	}
+}
+
+func Test_PohligHellman_NotInSubgroup(t *testing.T) {
+	// (15, 86) の位数は7、(47, 71) の位数は21
+	ec := toy223.ec
+	P, Q := point(ec, 15, 86), point(ec, 47, 71)
+	for _, n := range []int64{7, 21, 252} {
+		if _, err := PohligHellman(context.Background(), ec, P, Q, big.NewInt(n)); !errors.Is(err, ErrNotFound) {
+			t.Errorf("PohligHellman(n = %v) err = %v, want %v", n, err, ErrNotFound)
+		}
+	}
+}
+
+func Test_PohligHellman_Progress(t *testing.T) {
+	var progress []Progress
+	k, Q := smooth48.randomLog(rand.New(rand.NewSource(6)))
+	got, err := PohligHellman(context.Background(), smooth48.ec, smooth48.G, Q, smooth48.n, WithProgress(func(p Progress) {
+		progress = append(progress, p)
+	}))
+	if err != nil || got.Cmp(k) != 0 {
+		t.Fatalf("PohligHellman() = %v, %v, want %v", got, err, k)
+	}
+
+	last := progress[len(progress)-1]
+	if !last.Done || last.Algorithm != "pohlig-hellman" || last.Expected == 0 {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+// secp256k1 の位数は素数なので Pohlig–Hellman で分解できず、256ビットの rho 法になって終わらない
+func Test_PohligHellman_Secp256k1(t *testing.T) {
+	ec := curves.Secp256k1()
+	Q := ec.ScalarBaseMultP([]byte{0x2a})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := PohligHellman(ctx, ec, ec.Generator(), Q, ec.Params().N); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PohligHellman() err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// 48ビットでも位数が smooth なら、36ビットの素数位数より速く解ける
+func BenchmarkDiscreteLog(b *testing.B) {
+	r := rand.New(rand.NewSource(7))
+	benchmarks := []struct {
+		name  string
+		c     *testCurve
+		solve func(context.Context, *models.EllipticCurve, *models.EllipticCurvePoint, *models.EllipticCurvePoint, *big.Int, ...Option) (*big.Int, error)
+	}{
+		{name: "PohligHellman/smooth48", c: smooth48, solve: PohligHellman},
+		{name: "PollardRho/prime36", c: prime36, solve: PollardRho},
+	}
+
+	for _, bm := range benchmarks {
+		_, Q := bm.c.randomLog(r)
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := bm.solve(context.Background(), bm.c.ec, bm.c.G, Q, bm.c.n); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
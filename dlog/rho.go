@@ -0,0 +1,223 @@
+package dlog
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+const (
+	// rhoPartitions : r-adding walk で使う点の数 (Teske の結果では20程度で十分ランダムになる)
+	rhoPartitions = 32
+	// rhoDistinguishedPoints : 解が見つかるまでに見つかる distinguished point の数の目安
+	rhoDistinguishedPoints = 1 << 8
+	// rhoMaxDegenerate : kが求まらない衝突がこの回数起きたら、Q は <P> にないとみなす
+	rhoMaxDegenerate = 16
+)
+
+// randReader : walk の始点などに使う乱数 (テストでは失敗するものに差し替える)
+var randReader io.Reader = rand.Reader
+
+// distinguished : walk が見つけた distinguished point X = a*P + b*Q
+type distinguished struct {
+	X    *models.EllipticCurvePoint
+	a, b *big.Int
+}
+
+// PollardRho() : k*P == Q となる 0 <= k < n を Pollard の rho 法で求める
+// n はPの位数で、素数でなければならない (そうでなければ PohligHellman() を使う)
+//
+// 複数のgoroutineで r-adding walk を並列に進め、x座標の下位ビットが0の点 (distinguished point) だけを集めて
+// 別のwalkと同じ点にたどり着いたら a1*P + b1*Q == ±(a2*P + b2*Q) からkを求める (van Oorschot–Wiener)
+// 覚えておく点は少ないので、n が大きくてもメモリは足りるが、およそ 1.25*sqrt(n) 回の加算がかかる
+//
+// Q が <P> に含まれない場合は ErrNotFound、ctx がキャンセルされた場合は ctx.Err() を返す
+func PollardRho(ctx context.Context, ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, n *big.Int, opts ...Option) (*big.Int, error) {
+	if err := checkInput(ec, P, Q, n); err != nil {
+		return nil, err
+	}
+	if !n.ProbablyPrime(20) {
+		return nil, fmt.Errorf("%w: n = %v is not a prime", ErrInvalidInput, n)
+	}
+	c := newConfig(opts)
+	rep := newReporter(c, "rho", sqrtSteps(n, 1.25))
+	defer rep.report(true)
+	return rho(ctx, ec, P, Q, n, c.workers, rep)
+}
+
+func rho(ctx context.Context, ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, n *big.Int, workers int, rep *reporter) (*big.Int, error) {
+	if Q.IsZero {
+		return new(big.Int), nil
+	}
+
+	// R_j = c_j*P + d_j*Q
+	var steps [rhoPartitions]distinguished
+	for j := range steps {
+		a, b, err := randScalars(n)
+		if err != nil {
+			return nil, err
+		}
+		steps[j] = distinguished{X: combine(ec, P, Q, a, b), a: a, b: b}
+	}
+
+	// walk は平均して 2^bits 回ごとに distinguished point を見つける
+	// (nが大きすぎて現実的に終わらない場合でも、maxTrail があふれないように抑えておく)
+	bits := n.BitLen()/2 - 8
+	if bits < 0 {
+		bits = 0
+	}
+	if bits > 32 {
+		bits = 32
+	}
+	mask := uint64(1)<<uint(bits) - 1
+	maxTrail := 20 << uint(bits)
+
+	ctx, cancel := context.WithCancel(ctx)
+	found := make(chan distinguished)
+	// 乱数が作れずに止まった walk のエラー (全部のworkerが送っても詰まらないようにしておく)
+	failed := make(chan error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := walk(ctx, ec, P, Q, n, &steps, mask, maxTrail, found, rep); err != nil {
+				failed <- err
+			}
+		}()
+	}
+	// 解が見つかったら、残りの walk を止めてから返す
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	seen := make(map[string]distinguished)
+	degenerate := 0
+	for {
+		var d distinguished
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case err := <-failed:
+			return nil, err
+		case d = <-found:
+		}
+
+		key := string(d.X.X.Value.Bytes())
+		e, ok := seen[key]
+		if !ok {
+			seen[key] = d
+			continue
+		}
+
+		if k, ok := collide(d, e, n); ok && isLog(ec, P, Q, k) {
+			return k, nil
+		}
+		// b1 == b2 で k が求まらなかった
+		degenerate++
+		if degenerate >= rhoMaxDegenerate {
+			return nil, ErrNotFound
+		}
+		seen[key] = d
+	}
+}
+
+// walk() : ランダムな点から r-adding walk を始め、distinguished point を見つけたら found に送って新しく始め直す
+// maxTrail 回進んでも見つからない場合は、ループに入ったとみなして始め直す
+// ctx がキャンセルされたら nil を、乱数が作れなかったらそのエラーを返して止まる
+func walk(ctx context.Context, ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, n *big.Int, steps *[rhoPartitions]distinguished, mask uint64, maxTrail int, found chan<- distinguished, rep *reporter) error {
+	var count uint64
+	for ctx.Err() == nil {
+		a, b, err := randScalars(n)
+		if err != nil {
+			return err
+		}
+		X := combine(ec, P, Q, a, b)
+
+		for t := 0; t < maxTrail && !X.IsZero; t++ {
+			if count++; count == 256 {
+				if ctx.Err() != nil {
+					return nil
+				}
+				rep.add(count)
+				count = 0
+			}
+
+			x := lowWord(X.X.Value)
+			if x&mask == 0 {
+				select {
+				case found <- distinguished{X: X, a: a, b: b}:
+				case <-ctx.Done():
+					return nil
+				}
+				break
+			}
+
+			// x座標のハッシュの上位ビットで、次に足す点を選ぶ
+			s := &steps[(x*0x9e3779b97f4a7c15)>>59%rhoPartitions]
+			X = ec.AddP(X, s.X)
+			a = a.Add(a, s.a)
+			a.Mod(a, n)
+			b = b.Add(b, s.b)
+			b.Mod(b, n)
+		}
+	}
+	return nil
+}
+
+// collide() : a1*P + b1*Q == ±(a2*P + b2*Q) から k を求める
+func collide(d1, d2 distinguished, n *big.Int) (*big.Int, bool) {
+	num, den := new(big.Int), new(big.Int)
+	if d1.X.Y.Equals(d2.X.Y) {
+		// (a1 - a2) == (b2 - b1)*k
+		num.Sub(d1.a, d2.a)
+		den.Sub(d2.b, d1.b)
+	} else {
+		// (a1 + a2) == -(b1 + b2)*k
+		num.Add(d1.a, d2.a)
+		den.Add(d1.b, d2.b)
+		den.Neg(den)
+	}
+	if den.Mod(den, n).Sign() == 0 {
+		return nil, false
+	}
+	k := den.ModInverse(den, n)
+	k.Mul(k, num)
+	return k.Mod(k, n), true
+}
+
+// combine() : a*P + b*Q
+func combine(ec *models.EllipticCurve, P, Q *models.EllipticCurvePoint, a, b *big.Int) *models.EllipticCurvePoint {
+	return ec.AddP(ec.ScalarMultP(P, a.Bytes()), ec.ScalarMultP(Q, b.Bytes()))
+}
+
+// lowWord() : xの下位64ビット
+func lowWord(x *big.Int) uint64 {
+	b := x.Bytes()
+	if len(b) > 8 {
+		b = b[len(b)-8:]
+	}
+	var w uint64
+	for _, c := range b {
+		w = w<<8 | uint64(c)
+	}
+	return w
+}
+
+// randScalars() : [0, n) の一様な乱数を2つ
+func randScalars(n *big.Int) (a, b *big.Int, err error) {
+	if a, err = rand.Int(randReader, n); err != nil {
+		return nil, nil, fmt.Errorf("dlog: random scalar: %w", err)
+	}
+	if b, err = rand.Int(randReader, n); err != nil {
+		return nil, nil, fmt.Errorf("dlog: random scalar: %w", err)
+	}
+	return a, b, nil
+}
@@ -0,0 +1,131 @@
+package dlog
+
+import (
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func Test_PollardRho(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	zero := models.NewEllipticCurvePoint(nil, nil, true)
+	P7 := point(toy223.ec, 15, 86)
+
+	tests := []struct {
+		name    string
+		c       *testCurve
+		P       *models.EllipticCurvePoint
+		n       *big.Int
+		k       int64 // -1 ならランダム
+		workers int
+		wantErr error
+	}{
+		{name: "1 worker", c: prime32, k: -1, workers: 1},
+		{name: "4 workers", c: prime32, k: -1, workers: 4},
+		{name: "36-bit prime order", c: prime36, k: -1, workers: 4},
+		{name: "k = 0", c: prime32, k: 0, workers: 2},
+		{name: "order 7", c: toy223, P: P7, n: big.NewInt(7), k: 5, workers: 2},
+		{name: "order not prime", c: toy223, k: 5, workers: 2, wantErr: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			P, n := tt.c.G, tt.c.n
+			if tt.P != nil {
+				P, n = tt.P, tt.n
+			}
+			k := big.NewInt(tt.k)
+			if tt.k < 0 {
+				k = new(big.Int).Rand(r, n)
+			}
+			Q := zero
+			if k.Sign() != 0 {
+				Q = tt.c.ec.ScalarMultP(P, k.Bytes())
+			}
+
+			got, err := PollardRho(context.Background(), tt.c.ec, P, Q, n, WithWorkers(tt.workers))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : PollardRho() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && got.Cmp(k) != 0 {
+				t.Errorf("%v : PollardRho() = %v, want %v", tt.name, got, k)
+			}
+		})
+	}
+}
+
+func Test_PollardRho_Canceled(t *testing.T) {
+	// 256ビットの素数位数では終わらないので、タイムアウトで止まる
+	ec := curves.Secp256k1()
+	Q := ec.ScalarBaseMultP([]byte{0x2a})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var last Progress
+	_, err := PollardRho(ctx, ec, ec.Generator(), Q, ec.Params().N, WithWorkers(4), WithProgress(func(p Progress) {
+		last = p
+	}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PollardRho() err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("PollardRho() returned %v after the deadline", d)
+	}
+	if !last.Done || last.Algorithm != "rho" || last.Expected == 0 {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+var errRead = errors.New("read failed")
+
+// failingReader : 最初の n 回だけ crypto/rand から読み、それ以降は errRead を返す
+type failingReader struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (r *failingReader) Read(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.n <= 0 {
+		return 0, errRead
+	}
+	r.n--
+	return crand.Read(b)
+}
+
+func Test_PollardRho_RandError(t *testing.T) {
+	// secp256k1 の位数ではほぼ確実に rand.Int() は1回ずつしか読まないので、
+	// 2*rhoPartitions 回までは始めの R_j に使われ、その後は walk の始点で失敗する
+	ec := curves.Secp256k1()
+	Q := ec.ScalarBaseMultP([]byte{0x2a})
+
+	tests := []struct {
+		name  string
+		reads int
+	}{
+		{name: "steps", reads: 0},
+		{name: "walk", reads: 2 * rhoPartitions},
+	}
+
+	defer func(r io.Reader) { randReader = r }(randReader)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			randReader = &failingReader{n: tt.reads}
+			_, err := PollardRho(context.Background(), ec, ec.Generator(), Q, ec.Params().N, WithWorkers(4))
+			if !errors.Is(err, errRead) {
+				t.Errorf("%v : PollardRho() err = %v, want %v", tt.name, err, errRead)
+			}
+		})
+	}
+}
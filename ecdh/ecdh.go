@@ -0,0 +1,45 @@
+package ecdh
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/matumoto1234/secp256k1/ecdsa"
+)
+
+var (
+	ErrInvalidPrivateKey = errors.New("ecdh: invalid private key")
+	ErrInvalidPublicKey  = errors.New("ecdh: invalid public key")
+)
+
+// SharedSecret() : 楕円曲線Diffie-Hellman (SEC1 3.3.1)
+// 自分の秘密鍵dと相手の公開鍵Qから、共有点 d*Q のx座標を固定長のbig-endianで返す
+// 鍵ペアは ECDSA と同じものを使う
+func SharedSecret(priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey) ([]byte, error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil || priv.D.IsZero() {
+		return nil, ErrInvalidPrivateKey
+	}
+	if pub == nil || pub.Curve == nil || pub.Q == nil || pub.Q.IsZero {
+		return nil, ErrInvalidPublicKey
+	}
+
+	ec := priv.Curve
+	params := ec.Params()
+	pubParams := pub.Curve.Params()
+	if params.P.Cmp(pubParams.P) != 0 || params.N.Cmp(pubParams.N) != 0 {
+		return nil, errors.New("ecdh: keys are on different curves")
+	}
+
+	// dは秘密なので、定数時間のスカラー倍算を使う
+	// 曲線上にない点を渡されると、位数の小さい別の曲線の上で計算させられてしまう (invalid curve attack) のでエラーにする
+	S, err := ec.ScalarMultConstTimePChecked(pub.Q, priv.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	if S.IsZero {
+		return nil, errors.New("ecdh: shared point is the point at infinity")
+	}
+
+	size := (params.P.BitLen() + 7) / 8
+	return S.X.Value.FillBytes(make([]byte, size)), nil
+}
@@ -0,0 +1,115 @@
+package ecdh
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func Test_SharedSecret(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *models.EllipticCurve
+	}{
+		{name: "secp256k1", ec: curves.Secp256k1()},
+		{name: "P-256", ec: curves.P256()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alice, err := ecdsa.GenerateKey(tt.ec, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bob, err := ecdsa.GenerateKey(tt.ec, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s1, err := SharedSecret(alice, &bob.PublicKey)
+			if err != nil {
+				t.Fatalf("%v : SharedSecret(alice, bob) error = %v", tt.name, err)
+			}
+			s2, err := SharedSecret(bob, &alice.PublicKey)
+			if err != nil {
+				t.Fatalf("%v : SharedSecret(bob, alice) error = %v", tt.name, err)
+			}
+			if !bytes.Equal(s1, s2) {
+				t.Errorf("%v : SharedSecret(alice, bob) = %x, SharedSecret(bob, alice) = %x", tt.name, s1, s2)
+			}
+			if len(s1) != 32 {
+				t.Errorf("%v : len(SharedSecret()) = %v, want 32", tt.name, len(s1))
+			}
+
+			// d * Q のx座標と一致する
+			want := tt.ec.ScalarMultP(bob.Q, alice.Bytes()).X.Value.FillBytes(make([]byte, 32))
+			if !bytes.Equal(s1, want) {
+				t.Errorf("%v : SharedSecret() = %x, want %x", tt.name, s1, want)
+			}
+		})
+	}
+}
+
+func Test_SharedSecret_P256Interop(t *testing.T) {
+	ec := curves.P256()
+	curve := elliptic.P256()
+
+	alice, err := ecdsa.GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := ecdsa.GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := SharedSecret(alice, &bob.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, _ := curve.ScalarMult(bob.Q.X.Value, bob.Q.Y.Value, alice.Bytes())
+	if want := x.FillBytes(make([]byte, 32)); !bytes.Equal(got, want) {
+		t.Errorf("SharedSecret() = %x, want %x (crypto/elliptic)", got, want)
+	}
+}
+
+func Test_SharedSecret_InvalidPublicKey(t *testing.T) {
+	ec := curves.Secp256k1()
+	prime := ec.Params().P
+
+	priv, err := ecdsa.NewPrivateKey(ec, big.NewInt(12345))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		pub  *ecdsa.PublicKey
+	}{
+		{name: "nil", pub: nil},
+		{name: "infinity", pub: &ecdsa.PublicKey{Curve: ec, Q: models.NewEllipticCurvePoint(nil, nil, true)}},
+		{
+			name: "not on curve",
+			pub: &ecdsa.PublicKey{Curve: ec, Q: models.NewEllipticCurvePoint(
+				models.NewFiniteField(big.NewInt(1), prime),
+				models.NewFiniteField(big.NewInt(1), prime),
+				false,
+			)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SharedSecret(priv, tt.pub); !errors.Is(err, ErrInvalidPublicKey) {
+				t.Errorf("%v : SharedSecret() error = %v, want ErrInvalidPublicKey", tt.name, err)
+			}
+		})
+	}
+}
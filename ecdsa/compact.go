@@ -0,0 +1,66 @@
+package ecdsa
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// RecoverableSignature : recovery id付きの署名
+// RecoveryID := 署名時の一時的な公開鍵Qについて
+//
+//	bit 0 : Qのy座標が奇数なら1
+//	bit 1 : Qのx座標がN以上(r = x - N)なら1
+type RecoverableSignature struct {
+	Signature
+	RecoveryID byte
+}
+
+// MarshalCompact() : 署名を r || s の固定長形式にエンコードする
+// secp256k1 の場合は64バイトになる
+func (sig *Signature) MarshalCompact() []byte {
+	size := byteLen(sig.R.Prime)
+	b := make([]byte, 2*size)
+	sig.R.Value.FillBytes(b[:size])
+	sig.S.Value.FillBytes(b[size:])
+	return b
+}
+
+// ParseCompactSignature() : r || s の固定長形式の署名をパースする
+func ParseCompactSignature(ec *models.EllipticCurve, b []byte) (*Signature, error) {
+	size := byteLen(ec.Params().N)
+	if len(b) != 2*size {
+		return nil, fmt.Errorf("%w: compact signature must be %d bytes", ErrInvalidSignature, 2*size)
+	}
+
+	r := new(big.Int).SetBytes(b[:size])
+	s := new(big.Int).SetBytes(b[size:])
+	return newSignature(ec, r, s)
+}
+
+// MarshalCompact() : 署名を r || s || v の固定長形式にエンコードする
+// secp256k1 の場合は65バイトになる
+func (sig *RecoverableSignature) MarshalCompact() []byte {
+	return append(sig.Signature.MarshalCompact(), sig.RecoveryID)
+}
+
+// ParseRecoverableSignature() : r || s || v の固定長形式の署名をパースする
+// vは0から3までのrecovery idでなければならない
+func ParseRecoverableSignature(ec *models.EllipticCurve, b []byte) (*RecoverableSignature, error) {
+	size := byteLen(ec.Params().N)
+	if len(b) != 2*size+1 {
+		return nil, fmt.Errorf("%w: recoverable signature must be %d bytes", ErrInvalidSignature, 2*size+1)
+	}
+
+	v := b[2*size]
+	if v > 3 {
+		return nil, fmt.Errorf("%w: invalid recovery id %d", ErrInvalidSignature, v)
+	}
+
+	sig, err := ParseCompactSignature(ec, b[:2*size])
+	if err != nil {
+		return nil, err
+	}
+	return &RecoverableSignature{Signature: *sig, RecoveryID: v}, nil
+}
@@ -0,0 +1,151 @@
+package ecdsa
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func Test_Signature_MarshalCompact(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := NewPrivateKey(ec, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256Hash("Satoshi Nakamoto")
+
+	sig, err := Sign(priv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := sig.MarshalCompact()
+	if len(b) != 64 {
+		t.Fatalf("len(MarshalCompact()) = %v, want 64", len(b))
+	}
+	if got, want := hex.EncodeToString(b[:32]), "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8"; got != want {
+		t.Errorf("MarshalCompact()[:32] = %v, want %v", got, want)
+	}
+
+	parsed, err := ParseCompactSignature(ec, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.R.Equals(sig.R) || !parsed.S.Equals(sig.S) {
+		t.Errorf("ParseCompactSignature() = (%v, %v), want (%v, %v)", parsed.R, parsed.S, sig.R, sig.S)
+	}
+}
+
+func Test_ParseCompactSignature_Invalid(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141"
+	one := strings.Repeat("00", 31) + "01"
+	zero := strings.Repeat("00", 32)
+
+	tests := []struct {
+		name    string
+		hex     string
+		wantErr bool
+	}{
+		{name: "valid", hex: one + one, wantErr: false},
+		{name: "too short", hex: one + one[2:], wantErr: true},
+		{name: "too long", hex: one + one + "00", wantErr: true},
+		{name: "r = 0", hex: zero + one, wantErr: true},
+		{name: "s = 0", hex: one + zero, wantErr: true},
+		{name: "r = N", hex: n + one, wantErr: true},
+		{name: "s = N", hex: one + n, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCompactSignature(ec, mustDecodeHex(tt.hex))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%v : ParseCompactSignature() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_SignRecoverable(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := NewPrivateKey(ec, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256Hash("Satoshi Nakamoto")
+
+	sig, err := SignRecoverable(priv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(&priv.PublicKey, hash, &sig.Signature) {
+		t.Errorf("Verify() = false, want true")
+	}
+
+	// R = (z/s)*G + (r/s)*Q のy座標の偶奇が recovery id になる
+	n := ec.Params().N
+	u1 := new(models.FiniteField).Div(hashToFiniteField(hash, n), sig.S)
+	u2 := new(models.FiniteField).Div(sig.R, sig.S)
+	R := ec.AddP(ec.ScalarBaseMultP(u1.Value.Bytes()), ec.ScalarMultP(priv.Q, u2.Value.Bytes()))
+	if want := byte(R.Y.Value.Bit(0)); sig.RecoveryID != want {
+		t.Errorf("SignRecoverable().RecoveryID = %v, want %v", sig.RecoveryID, want)
+	}
+
+	b := sig.MarshalCompact()
+	if len(b) != 65 {
+		t.Fatalf("len(MarshalCompact()) = %v, want 65", len(b))
+	}
+	parsed, err := ParseRecoverableSignature(ec, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.R.Equals(sig.R) || !parsed.S.Equals(sig.S) || parsed.RecoveryID != sig.RecoveryID {
+		t.Errorf("ParseRecoverableSignature() = %v, want %v", parsed, sig)
+	}
+}
+
+func Test_ParseRecoverableSignature_Invalid(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignRecoverable(priv, sha256Hash("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := sig.MarshalCompact()
+
+	withV := func(v byte) []byte {
+		b := append([]byte(nil), valid...)
+		b[64] = v
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		b       []byte
+		wantErr bool
+	}{
+		{name: "v = 0", b: withV(0), wantErr: false},
+		{name: "v = 3", b: withV(3), wantErr: false},
+		{name: "v = 4", b: withV(4), wantErr: true},
+		{name: "v = 27", b: withV(27), wantErr: true},
+		{name: "64 bytes", b: valid[:64], wantErr: true},
+		{name: "66 bytes", b: append(withV(0), 0), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRecoverableSignature(ec, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%v : ParseRecoverableSignature() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
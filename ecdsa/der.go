@@ -0,0 +1,197 @@
+package ecdsa
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+var ErrInvalidDER = errors.New("ecdsa: invalid DER signature")
+
+const (
+	asn1Sequence = 0x30
+	asn1Integer  = 0x02
+)
+
+// MarshalDER() : 署名を DER (ASN.1) 形式にエンコードする
+//
+//	0x30 [total-length] 0x02 [R-length] [R] 0x02 [S-length] [S]
+func (sig *Signature) MarshalDER() []byte {
+	r := derInteger(sig.R.Value)
+	s := derInteger(sig.S.Value)
+
+	b := make([]byte, 0, 6+len(r)+len(s))
+	b = append(b, asn1Sequence, byte(4+len(r)+len(s)))
+	b = append(b, asn1Integer, byte(len(r)))
+	b = append(b, r...)
+	b = append(b, asn1Integer, byte(len(s)))
+	b = append(b, s...)
+	return b
+}
+
+// derInteger() : 非負整数xを最小長のASN.1 INTEGERの内容にエンコードする
+// 最上位ビットが立っている場合は負数と解釈されないように0x00を先頭に付ける
+func derInteger(x *big.Int) []byte {
+	b := x.Bytes()
+	if len(b) == 0 || b[0]&0x80 != 0 {
+		b = append([]byte{0x00}, b...)
+	}
+	return b
+}
+
+// ParseDERSignature() : DER形式の署名を BIP66 の規則に従って厳密にパースする
+// 負の整数、余分な0x00によるパディング、長さの不一致などはすべてエラーになる
+func ParseDERSignature(ec *models.EllipticCurve, b []byte) (*Signature, error) {
+	// 0x30 [total-length] 0x02 [R-length] [R] 0x02 [S-length] [S]
+	// 最小: R, Sがそれぞれ1バイト
+	// 最大: R, Sがそれぞれ位数のバイト長+1バイト(0x00によるパディング)
+	maxLen := 6 + 2*(byteLen(ec.Params().N)+1)
+	if len(b) < 8 {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidDER)
+	}
+	if len(b) > maxLen {
+		return nil, fmt.Errorf("%w: too long", ErrInvalidDER)
+	}
+	if b[0] != asn1Sequence {
+		return nil, fmt.Errorf("%w: not a sequence", ErrInvalidDER)
+	}
+	if int(b[1]) != len(b)-2 {
+		return nil, fmt.Errorf("%w: sequence length mismatch", ErrInvalidDER)
+	}
+
+	rLen := int(b[3])
+	if 5+rLen >= len(b) {
+		return nil, fmt.Errorf("%w: R length out of bounds", ErrInvalidDER)
+	}
+	sLen := int(b[5+rLen])
+	if rLen+sLen+6 != len(b) {
+		return nil, fmt.Errorf("%w: R and S lengths do not match the sequence", ErrInvalidDER)
+	}
+
+	r, err := parseStrictDERInteger(b[2:4+rLen], "R")
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseStrictDERInteger(b[4+rLen:], "S")
+	if err != nil {
+		return nil, err
+	}
+
+	return newSignature(ec, r, s)
+}
+
+// parseStrictDERInteger() : 0x02 [length] [value] をパースする
+func parseStrictDERInteger(b []byte, name string) (*big.Int, error) {
+	if b[0] != asn1Integer {
+		return nil, fmt.Errorf("%w: %v is not an integer", ErrInvalidDER, name)
+	}
+
+	v := b[2:]
+	if len(v) == 0 {
+		return nil, fmt.Errorf("%w: zero-length %v", ErrInvalidDER, name)
+	}
+	if v[0]&0x80 != 0 {
+		return nil, fmt.Errorf("%w: negative %v", ErrInvalidDER, name)
+	}
+	if len(v) > 1 && v[0] == 0x00 && v[1]&0x80 == 0 {
+		return nil, fmt.Errorf("%w: excess padding in %v", ErrInvalidDER, name)
+	}
+	return new(big.Int).SetBytes(v), nil
+}
+
+// ParseDERSignatureLax() : BIP66 以前の不正確なエンコードも受け付けるDERパーサ
+// libsecp256k1 の ecdsa_signature_parse_der_lax と同様に、
+// 長形式の長さ、余分なパディング、負数(符号なしとして扱う)、末尾の余分なバイトを許容する
+func ParseDERSignatureLax(ec *models.EllipticCurve, b []byte) (*Signature, error) {
+	p := laxDERParser{b: b}
+
+	if !p.expect(asn1Sequence) {
+		return nil, fmt.Errorf("%w: not a sequence", ErrInvalidDER)
+	}
+	// シーケンスの長さは信用しない
+	if _, ok := p.length(); !ok {
+		return nil, fmt.Errorf("%w: invalid sequence length", ErrInvalidDER)
+	}
+
+	r, ok := p.integer()
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid R", ErrInvalidDER)
+	}
+	s, ok := p.integer()
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid S", ErrInvalidDER)
+	}
+
+	return newSignature(ec, r, s)
+}
+
+type laxDERParser struct {
+	b   []byte
+	pos int
+}
+
+func (p *laxDERParser) expect(tag byte) bool {
+	if p.pos >= len(p.b) || p.b[p.pos] != tag {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// length() : 短形式と長形式のどちらの長さも読む
+func (p *laxDERParser) length() (int, bool) {
+	if p.pos >= len(p.b) {
+		return 0, false
+	}
+	l := int(p.b[p.pos])
+	p.pos++
+	if l&0x80 == 0 {
+		return l, true
+	}
+
+	n := l & 0x7f
+	// 長さの先頭の0x00は読み飛ばす
+	for n > 0 && p.pos < len(p.b) && p.b[p.pos] == 0x00 {
+		p.pos++
+		n--
+	}
+	if n > 4 || p.pos+n > len(p.b) {
+		return 0, false
+	}
+	l = 0
+	for ; n > 0; n-- {
+		l = l<<8 | int(p.b[p.pos])
+		p.pos++
+	}
+	return l, true
+}
+
+func (p *laxDERParser) integer() (*big.Int, bool) {
+	if !p.expect(asn1Integer) {
+		return nil, false
+	}
+	l, ok := p.length()
+	if !ok || p.pos+l > len(p.b) {
+		return nil, false
+	}
+	v := p.b[p.pos : p.pos+l]
+	p.pos += l
+	return new(big.Int).SetBytes(v), true
+}
+
+// newSignature() : r, s が [1, N) の範囲にあることを確認して署名を作る
+func newSignature(ec *models.EllipticCurve, r, s *big.Int) (*Signature, error) {
+	n := ec.Params().N
+	if r.Sign() <= 0 || r.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("%w: R out of range", ErrInvalidSignature)
+	}
+	if s.Sign() <= 0 || s.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("%w: S out of range", ErrInvalidSignature)
+	}
+	return &Signature{
+		R: models.NewFiniteField(r, n),
+		S: models.NewFiniteField(s, n),
+	}, nil
+}
@@ -0,0 +1,187 @@
+package ecdsa
+
+import (
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func Test_Signature_MarshalDER(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+
+	tests := []struct {
+		name string
+		r    *big.Int
+		s    *big.Int
+		want string
+	}{
+		{
+			name: "small values",
+			r:    big.NewInt(1),
+			s:    big.NewInt(2),
+			want: "3006020101020102",
+		},
+		{
+			name: "high bit set needs padding",
+			r:    big.NewInt(0x80),
+			s:    big.NewInt(0x7f),
+			want: "30070202008002017f",
+		},
+		{
+			name: "N - 1",
+			r:    new(big.Int).Sub(n, big.NewInt(1)),
+			s:    big.NewInt(1),
+			want: "3026022100fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140020101",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := &Signature{R: models.NewFiniteField(tt.r, n), S: models.NewFiniteField(tt.s, n)}
+			if got := hex.EncodeToString(sig.MarshalDER()); got != tt.want {
+				t.Errorf("%v : MarshalDER() = %v, want %v", tt.name, got, tt.want)
+			}
+
+			parsed, err := ParseDERSignature(ec, mustDecodeHex(tt.want))
+			if err != nil {
+				t.Fatalf("%v : ParseDERSignature() error = %v", tt.name, err)
+			}
+			if !parsed.R.Equals(sig.R) || !parsed.S.Equals(sig.S) {
+				t.Errorf("%v : ParseDERSignature() = (%v, %v), want (%v, %v)", tt.name, parsed.R, parsed.S, sig.R, sig.S)
+			}
+		})
+	}
+}
+
+func Test_Signature_MarshalDER_RoundTrip(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 16; i++ {
+		hash := sha256Hash(string(rune('a' + i)))
+		sig, err := Sign(priv, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		der := sig.MarshalDER()
+		parsed, err := ParseDERSignature(ec, der)
+		if err != nil {
+			t.Fatalf("ParseDERSignature(%x) error = %v", der, err)
+		}
+		if !Verify(&priv.PublicKey, hash, parsed) {
+			t.Errorf("Verify() of the parsed signature %x = false, want true", der)
+		}
+	}
+}
+
+// Test_ParseDERSignature_P256 : crypto/ecdsa の ASN.1 形式と相互に変換できることを確認する
+func Test_ParseDERSignature_P256(t *testing.T) {
+	ec := curves.P256()
+	hash := sha256Hash("DER interop")
+
+	goPriv, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := NewPrivateKey(ec, goPriv.D)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goDER, err := goecdsa.SignASN1(rand.Reader, goPriv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ParseDERSignature(ec, goDER)
+	if err != nil {
+		t.Fatalf("ParseDERSignature() error = %v", err)
+	}
+	if !Verify(&priv.PublicKey, hash, sig) {
+		t.Errorf("Verify() rejected a DER signature made by crypto/ecdsa")
+	}
+
+	sig, err = Sign(priv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !goecdsa.VerifyASN1(&goPriv.PublicKey, hash, sig.MarshalDER()) {
+		t.Errorf("crypto/ecdsa rejected a DER signature made by MarshalDER()")
+	}
+}
+
+func Test_ParseDERSignature_Malformed(t *testing.T) {
+	ec := curves.Secp256k1()
+
+	tests := []struct {
+		name    string
+		der     string
+		wantLax bool
+	}{
+		{name: "empty", der: "", wantLax: false},
+		{name: "too short", der: "300502010102", wantLax: false},
+		{name: "not a sequence", der: "3106020101020102", wantLax: false},
+		{name: "sequence length too long", der: "3007020101020102", wantLax: true},
+		{name: "sequence length too short", der: "3005020101020102", wantLax: true},
+		{name: "trailing garbage", der: "300602010102010200", wantLax: true},
+		{name: "R is not an integer", der: "3006030101020102", wantLax: false},
+		{name: "S is not an integer", der: "3006020101030102", wantLax: false},
+		{name: "zero-length R", der: "30050200020102", wantLax: false},
+		{name: "zero-length S", der: "30050201010200", wantLax: false},
+		{name: "R length out of bounds", der: "3006020901020102", wantLax: false},
+		{name: "negative R", der: "3006020181020102", wantLax: true},
+		{name: "negative S", der: "3006020101020182", wantLax: true},
+		{name: "excess padding in R", der: "300702020001020102", wantLax: true},
+		{name: "excess padding in S", der: "300702010102020002", wantLax: true},
+		{name: "long-form length", der: "308106020101020102", wantLax: true},
+		{name: "R = 0", der: "3006020100020102", wantLax: false},
+		{name: "S = 0", der: "3006020101020100", wantLax: false},
+		{
+			name:    "R = N",
+			der:     "3026022100fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141020101",
+			wantLax: false,
+		},
+		{
+			name:    "too long",
+			der:     "304902220000fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140022300000000fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+			wantLax: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := mustDecodeHex(tt.der)
+
+			_, err := ParseDERSignature(ec, b)
+			if err == nil {
+				t.Errorf("%v : ParseDERSignature() error = nil, want error", tt.name)
+			} else if !errors.Is(err, ErrInvalidDER) && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("%v : ParseDERSignature() error = %v, want ErrInvalidDER or ErrInvalidSignature", tt.name, err)
+			}
+
+			_, err = ParseDERSignatureLax(ec, b)
+			if (err == nil) != tt.wantLax {
+				t.Errorf("%v : ParseDERSignatureLax() error = %v, want accepted = %v", tt.name, err, tt.wantLax)
+			}
+		})
+	}
+}
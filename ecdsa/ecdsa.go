@@ -0,0 +1,399 @@
+package ecdsa
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+var (
+	ErrInvalidPrivateKey = errors.New("ecdsa: invalid private key")
+	ErrInvalidPublicKey  = errors.New("ecdsa: invalid public key")
+	ErrInvalidSignature  = errors.New("ecdsa: invalid signature")
+	ErrInvalidNonce      = errors.New("ecdsa: invalid nonce")
+)
+
+// PublicKey : 楕円曲線Curve上の公開鍵 Q = d*G
+type PublicKey struct {
+	Curve *models.EllipticCurve
+	Q     *models.EllipticCurvePoint
+}
+
+// PrivateKey : 秘密鍵d(位数Nを法とする有限体の元)と対応する公開鍵
+type PrivateKey struct {
+	PublicKey
+	D *models.FiniteField
+}
+
+// Signature : ECDSA署名(r, s)
+// r, s はどちらも位数Nを法とする有限体の元
+type Signature struct {
+	R *models.FiniteField
+	S *models.FiniteField
+}
+
+// NewPrivateKey() : 秘密鍵dから鍵ペアを生成する
+// dは [1, N) の範囲でなければならない
+func NewPrivateKey(ec *models.EllipticCurve, d *big.Int) (*PrivateKey, error) {
+	n := ec.Params().N
+	if d.Sign() <= 0 || d.Cmp(n) >= 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	priv := &PrivateKey{
+		D: models.NewFiniteField(d, n),
+	}
+	priv.Curve = ec
+	priv.Q = ec.ScalarBaseMultConstTimeP(priv.Bytes())
+	return priv, nil
+}
+
+// ParsePublicKey() : SEC1 の圧縮形式・非圧縮形式でエンコードされた公開鍵をパースする
+func ParsePublicKey(ec *models.EllipticCurve, b []byte) (*PublicKey, error) {
+	Q, err := ec.ParsePoint(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	return &PublicKey{Curve: ec, Q: Q}, nil
+}
+
+// isValid() : 公開鍵が無限遠点でなく、曲線上の点であるかを判定する
+func (pub *PublicKey) isValid() bool {
+	if pub == nil || pub.Curve == nil || pub.Q == nil || pub.Q.IsZero {
+		return false
+	}
+	if pub.Q.X == nil || pub.Q.Y == nil {
+		return false
+	}
+
+	p := pub.Curve.Params().P
+	if pub.Q.X.Prime.Cmp(p) != 0 || pub.Q.Y.Prime.Cmp(p) != 0 {
+		return false
+	}
+	return pub.Curve.IsOnCurveP(pub.Q)
+}
+
+// GenerateKey() : 乱数randから [1, N) の一様乱数を秘密鍵として選び、鍵ペアを生成する
+func GenerateKey(ec *models.EllipticCurve, rand io.Reader) (*PrivateKey, error) {
+	d, err := newRandomFiniteField(rand, ec.Params().N)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(ec, d.Value)
+}
+
+// NewPrivateKeyFromBytes() : big-endianの固定長バイト列から秘密鍵を復元する
+// バイト列の長さは位数Nのバイト長と等しくなければならない
+func NewPrivateKeyFromBytes(ec *models.EllipticCurve, b []byte) (*PrivateKey, error) {
+	if len(b) != byteLen(ec.Params().N) {
+		return nil, ErrInvalidPrivateKey
+	}
+	return NewPrivateKey(ec, new(big.Int).SetBytes(b))
+}
+
+// NewPrivateKeyFromHex() : 16進数文字列から秘密鍵を復元する
+func NewPrivateKeyFromHex(ec *models.EllipticCurve, s string) (*PrivateKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	return NewPrivateKeyFromBytes(ec, b)
+}
+
+// Bytes() : 秘密鍵をbig-endianの固定長バイト列に変換する
+func (priv *PrivateKey) Bytes() []byte {
+	b := make([]byte, byteLen(priv.Curve.Params().N))
+	return priv.D.Value.FillBytes(b)
+}
+
+// byteLen() : nを表すのに必要なバイト数
+func byteLen(n *big.Int) int {
+	return (n.BitLen() + 7) / 8
+}
+
+// HashToInt() : メッセージのハッシュを署名で使う整数zに変換する
+// ハッシュがNのビット長より長い場合は上位ビットのみを使う(SEC1 4.1.3)
+// 結果はNより大きいことがあるので、Nで割った余りにして使うこと
+func HashToInt(hash []byte, n *big.Int) *big.Int {
+	z := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - n.BitLen(); excess > 0 {
+		z.Rsh(z, uint(excess))
+	}
+	return z
+}
+
+// hashToFiniteField() : メッセージのハッシュを位数Nを法とする有限体の元に変換する
+func hashToFiniteField(hash []byte, n *big.Int) *models.FiniteField {
+	return models.NewFiniteField(HashToInt(hash, n), n)
+}
+
+// generate random number in [1, prime)
+func newRandomFiniteField(random io.Reader, prime *big.Int) (*models.FiniteField, error) {
+	for {
+		n, err := rand.Int(random, prime)
+		if err != nil {
+			return nil, err
+		}
+		if n.Sign() != 0 {
+			return models.NewFiniteField(n, prime), nil
+		}
+	}
+}
+
+// SignOption : Sign() の挙動を変更するオプション
+type SignOption func(*signConfig)
+
+type signConfig struct {
+	rand         io.Reader
+	extraEntropy []byte
+	nonce        *big.Int
+}
+
+// WithRandomNonce() : nonce kをRFC 6979ではなく乱数randから選ぶ
+// randが弱い乱数だと秘密鍵が漏洩するので注意すること
+func WithRandomNonce(rand io.Reader) SignOption {
+	return func(c *signConfig) {
+		c.rand = rand
+	}
+}
+
+// WithExtraEntropy() : RFC 6979 のnonce導出に追加のエントロピーを混ぜる (RFC 6979 3.6)
+// 署名は決定的でなくなるが、乱数が弱くてもRFC 6979と同等の安全性は保たれる
+func WithExtraEntropy(entropy []byte) SignOption {
+	return func(c *signConfig) {
+		c.extraEntropy = entropy
+	}
+}
+
+// WithNonce() : nonce kを直接指定する
+// 偏ったnonceや使い回したnonceの署名を作って、監査の手法を確かめるためのもので、普通の署名に使ってはいけない
+// kが [1, N) の範囲にない場合や、r == 0 または s == 0 となる場合は ErrInvalidNonce
+func WithNonce(k *big.Int) SignOption {
+	return func(c *signConfig) {
+		c.nonce = k
+	}
+}
+
+// Sign() : ハッシュhashに対する署名(r, s)を生成する
+// デフォルトではnonce kはRFC 6979によって秘密鍵とハッシュから決定的に導出される
+// G := 生成点
+// k := 一時的な秘密鍵
+// Q := 一時的な公開鍵(k*G)
+// d := 秘密鍵
+// r := 公開鍵Qのx座標
+// z := メッセージのハッシュ
+// s := (z + r*d) / k を計算した値
+// kG と s の計算が定数時間になるのは secp256k1 の場合だけで、それ以外の曲線では big.Int を使うので定数時間ではない
+func Sign(priv *PrivateKey, hash []byte, opts ...SignOption) (*Signature, error) {
+	sig, _, err := sign(priv, hash, opts)
+	return sig, err
+}
+
+// SignRecoverable() : Sign() と同様に署名し、公開鍵の復元に必要なrecovery idも記録する
+func SignRecoverable(priv *PrivateKey, hash []byte, opts ...SignOption) (*RecoverableSignature, error) {
+	sig, recid, err := sign(priv, hash, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &RecoverableSignature{Signature: *sig, RecoveryID: recid}, nil
+}
+
+func sign(priv *PrivateKey, hash []byte, opts []SignOption) (*Signature, byte, error) {
+	if priv == nil || priv.D == nil || !priv.PublicKey.isValid() {
+		return nil, 0, ErrInvalidPrivateKey
+	}
+
+	var config signConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	n := priv.Curve.Params().N
+
+	if config.nonce != nil {
+		if config.nonce.Sign() <= 0 || config.nonce.Cmp(n) >= 0 {
+			return nil, 0, fmt.Errorf("%w: k is not in [1, N)", ErrInvalidNonce)
+		}
+		sig, recid, ok := signWithNonce(priv, hash, models.NewFiniteField(config.nonce, n))
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: r or s is 0", ErrInvalidNonce)
+		}
+		return sig, recid, nil
+	}
+
+	if config.rand != nil {
+		for {
+			// temporary private key
+			k, err := newRandomFiniteField(config.rand, n)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			if sig, recid, ok := signWithNonce(priv, hash, k); ok {
+				return sig, recid, nil
+			}
+		}
+	}
+
+	nonces := newRFC6979(n, priv.D.Value, hash, config.extraEntropy)
+	for {
+		k := models.NewFiniteField(nonces.next(), n)
+		if sig, recid, ok := signWithNonce(priv, hash, k); ok {
+			return sig, recid, nil
+		}
+	}
+}
+
+// signWithNonce() : 一時的な秘密鍵kを使って署名する
+// recid := Qのy座標の偶奇(bit 0)と、Qのx座標がN以上かどうか(bit 1)
+// r == 0 または s == 0 となった場合は ok == false を返すので、kを選び直すこと
+func signWithNonce(priv *PrivateKey, hash []byte, k *models.FiniteField) (sig *Signature, recid byte, ok bool) {
+	n := priv.Curve.Params().N
+
+	// temporary public key
+	// kが漏れると秘密鍵も求まってしまうので、定数時間のスカラー倍算を使う
+	Q := priv.Curve.ScalarBaseMultConstTimeP(k.Value.FillBytes(make([]byte, byteLen(n))))
+	if Q.IsZero {
+		return nil, 0, false
+	}
+
+	r := models.NewFiniteField(Q.X.Value, n)
+	if r.Value.Sign() == 0 {
+		return nil, 0, false
+	}
+
+	// s = (r*d + z) / k も、秘密鍵dとkを使うので定数時間で計算する
+	z := hashToFiniteField(hash, n)
+	size := byteLen(n)
+	s := models.NewFiniteField(priv.Curve.ScalarMulAddDivConstTime(
+		r.Value.FillBytes(make([]byte, size)),
+		priv.D.Value.FillBytes(make([]byte, size)),
+		z.Value.FillBytes(make([]byte, size)),
+		k.Value.FillBytes(make([]byte, size)),
+	), n)
+	if s.Value.Sign() == 0 {
+		return nil, 0, false
+	}
+
+	recid = byte(Q.Y.Value.Bit(0))
+	if Q.X.Value.Cmp(n) >= 0 {
+		recid |= 2
+	}
+
+	// s と N - s はどちらも正しい署名になるので、常に小さい方を選ぶ (BIP62, BIP146)
+	// s を -s にするのは Q を -Q にするのと同じなので、y座標の偶奇も反転する
+	sig = &Signature{R: r, S: s}
+	if sig.Normalize() {
+		recid ^= 1
+	}
+
+	return sig, recid, true
+}
+
+// IsLowS() : sが位数の半分 N/2 以下かどうかを判定する
+func (sig *Signature) IsLowS() bool {
+	half := new(big.Int).Rsh(sig.S.Prime, 1)
+	return sig.S.Value.Cmp(half) <= 0
+}
+
+// Normalize() : sがN/2より大きければ N - s に置き換える
+// 置き換えた場合は true を返す
+func (sig *Signature) Normalize() bool {
+	if sig.IsLowS() {
+		return false
+	}
+	sig.S = new(models.FiniteField).Neg(sig.S)
+	return true
+}
+
+// InRange() : r, s がどちらも [1, N) の範囲にある、Nを法とする有限体の元かどうかを判定する
+// Verify() と同じ判定で、署名のダンプなどから作った署名を確かめるのに使う
+func (sig *Signature) InRange(n *big.Int) bool {
+	return inRange(sig.R, n) && inRange(sig.S, n)
+}
+
+// Normalize() : Signature.Normalize() と同様にsを正規化し、recovery idの偶奇も合わせて反転する
+func (sig *RecoverableSignature) Normalize() bool {
+	if !sig.Signature.Normalize() {
+		return false
+	}
+	sig.RecoveryID ^= 1
+	return true
+}
+
+// inRange() : xが [1, n) の範囲にある、nを法とする有限体の元かどうかを判定する
+func inRange(x *models.FiniteField, n *big.Int) bool {
+	if x == nil || x.Value == nil || x.Prime == nil || x.Prime.Cmp(n) != 0 {
+		return false
+	}
+	return x.Value.Sign() > 0 && x.Value.Cmp(n) < 0
+}
+
+// VerifyOption : Verify() の挙動を変更するオプション
+type VerifyOption func(*verifyConfig)
+
+type verifyConfig struct {
+	requireLowS bool
+}
+
+// RequireLowS() : s > N/2 の署名を不正なものとして扱う (BIP62, BIP146)
+func RequireLowS() VerifyOption {
+	return func(c *verifyConfig) {
+		c.requireLowS = true
+	}
+}
+
+// Verify() : 署名(r, s)の署名検証
+// G := 生成点
+// z := メッセージのハッシュ
+// Q := 公開鍵
+// R := (z*G + r*Q) / s
+//
+//	Rのx座標 == r -> OK
+//	Rのx座標 != r -> NG(R == 無限遠点の場合もNG)
+func Verify(pub *PublicKey, hash []byte, sig *Signature, opts ...VerifyOption) bool {
+	if !pub.isValid() || sig == nil {
+		return false
+	}
+
+	var config verifyConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	ec := pub.Curve
+	n := ec.Params().N
+	if !inRange(sig.R, n) || !inRange(sig.S, n) {
+		return false
+	}
+	if config.requireLowS && !sig.IsLowS() {
+		return false
+	}
+
+	// 計算量改善のための式変形
+	// R = (z*G + r*Q)/s
+	// w := 1/sとして、
+	// R = (z*G + r*Q) * w
+	// R = ((z*w)*G + (r*w)*Q)を求める
+
+	// w = 1 / s
+	one := models.NewFiniteField(big.NewInt(1), n)
+	w := new(models.FiniteField).Div(one, sig.S)
+
+	z := hashToFiniteField(hash, n)
+	zw := new(models.FiniteField).Mul(z, w)
+	rw := new(models.FiniteField).Mul(sig.R, w)
+
+	// 2つのスカラー倍算は2倍算を共有してまとめて計算する
+	R := ec.DoubleScalarMultP(zw.Value.Bytes(), ec.Generator(), rw.Value.Bytes(), pub.Q)
+	if R.IsZero {
+		return false
+	}
+
+	return sig.R.Equals(models.NewFiniteField(R.X.Value, n))
+}
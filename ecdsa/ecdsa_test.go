@@ -0,0 +1,474 @@
+package ecdsa
+
+import (
+	"bytes"
+	goecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func mustHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex: " + s)
+	}
+	return n
+}
+
+func sha256Hash(msg string) []byte {
+	h := sha256.Sum256([]byte(msg))
+	return h[:]
+}
+
+func Test_NewPrivateKey(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+
+	tests := []struct {
+		name    string
+		d       *big.Int
+		wantErr bool
+	}{
+		{name: "d = 0", d: big.NewInt(0), wantErr: true},
+		{name: "d = -1", d: big.NewInt(-1), wantErr: true},
+		{name: "d = N", d: n, wantErr: true},
+		{name: "d = 1", d: big.NewInt(1), wantErr: false},
+		{name: "d = N - 1", d: new(big.Int).Sub(n, big.NewInt(1)), wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPrivateKey(ec, tt.d)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%v : NewPrivateKey() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_GenerateKey(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+
+	tests := []struct {
+		name    string
+		rand    io.Reader
+		wantErr bool
+	}{
+		{name: "crypto/rand", rand: rand.Reader, wantErr: false},
+		{name: "fixed bytes", rand: bytes.NewReader(bytes.Repeat([]byte{0x42}, 64)), wantErr: false},
+		{name: "empty reader", rand: bytes.NewReader(nil), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priv, err := GenerateKey(ec, tt.rand)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%v : GenerateKey() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if d := priv.D.Value; d.Sign() <= 0 || d.Cmp(n) >= 0 {
+				t.Errorf("%v : GenerateKey() d = %v, out of [1, N)", tt.name, d)
+			}
+			if !priv.PublicKey.isValid() {
+				t.Errorf("%v : GenerateKey() public key %v is invalid", tt.name, priv.Q)
+			}
+		})
+	}
+}
+
+func Test_NewPrivateKeyFromHex(t *testing.T) {
+	ec := curves.Secp256k1()
+
+	tests := []struct {
+		name    string
+		hex     string
+		wantErr bool
+	}{
+		{name: "valid", hex: "83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8", wantErr: false},
+		{name: "leading zeros", hex: "0000000000000000000000000000000000000000000000000000000000000001", wantErr: false},
+		{name: "zero", hex: "0000000000000000000000000000000000000000000000000000000000000000", wantErr: true},
+		{name: "equal to N", hex: "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", wantErr: true},
+		{name: "greater than N", hex: "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", wantErr: true},
+		{name: "too short", hex: "01", wantErr: true},
+		{name: "too long", hex: "0083ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8", wantErr: true},
+		{name: "not hex", hex: "zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priv, err := NewPrivateKeyFromHex(ec, tt.hex)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%v : NewPrivateKeyFromHex() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := hex.EncodeToString(priv.Bytes()); got != tt.hex {
+				t.Errorf("%v : Bytes() = %v, want %v", tt.name, got, tt.hex)
+			}
+		})
+	}
+}
+
+func Test_ParsePublicKey(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		b       []byte
+		wantErr bool
+	}{
+		{name: "compressed", b: priv.Q.MarshalCompressed(), wantErr: false},
+		{name: "uncompressed", b: priv.Q.MarshalUncompressed(), wantErr: false},
+		{name: "infinity", b: []byte{0x00}, wantErr: true},
+		{name: "truncated", b: priv.Q.MarshalCompressed()[:32], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub, err := ParsePublicKey(ec, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%v : ParsePublicKey() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidPublicKey) {
+					t.Errorf("%v : ParsePublicKey() error = %v, want ErrInvalidPublicKey", tt.name, err)
+				}
+				return
+			}
+			if pub.Q.String() != priv.Q.String() {
+				t.Errorf("%v : ParsePublicKey() = %v, want %v", tt.name, pub.Q, priv.Q)
+			}
+		})
+	}
+}
+
+func Test_NewPrivateKey_PublicKey(t *testing.T) {
+	ec := curves.Secp256k1()
+
+	// 1*G = G
+	priv, err := NewPrivateKey(ec, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := priv.Q.String(), "("+ec.Params().Gx.String()+","+ec.Params().Gy.String()+")"; got != want {
+		t.Errorf("NewPrivateKey(1).Q = %v, want %v", got, want)
+	}
+}
+
+func Test_SignAndVerify(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := NewPrivateKey(ec, mustHex("83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := Sign(priv, sha256Hash("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		msg  string
+		want bool
+	}{
+		{name: "same message", msg: "hello", want: true},
+		{name: "different message 1", msg: "hollo", want: false},
+		{name: "different message 2", msg: "here", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(&priv.PublicKey, sha256Hash(tt.msg), sig); got != tt.want {
+				t.Errorf("%v : Verify() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Verify_InvalidInput(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	priv, err := NewPrivateKey(ec, big.NewInt(12345))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewPrivateKey(ec, big.NewInt(54321))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256Hash("hello")
+	sig, err := Sign(priv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notOnCurve := &PublicKey{
+		Curve: ec,
+		Q: models.NewEllipticCurvePoint(
+			models.NewFiniteField(big.NewInt(1), ec.Params().P),
+			models.NewFiniteField(big.NewInt(1), ec.Params().P),
+			false,
+		),
+	}
+
+	tests := []struct {
+		name string
+		pub  *PublicKey
+		sig  *Signature
+	}{
+		{name: "nil signature", pub: &priv.PublicKey, sig: nil},
+		{name: "r = 0", pub: &priv.PublicKey, sig: &Signature{R: models.NewFiniteField(big.NewInt(0), n), S: sig.S}},
+		{name: "s = 0", pub: &priv.PublicKey, sig: &Signature{R: sig.R, S: models.NewFiniteField(big.NewInt(0), n)}},
+		{name: "r over wrong modulus", pub: &priv.PublicKey, sig: &Signature{R: models.NewFiniteField(sig.R.Value, ec.Params().P), S: sig.S}},
+		{name: "wrong public key", pub: &other.PublicKey, sig: sig},
+		{name: "public key not on curve", pub: notOnCurve, sig: sig},
+		{name: "public key at infinity", pub: &PublicKey{Curve: ec, Q: models.NewEllipticCurvePoint(nil, nil, true)}, sig: sig},
+		{name: "nil public key", pub: nil, sig: sig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if Verify(tt.pub, hash, tt.sig) {
+				t.Errorf("%v : Verify() = true, want false", tt.name)
+			}
+		})
+	}
+}
+
+// Test_Verify_P256 : crypto/ecdsa と相互に署名を検証できることを確認する
+func Test_Verify_P256(t *testing.T) {
+	ec := curves.P256()
+	hash := sha256Hash("cross check with crypto/ecdsa")
+
+	goPriv, err := goecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priv, err := NewPrivateKey(ec, goPriv.D)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.Q.X.Value.Cmp(goPriv.X) != 0 || priv.Q.Y.Value.Cmp(goPriv.Y) != 0 {
+		t.Fatalf("public key = %v, want (%v,%v)", priv.Q, goPriv.X, goPriv.Y)
+	}
+
+	r, s, err := goecdsa.Sign(rand.Reader, goPriv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSig := &Signature{
+		R: models.NewFiniteField(r, ec.Params().N),
+		S: models.NewFiniteField(s, ec.Params().N),
+	}
+	if !Verify(&priv.PublicKey, hash, goSig) {
+		t.Errorf("Verify() rejected a signature made by crypto/ecdsa")
+	}
+
+	sig, err := Sign(priv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !goecdsa.Verify(&goPriv.PublicKey, hash, sig.R.Value, sig.S.Value) {
+		t.Errorf("crypto/ecdsa rejected a signature made by Sign()")
+	}
+}
+
+// Test_Sign_LowS : libsecp256k1 / Trezor の RFC 6979 テストベクタ (s は N/2 以下に正規化済み)
+func Test_Sign_LowS(t *testing.T) {
+	ec := curves.Secp256k1()
+
+	tests := []struct {
+		name string
+		d    string
+		msg  string
+		want string // r || s
+	}{
+		{
+			name: "d = 1",
+			d:    "1",
+			msg:  "Satoshi Nakamoto",
+			want: "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d82442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5",
+		},
+		{
+			name: "d = 1, long message",
+			d:    "1",
+			msg:  "All those moments will be lost in time, like tears in rain. Time to die...",
+			want: "8600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc21",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priv, err := NewPrivateKey(ec, mustHex(tt.d))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig, err := Sign(priv, sha256Hash(tt.msg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(sig.MarshalCompact()); got != tt.want {
+				t.Errorf("%v : Sign() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Signature_Normalize(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	half := new(big.Int).Rsh(n, 1)
+
+	tests := []struct {
+		name    string
+		s       *big.Int
+		wantLow bool
+		wantS   *big.Int
+	}{
+		{name: "s = 1", s: big.NewInt(1), wantLow: true, wantS: big.NewInt(1)},
+		{name: "s = N/2", s: half, wantLow: true, wantS: half},
+		{name: "s = N/2 + 1", s: new(big.Int).Add(half, big.NewInt(1)), wantLow: false, wantS: half},
+		{name: "s = N - 1", s: new(big.Int).Sub(n, big.NewInt(1)), wantLow: false, wantS: big.NewInt(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := &Signature{R: models.NewFiniteField(big.NewInt(1), n), S: models.NewFiniteField(tt.s, n)}
+			if got := sig.IsLowS(); got != tt.wantLow {
+				t.Errorf("%v : IsLowS() = %v, want %v", tt.name, got, tt.wantLow)
+			}
+			if got := sig.Normalize(); got == tt.wantLow {
+				t.Errorf("%v : Normalize() = %v, want %v", tt.name, got, !tt.wantLow)
+			}
+			if sig.S.Value.Cmp(tt.wantS) != 0 || !sig.IsLowS() {
+				t.Errorf("%v : s after Normalize() = %v, want %v", tt.name, sig.S, tt.wantS)
+			}
+		})
+	}
+}
+
+func Test_Signature_InRange(t *testing.T) {
+	params := curves.Secp256k1().Params()
+	n, p := params.N, params.P
+
+	tests := []struct {
+		name string
+		r, s *models.FiniteField
+		want bool
+	}{
+		{name: "1, N - 1", r: models.NewFiniteField(big.NewInt(1), n), s: models.NewFiniteField(new(big.Int).Sub(n, big.NewInt(1)), n), want: true},
+		{name: "r = 0", r: models.NewFiniteField(big.NewInt(0), n), s: models.NewFiniteField(big.NewInt(1), n), want: false},
+		{name: "s = N", r: models.NewFiniteField(big.NewInt(1), n), s: &models.FiniteField{Value: new(big.Int).Set(n), Prime: n}, want: false},
+		{name: "r modulo p", r: models.NewFiniteField(big.NewInt(1), p), s: models.NewFiniteField(big.NewInt(1), n), want: false},
+		{name: "nil s", r: models.NewFiniteField(big.NewInt(1), n), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := &Signature{R: tt.r, S: tt.s}
+			if got := sig.InRange(n); got != tt.want {
+				t.Errorf("%v : InRange() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_HashToInt(t *testing.T) {
+	n := curves.Secp256k1().Params().N
+	hash := bytes.Repeat([]byte{0xab}, 32)
+
+	tests := []struct {
+		name string
+		hash []byte
+		want *big.Int
+	}{
+		{name: "empty", hash: nil, want: big.NewInt(0)},
+		{name: "shorter than N", hash: []byte{0x01, 0x02}, want: big.NewInt(0x0102)},
+		// N より大きい値はそのまま返す
+		{name: "same length", hash: hash, want: new(big.Int).SetBytes(hash)},
+		// 上位256ビットだけを使う
+		{name: "longer than N", hash: append(append([]byte{}, hash...), 0xcd, 0xef), want: new(big.Int).SetBytes(hash)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HashToInt(tt.hash, n); got.Cmp(tt.want) != 0 {
+				t.Errorf("%v : HashToInt() = %x, want %x", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Verify_RequireLowS(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256Hash("low s")
+
+	low, err := SignRecoverable(priv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !low.IsLowS() {
+		t.Fatalf("SignRecoverable() returned a high-S signature")
+	}
+
+	high := &RecoverableSignature{
+		Signature:  Signature{R: low.R, S: new(models.FiniteField).Neg(low.S)},
+		RecoveryID: low.RecoveryID ^ 1,
+	}
+
+	tests := []struct {
+		name string
+		sig  *Signature
+		opts []VerifyOption
+		want bool
+	}{
+		{name: "low-S", sig: &low.Signature, opts: nil, want: true},
+		{name: "low-S, strict", sig: &low.Signature, opts: []VerifyOption{RequireLowS()}, want: true},
+		{name: "high-S", sig: &high.Signature, opts: nil, want: true},
+		{name: "high-S, strict", sig: &high.Signature, opts: []VerifyOption{RequireLowS()}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(&priv.PublicKey, hash, tt.sig, tt.opts...); got != tt.want {
+				t.Errorf("%v : Verify() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	// 正規化すると recovery id も合わせて反転し、同じ公開鍵が復元できる
+	if !high.Normalize() {
+		t.Fatalf("RecoverableSignature.Normalize() = false, want true")
+	}
+	pub, err := RecoverPublicKey(ec, hash, high)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Q.String() != priv.Q.String() {
+		t.Errorf("RecoverPublicKey() after Normalize() = %v, want %v", pub.Q, priv.Q)
+	}
+}
@@ -0,0 +1,48 @@
+package ecdsa
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+// RecoverPublicKey() : recovery id付きの署名とメッセージのハッシュから公開鍵を復元する
+// (Ethereum の ecrecover や Bitcoin の signed message で使われる)
+// x := r + (recid >> 1) * N
+// R := x座標がxで、y座標の偶奇が (recid & 1) の点
+// z := メッセージのハッシュ
+// Q := (s*R - z*G) / r
+func RecoverPublicKey(ec *models.EllipticCurve, hash []byte, sig *RecoverableSignature) (*PublicKey, error) {
+	if sig == nil || sig.RecoveryID > 3 {
+		return nil, fmt.Errorf("%w: invalid recovery id", ErrInvalidSignature)
+	}
+
+	n := ec.Params().N
+	if !inRange(sig.R, n) || !inRange(sig.S, n) {
+		return nil, fmt.Errorf("%w: r or s out of range", ErrInvalidSignature)
+	}
+
+	// Rのx座標は r か r + N のどちらか
+	x := new(big.Int).Set(sig.R.Value)
+	if sig.RecoveryID&2 != 0 {
+		x.Add(x, n)
+	}
+	R, ok := ec.LiftX(x, sig.RecoveryID&1 == 1)
+	if !ok {
+		return nil, fmt.Errorf("%w: no curve point for r", ErrInvalidSignature)
+	}
+
+	// Q = (-z/r)*G + (s/r)*R
+	z := hashToFiniteField(hash, n)
+	u1 := new(models.FiniteField).Div(z, sig.R)
+	u1.Neg(u1)
+	u2 := new(models.FiniteField).Div(sig.S, sig.R)
+
+	Q := ec.DoubleScalarMultP(u1.Value.Bytes(), ec.Generator(), u2.Value.Bytes(), R)
+	if Q.IsZero {
+		return nil, fmt.Errorf("%w: recovered point at infinity", ErrInvalidSignature)
+	}
+
+	return &PublicKey{Curve: ec, Q: Q}, nil
+}
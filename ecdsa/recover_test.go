@@ -0,0 +1,160 @@
+package ecdsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func Test_RecoverPublicKey(t *testing.T) {
+	ec := curves.Secp256k1()
+
+	tests := []struct {
+		name string
+		hash string
+		sig  string // r || s || v
+		pub  string // 04 || x || y
+	}{
+		// go-ethereum crypto/signature_test.go (ecrecover)
+		{
+			name: "go-ethereum ecrecover",
+			hash: "ce0677bb30baa8cf067c88db9811f4333d131bf8bcf12fe7065d211dce971008",
+			sig:  "90f27b8b488db00b00606796d2987f6a5f59ae62ea05effe84fef5b8b0e549984a691139ad57a3f0b906637673aa2f63d1f55cb1a69199d4009eea23ceaddc9301",
+			pub:  "04e32df42865e97135acfb65f3bae71bdc86f4d49150ad6a440b6f15878109880a0a2b2667f7e725ceea70c673093bf67663e0312623c8e091b13cf2c0f11ef652",
+		},
+		// EIP-155 の仕様にある chain id 1 (mainnet) の署名済みトランザクションの例
+		// 0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a0...a0...
+		// hash は署名対象 rlp([nonce, gasPrice, gas, to, value, data, 1, 0, 0]) の keccak256
+		// v = 37 = 1*2 + 35 + 0 なので recovery id は 0 (go-ethereum の例は 1)
+		// 送信者は秘密鍵 0x4646...46 のアドレス 0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F
+		{
+			name: "EIP-155 chain id 1 transaction",
+			hash: "daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53",
+			sig:  "28ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa63627667cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d8300",
+			pub:  "044bc2a31265153f07e70e0bab08724e6b85e217f8cd628ceb62974247bb493382ce28cab79ad7119ee1ad3ebcdb98a16805211530ecc6cfefa1b88e6dff99232a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := ParseRecoverableSignature(ec, mustDecodeHex(tt.sig))
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash := mustDecodeHex(tt.hash)
+
+			pub, err := RecoverPublicKey(ec, hash, sig)
+			if err != nil {
+				t.Fatalf("%v : RecoverPublicKey() error = %v", tt.name, err)
+			}
+
+			want := mustDecodeHex(tt.pub)
+			if pub.Q.X.Value.Cmp(new(big.Int).SetBytes(want[1:33])) != 0 || pub.Q.Y.Value.Cmp(new(big.Int).SetBytes(want[33:])) != 0 {
+				t.Errorf("%v : RecoverPublicKey() = %v, want %v", tt.name, pub.Q, tt.pub)
+			}
+			if !Verify(pub, hash, &sig.Signature) {
+				t.Errorf("%v : Verify() with the recovered key = false, want true", tt.name)
+			}
+		})
+	}
+}
+
+func Test_RecoverPublicKey_RoundTrip(t *testing.T) {
+	ec := curves.Secp256k1()
+
+	for i := 0; i < 8; i++ {
+		priv, err := GenerateKey(ec, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := sha256Hash(string(rune('a' + i)))
+
+		sig, err := SignRecoverable(priv, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pub, err := RecoverPublicKey(ec, hash, sig)
+		if err != nil {
+			t.Fatalf("RecoverPublicKey() error = %v", err)
+		}
+		if pub.Q.String() != priv.Q.String() {
+			t.Errorf("RecoverPublicKey() = %v, want %v", pub.Q, priv.Q)
+		}
+
+		// recovery idを変えると別の公開鍵になるか、復元できない
+		wrong := *sig
+		wrong.RecoveryID ^= 1
+		if pub, err := RecoverPublicKey(ec, hash, &wrong); err == nil && pub.Q.String() == priv.Q.String() {
+			t.Errorf("RecoverPublicKey() with a flipped recovery id returned the signer's key")
+		}
+	}
+}
+
+// Test_RecoverPublicKey_Overflow : Rのx座標がN以上 (recid = 2, 3) の場合を確認する
+// そのようなnonceを探すのは現実的でないので、Rとsを先に選び、
+// 署名が正しくなる公開鍵を Q = (s*R - z*G) / r として作る
+func Test_RecoverPublicKey_Overflow(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	hash := sha256Hash("r + N")
+
+	x := new(big.Int).Set(n)
+	for {
+		x.Add(x, big.NewInt(1))
+		if _, ok := ec.LiftX(x, false); ok {
+			break
+		}
+	}
+
+	for _, odd := range []bool{false, true} {
+		R, _ := ec.LiftX(x, odd)
+		s := models.NewFiniteField(big.NewInt(12345), n)
+		r := models.NewFiniteField(R.X.Value, n)
+		recid := byte(2)
+		if odd {
+			recid |= 1
+		}
+		sig := &RecoverableSignature{Signature: Signature{R: r, S: s}, RecoveryID: recid}
+
+		pub, err := RecoverPublicKey(ec, hash, sig)
+		if err != nil {
+			t.Fatalf("recid = %v : RecoverPublicKey() error = %v", recid, err)
+		}
+		if !Verify(pub, hash, &sig.Signature) {
+			t.Errorf("recid = %v : Verify() with the recovered key = false, want true", recid)
+		}
+	}
+}
+
+func Test_RecoverPublicKey_Invalid(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	hash := sha256Hash("hello")
+	one := models.NewFiniteField(big.NewInt(1), n)
+
+	// x = 5 は secp256k1 上の点のx座標ではない (5^3 + 7 は平方非剰余)
+	five := models.NewFiniteField(big.NewInt(5), n)
+
+	tests := []struct {
+		name string
+		sig  *RecoverableSignature
+	}{
+		{name: "nil", sig: nil},
+		{name: "recovery id 4", sig: &RecoverableSignature{Signature: Signature{R: one, S: one}, RecoveryID: 4}},
+		{name: "r = 0", sig: &RecoverableSignature{Signature: Signature{R: models.NewFiniteField(big.NewInt(0), n), S: one}}},
+		{name: "no point for r", sig: &RecoverableSignature{Signature: Signature{R: five, S: one}}},
+		{name: "r + N exceeds p", sig: &RecoverableSignature{Signature: Signature{R: models.NewFiniteField(new(big.Int).Sub(n, big.NewInt(1)), n), S: one}, RecoveryID: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RecoverPublicKey(ec, hash, tt.sig); err == nil {
+				t.Errorf("%v : RecoverPublicKey() error = nil, want error", tt.name)
+			}
+		})
+	}
+}
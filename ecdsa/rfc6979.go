@@ -0,0 +1,82 @@
+package ecdsa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+)
+
+// rfc6979 : RFC 6979 の HMAC-DRBG (HMAC-SHA256) による決定的なnonce生成器
+// libsecp256k1 と同様に、追加のエントロピー(extra data)を混ぜることもできる
+type rfc6979 struct {
+	n *big.Int
+	k []byte
+	v []byte
+}
+
+// newRFC6979() : RFC 6979 3.2 の手順 a. 〜 g. を行い、生成器を初期化する
+// d := 秘密鍵
+// hash := メッセージのハッシュ
+// extra := 追加のエントロピー(nilでもよい)
+func newRFC6979(n, d *big.Int, hash, extra []byte) *rfc6979 {
+	rlen := byteLen(n)
+	x := d.FillBytes(make([]byte, rlen))
+	h1 := hashToFiniteField(hash, n).Value.FillBytes(make([]byte, rlen))
+
+	g := &rfc6979{
+		n: n,
+		k: make([]byte, sha256.Size),
+		v: make([]byte, sha256.Size),
+	}
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+
+	// K = HMAC_K(V || 0x00 || int2octets(x) || bits2octets(h1) || extra)
+	// V = HMAC_K(V)
+	g.k = g.mac(g.v, []byte{0x00}, x, h1, extra)
+	g.v = g.mac(g.v)
+
+	// K = HMAC_K(V || 0x01 || int2octets(x) || bits2octets(h1) || extra)
+	// V = HMAC_K(V)
+	g.k = g.mac(g.v, []byte{0x01}, x, h1, extra)
+	g.v = g.mac(g.v)
+
+	return g
+}
+
+func (g *rfc6979) mac(data ...[]byte) []byte {
+	m := hmac.New(sha256.New, g.k)
+	for _, d := range data {
+		m.Write(d)
+	}
+	return m.Sum(nil)
+}
+
+// next() : [1, N) の範囲の次のnonce候補を返す (RFC 6979 3.2 h.)
+// 得られたnonceで署名できなかった場合も、もう一度next()を呼べばよい
+func (g *rfc6979) next() *big.Int {
+	qlen := g.n.BitLen()
+	for {
+		var t []byte
+		for len(t)*8 < qlen {
+			g.v = g.mac(g.v)
+			t = append(t, g.v...)
+		}
+
+		k := new(big.Int).SetBytes(t)
+		if excess := len(t)*8 - qlen; excess > 0 {
+			k.Rsh(k, uint(excess))
+		}
+
+		// 次の候補のために状態を更新しておく
+		// K = HMAC_K(V || 0x00)
+		// V = HMAC_K(V)
+		g.k = g.mac(g.v, []byte{0x00})
+		g.v = g.mac(g.v)
+
+		if k.Sign() > 0 && k.Cmp(g.n) < 0 {
+			return k
+		}
+	}
+}
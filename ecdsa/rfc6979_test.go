@@ -0,0 +1,199 @@
+package ecdsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+func Test_rfc6979_next(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *models.EllipticCurve
+		d    string
+		msg  string
+		want string
+	}{
+		// RFC 6979 A.2.5 (P-256, SHA-256)
+		{
+			name: "P-256 sample",
+			ec:   curves.P256(),
+			d:    "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+			msg:  "sample",
+			want: "A6E3C57DD01ABE90086538398355DD4C3B17AA873382B0F24D6129493D8AAD60",
+		},
+		{
+			name: "P-256 test",
+			ec:   curves.P256(),
+			d:    "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+			msg:  "test",
+			want: "D16B6AE827F17175E040871A1C7EC3500192C4C92677336EC2537ACAEE0008E0",
+		},
+		// secp256k1, SHA-256
+		{
+			name: "secp256k1 d = 1",
+			ec:   curves.Secp256k1(),
+			d:    "1",
+			msg:  "Satoshi Nakamoto",
+			want: "8F8A276C19F4149656B280621E358CCE24F5F52542772691EE69063B74F15D15",
+		},
+		{
+			name: "secp256k1 d = 1, long message",
+			ec:   curves.Secp256k1(),
+			d:    "1",
+			msg:  "All those moments will be lost in time, like tears in rain. Time to die...",
+			want: "38AA22D72376B4DBC472E06C3BA403EE0A394DA63FC58D88686C611ABA98D6B3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newRFC6979(tt.ec.Params().N, mustHex(tt.d), sha256Hash(tt.msg), nil)
+			if got := g.next(); got.Cmp(mustHex(tt.want)) != 0 {
+				t.Errorf("%v : next() = %X, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sign_RFC6979(t *testing.T) {
+	// RFC 6979 A.2.5 (P-256, SHA-256)
+	ec := curves.P256()
+	priv, err := NewPrivateKey(ec, mustHex("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		msg   string
+		wantR string
+		wantS string
+	}{
+		{
+			name:  "sample",
+			msg:   "sample",
+			wantR: "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+			// RFC 6979 では s = F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8
+			// N - s に正規化される
+			wantS: "0834E36AD29A83BF2BC9385E491D6099C8FDF9D1ED67AA7EA5F51F93782857A9",
+		},
+		{
+			name:  "test",
+			msg:   "test",
+			wantR: "F1ABB023518351CD71D881567B1EA663ED3EFCF6C5132B354F28D3B0B7D38367",
+			wantS: "019F4113742A2B14BD25926B49C649155F267E60D3814B4C0CC84250E46F0083",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := Sign(priv, sha256Hash(tt.msg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sig.R.Value.Cmp(mustHex(tt.wantR)) != 0 || sig.S.Value.Cmp(mustHex(tt.wantS)) != 0 {
+				t.Errorf("%v : Sign() = (%X, %X), want (%v, %v)", tt.name, sig.R.Value, sig.S.Value, tt.wantR, tt.wantS)
+			}
+		})
+	}
+}
+
+func Test_Sign_Options(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := NewPrivateKey(ec, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256Hash("Satoshi Nakamoto")
+
+	deterministic, err := Sign(priv, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     []SignOption
+		wantSame bool
+	}{
+		{name: "no option", opts: nil, wantSame: true},
+		{name: "nil extra entropy", opts: []SignOption{WithExtraEntropy(nil)}, wantSame: true},
+		{name: "extra entropy", opts: []SignOption{WithExtraEntropy(bytes.Repeat([]byte{0x01}, 32))}, wantSame: false},
+		{name: "random nonce", opts: []SignOption{WithRandomNonce(rand.Reader)}, wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := Sign(priv, hash, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Verify(&priv.PublicKey, hash, sig) {
+				t.Errorf("%v : Verify() = false, want true", tt.name)
+			}
+			if same := sig.R.Equals(deterministic.R) && sig.S.Equals(deterministic.S); same != tt.wantSame {
+				t.Errorf("%v : same signature = %v, want %v", tt.name, same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func Test_Sign_RandomNonceError(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := NewPrivateKey(ec, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Sign(priv, sha256Hash("hello"), WithRandomNonce(bytes.NewReader(nil))); err == nil {
+		t.Errorf("Sign() with an empty reader error = nil, want error")
+	}
+}
+
+func Test_Sign_WithNonce(t *testing.T) {
+	ec := curves.Secp256k1()
+	n := ec.Params().N
+	priv, err := NewPrivateKey(ec, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256Hash("hello")
+
+	tests := []struct {
+		name    string
+		k       *big.Int
+		wantErr error
+	}{
+		{name: "small", k: big.NewInt(12345)},
+		{name: "N - 1", k: new(big.Int).Sub(n, big.NewInt(1))},
+		{name: "zero", k: big.NewInt(0), wantErr: ErrInvalidNonce},
+		{name: "negative", k: big.NewInt(-1), wantErr: ErrInvalidNonce},
+		{name: "N", k: new(big.Int).Set(n), wantErr: ErrInvalidNonce},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := Sign(priv, hash, WithNonce(tt.k))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : Sign() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !Verify(&priv.PublicKey, hash, sig) {
+				t.Errorf("%v : Verify() = false, want true", tt.name)
+			}
+			// r は k*G のx座標
+			R := ec.ScalarBaseMultP(tt.k.Bytes())
+			if want := new(big.Int).Mod(R.X.Value, n); sig.R.Value.Cmp(want) != 0 {
+				t.Errorf("%v : Sign() r = %x, want %x", tt.name, sig.R.Value, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Package dudect は dudect (https://eprint.iacr.org/2016/1123) の方式で
+// 関数の実行時間が入力によって変わるかどうかを統計的に調べる
+//
+// 入力を「固定値」のクラス0と「乱数」のクラス1に分け、ランダムな順序で実行時間を計測する
+// 2つのクラスの実行時間の分布を Welch の t 検定で比べ、|t| が大きければ
+// 実行時間が入力に依存している (定数時間ではない) と判断する
+package dudect
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Threshold : これを超える |t| は、定数時間ではないとみなす値 (dudect の既定値)
+const Threshold = 4.5
+
+// welch : Welch の t 検定のための、クラスごとの平均と分散のオンライン計算 (Welford 法)
+type welch struct {
+	n    [2]float64
+	mean [2]float64
+	m2   [2]float64
+}
+
+func (w *welch) push(class int, x float64) {
+	w.n[class]++
+	delta := x - w.mean[class]
+	w.mean[class] += delta / w.n[class]
+	w.m2[class] += delta * (x - w.mean[class])
+}
+
+// t() : t = (mean0 - mean1) / sqrt(var0/n0 + var1/n1)
+func (w *welch) t() float64 {
+	if w.n[0] < 2 || w.n[1] < 2 {
+		return 0
+	}
+	v0 := w.m2[0] / (w.n[0] - 1)
+	v1 := w.m2[1] / (w.n[1] - 1)
+	den := math.Sqrt(v0/w.n[0] + v1/w.n[1])
+	if den == 0 {
+		return 0
+	}
+	return (w.mean[0] - w.mean[1]) / den
+}
+
+// Result : 計測結果
+// T := すべての計測値と、外れ値を除くために上位を切り捨てた計測値のうち、|t| が最大のもの
+type Result struct {
+	T       float64
+	Samples int
+}
+
+// Leaks() : |t| が Threshold を超えたかどうか
+func (r Result) Leaks() bool {
+	return math.Abs(r.T) > Threshold
+}
+
+// Test() : n 回計測して t 値を求める
+// input(class) で入力を作り、measure(in) の実行時間を計測する
+// 入力の生成は計測の前にまとめて行うので、計測には含まれない
+func Test(n int, seed int64, input func(class int) []byte, measure func(in []byte)) Result {
+	r := rand.New(rand.NewSource(seed))
+
+	classes := make([]int, n)
+	inputs := make([][]byte, n)
+	for i := range inputs {
+		classes[i] = r.Intn(2)
+		inputs[i] = input(classes[i])
+	}
+
+	times := make([]float64, n)
+	for i, in := range inputs {
+		start := time.Now()
+		measure(in)
+		times[i] = float64(time.Since(start))
+	}
+
+	// 割り込みなどで極端に遅くなった計測値の影響を減らすため、
+	// dudect と同じく上位を切り捨てたものについても t 値を求める
+	sorted := append([]float64(nil), times...)
+	sort.Float64s(sorted)
+	cutoffs := []float64{math.Inf(1)}
+	for _, q := range []float64{0.5, 0.75, 0.9, 0.95, 0.99} {
+		cutoffs = append(cutoffs, sorted[int(q*float64(n-1))])
+	}
+
+	var result Result
+	for _, cutoff := range cutoffs {
+		var w welch
+		for i, x := range times {
+			if x <= cutoff {
+				w.push(classes[i], x)
+			}
+		}
+		if t := w.t(); math.Abs(t) > math.Abs(result.T) {
+			result.T = t
+		}
+	}
+	result.Samples = n
+	return result
+}
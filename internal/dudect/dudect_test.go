@@ -0,0 +1,58 @@
+package dudect
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_welch_t(t *testing.T) {
+	tests := []struct {
+		name string
+		x0   []float64
+		x1   []float64
+		want float64
+	}{
+		{name: "same distribution", x0: []float64{1, 2, 3, 4}, x1: []float64{1, 2, 3, 4}, want: 0},
+		// mean0 = 2.5, mean1 = 4.5, var0 = var1 = 5/3 -> t = -2 / sqrt(5/6)
+		{name: "shifted", x0: []float64{1, 2, 3, 4}, x1: []float64{3, 4, 5, 6}, want: -2 / math.Sqrt(5.0/6.0)},
+		{name: "too few samples", x0: []float64{1}, x1: []float64{100}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w welch
+			for _, x := range tt.x0 {
+				w.push(0, x)
+			}
+			for _, x := range tt.x1 {
+				w.push(1, x)
+			}
+			if got := w.t(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("%v : welch.t() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Test(t *testing.T) {
+	input := func(class int) []byte {
+		return []byte{byte(class)}
+	}
+
+	// クラス1のときだけ余計な処理をする関数は検出される
+	leaky := Test(2000, 1, input, func(in []byte) {
+		if in[0] == 1 {
+			s := 0
+			for i := 0; i < 20000; i++ {
+				s += i
+			}
+			_ = s
+		}
+	})
+	if !leaky.Leaks() {
+		t.Errorf("Test(leaky) = %+v, want Leaks() == true", leaky)
+	}
+	if leaky.Samples != 2000 {
+		t.Errorf("Test(leaky).Samples = %v, want 2000", leaky.Samples)
+	}
+}
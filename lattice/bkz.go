@@ -0,0 +1,148 @@
+package lattice
+
+import (
+	"math"
+	"math/big"
+)
+
+// BKZ() : 基底を ブロックサイズ blockSize の BKZ で簡約する
+// 各 k について、b_k .. b_(k+blockSize-1) を b*_k の方向に射影した格子の最短ベクトルを数え上げで求め、
+// |b*_k| より十分短ければ b_k の位置に入れて LLL をかけ直す
+// blockSize が大きいほど LLL() より短いベクトルが求まるが、数え上げに指数時間かかる
+// 基底ベクトルが1次独立でない場合は ErrDependent
+func BKZ(basis [][]*big.Int, blockSize int, opts ...Option) error {
+	if err := checkBasis(basis); err != nil {
+		return err
+	}
+	c := newConfig(opts)
+	g, err := newGSO(basis)
+	if err != nil {
+		return err
+	}
+	g.lll(c.delta)
+
+	n := len(basis)
+	if blockSize > n {
+		blockSize = n
+	}
+	if blockSize < 2 {
+		return nil
+	}
+	delta, _ := c.delta.Float64()
+
+	for tour := 0; c.maxTours <= 0 || tour < c.maxTours; tour++ {
+		changed := false
+		for k := 0; k < n-1; k++ {
+			h := k + blockSize
+			if h > n {
+				h = n
+			}
+			mu, B := g.block(k, h)
+			x, ok := enumerate(mu, B, delta)
+			if !ok {
+				continue
+			}
+
+			g.insert(k, x)
+			for i := k; i < n; i++ {
+				if err := g.update(i); err != nil {
+					return err
+				}
+			}
+			g.lll(c.delta)
+			changed = true
+		}
+		if !changed {
+			break
+		}
+	}
+	return nil
+}
+
+// block() : k..h-1 行目の μ_ij と |b*_i|^2 / |b*_k|^2 を float64 で求める
+func (g *gso) block(k, h int) (mu [][]float64, B []float64) {
+	m := h - k
+	mu = make([][]float64, m)
+	B = make([]float64, m)
+	bk := new(big.Rat).SetFrac(g.d[k+1], g.d[k])
+	for i := 0; i < m; i++ {
+		bi := new(big.Rat).SetFrac(g.d[k+i+1], g.d[k+i])
+		B[i], _ = bi.Quo(bi, bk).Float64()
+
+		mu[i] = make([]float64, i)
+		for j := 0; j < i; j++ {
+			mu[i][j], _ = new(big.Rat).SetFrac(g.lambda[k+i][k+j], g.d[k+j+1]).Float64()
+		}
+	}
+	return mu, B
+}
+
+// enumerate() : |Σ x_i b*_i + ...|^2 = Σ_i B_i (x_i + Σ_(j>i) μ_ji x_j)^2 < bound となる、
+// 0でない整数ベクトルxのうちで最も短いものを深さ優先で数え上げる
+func enumerate(mu [][]float64, B []float64, bound float64) (x []int64, ok bool) {
+	m := len(B)
+	best := bound
+	cur := make([]float64, m)
+
+	var rec func(i int, partial float64)
+	rec = func(i int, partial float64) {
+		c := 0.0
+		for j := i + 1; j < m; j++ {
+			c -= mu[j][i] * cur[j]
+		}
+		r := math.Sqrt((best - partial) / B[i])
+		for xi := math.Ceil(c - r); xi <= math.Floor(c+r); xi++ {
+			l := partial + B[i]*(xi-c)*(xi-c)
+			if l >= best {
+				continue
+			}
+			cur[i] = xi
+			if i > 0 {
+				rec(i-1, l)
+				continue
+			}
+			// すべて0なら l == 0 になる
+			if l > 0 {
+				best = l
+				x = make([]int64, m)
+				for j := range cur {
+					x[j] = int64(cur[j])
+				}
+			}
+		}
+		cur[i] = 0
+	}
+	rec(m-1, 0)
+	return x, x != nil
+}
+
+// insert() : k 行目以降を、k 行目が Σ x_i b_(k+i) になるように変換する
+// 隣り合う2行に拡張ユークリッドの互除法と同じ変換をかけていくので、格子は変わらない
+func (g *gso) insert(k int, x []int64) {
+	xs := make([]*big.Int, len(x))
+	for i, v := range x {
+		xs[i] = big.NewInt(v)
+	}
+
+	b := g.b[k : k+len(x)]
+	q, t := new(big.Int), new(big.Int)
+	for i := len(xs) - 1; i > 0; i-- {
+		for xs[i].Sign() != 0 {
+			// x_(i-1) = q*x_i + r として
+			// x_(i-1)*b_(i-1) + x_i*b_i = r*b_(i-1) + x_i*(b_i + q*b_(i-1))
+			r := new(big.Int)
+			q.QuoRem(xs[i-1], xs[i], r)
+			for j := range b[i] {
+				b[i][j].Add(b[i][j], t.Mul(q, b[i-1][j]))
+			}
+			xs[i-1] = r
+			b[i-1], b[i] = b[i], b[i-1]
+			xs[i-1], xs[i] = xs[i], xs[i-1]
+		}
+	}
+	if xs[0].Sign() < 0 {
+		for j := range b[0] {
+			b[0][j].Neg(b[0][j])
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package lattice
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func Test_BKZ(t *testing.T) {
+	tests := []struct {
+		name      string
+		basis     [][]*big.Int
+		blockSize int
+	}{
+		{name: "block size 1 is LLL", basis: randomBasis(8, 64, 1), blockSize: 1},
+		{name: "block size 2", basis: randomBasis(8, 64, 1), blockSize: 2},
+		{name: "block size 4", basis: randomBasis(12, 64, 2), blockSize: 4},
+		{name: "block size 8", basis: randomBasis(12, 64, 3), blockSize: 8},
+		{name: "block size larger than dimension", basis: randomBasis(6, 64, 4), blockSize: 10},
+		{name: "subset sum", basis: func() [][]*big.Int { b, _ := subsetSumBasis(16, 80, 5); return b }(), blockSize: 6},
+	}
+
+	delta := big.NewRat(99, 100)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := copyBasis(tt.basis)
+			lll := copyBasis(tt.basis)
+			if err := LLL(lll); err != nil {
+				t.Fatalf("%v : LLL() err = %v", tt.name, err)
+			}
+
+			if err := BKZ(tt.basis, tt.blockSize); err != nil {
+				t.Fatalf("%v : BKZ() err = %v", tt.name, err)
+			}
+			if err := checkReduced(tt.basis, delta); err != nil {
+				t.Errorf("%v : BKZ() is not LLL-reduced : %v", tt.name, err)
+			}
+			if err := checkSameLattice(tt.basis, orig); err != nil {
+				t.Errorf("%v : BKZ() changed the lattice : %v", tt.name, err)
+			}
+			if norm2(tt.basis[0]).Cmp(norm2(lll[0])) > 0 {
+				t.Errorf("%v : BKZ() |b_0|^2 = %v, longer than LLL() %v", tt.name, norm2(tt.basis[0]), norm2(lll[0]))
+			}
+		})
+	}
+}
+
+// Test_BKZ_Shortest : ブロックサイズが次元と同じなら、b_0 は最短ベクトルになる
+func Test_BKZ_Shortest(t *testing.T) {
+	for seed := int64(0); seed < 4; seed++ {
+		basis := randomBasis(5, 16, seed)
+		orig := copyBasis(basis)
+		if err := BKZ(basis, 5); err != nil {
+			t.Fatalf("seed = %v : BKZ() err = %v", seed, err)
+		}
+
+		// 簡約した基底の係数 [-3, 3] の組み合わせをすべて試して、b_0 より短いものがないか確かめる
+		got := norm2(basis[0])
+		n := len(basis)
+		x := make([]int64, n)
+		var rec func(i int)
+		rec = func(i int) {
+			if i < n {
+				for c := int64(-3); c <= 3; c++ {
+					x[i] = c
+					rec(i + 1)
+				}
+				return
+			}
+			v := make([]*big.Int, n)
+			zero := true
+			for j := range v {
+				v[j] = new(big.Int)
+				for k := range x {
+					v[j].Add(v[j], new(big.Int).Mul(big.NewInt(x[k]), basis[k][j]))
+				}
+				zero = zero && v[j].Sign() == 0
+			}
+			if !zero && norm2(v).Cmp(got) < 0 {
+				t.Errorf("seed = %v : BKZ() |b_0|^2 = %v, but %v (x = %v) has %v", seed, got, v, x, norm2(v))
+			}
+		}
+		rec(0)
+
+		if err := checkSameLattice(basis, orig); err != nil {
+			t.Errorf("seed = %v : BKZ() changed the lattice : %v", seed, err)
+		}
+	}
+}
+
+func Test_gso_insert(t *testing.T) {
+	tests := []struct {
+		name string
+		k    int
+		x    []int64
+	}{
+		{name: "first", k: 0, x: []int64{3, -2, 5}},
+		{name: "middle", k: 1, x: []int64{0, 7, -4}},
+		{name: "single", k: 2, x: []int64{-1}},
+		{name: "unit", k: 0, x: []int64{0, 0, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			basis := randomBasis(5, 32, 1)
+			orig := copyBasis(basis)
+			g, err := newGSO(basis)
+			if err != nil {
+				t.Fatalf("%v : newGSO() err = %v", tt.name, err)
+			}
+			g.insert(tt.k, tt.x)
+
+			want := make([]*big.Int, len(orig[0]))
+			for j := range want {
+				want[j] = new(big.Int)
+				for i, c := range tt.x {
+					want[j].Add(want[j], new(big.Int).Mul(big.NewInt(c), orig[tt.k+i][j]))
+				}
+			}
+			if fmt.Sprint(basis[tt.k]) != fmt.Sprint(want) {
+				t.Errorf("%v : insert() b_%d = %v, want %v", tt.name, tt.k, basis[tt.k], want)
+			}
+			for i := 0; i < tt.k; i++ {
+				if fmt.Sprint(basis[i]) != fmt.Sprint(orig[i]) {
+					t.Errorf("%v : insert() changed b_%d", tt.name, i)
+				}
+			}
+			if err := checkSameLattice(basis, orig); err != nil {
+				t.Errorf("%v : insert() changed the lattice : %v", tt.name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// lattice : 整数格子の基底簡約 (LLL, BKZ)
+// 基底は [][]*big.Int の各行を基底ベクトルとし、その場で書き換える
+// Gram-Schmidt の係数は整数だけで正確に計算する (Cohen, A Course in Computational Algebraic Number Theory, 2.6.7)
+package lattice
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	ErrInvalidBasis = errors.New("lattice: invalid basis")
+	ErrDependent    = errors.New("lattice: basis vectors are linearly dependent")
+)
+
+// Option : LLL(), BKZ() の挙動を変更するオプション
+type Option func(*config)
+
+type config struct {
+	delta    *big.Rat
+	maxTours int
+}
+
+// WithDelta() : Lovász 条件の δ (1/4 < δ < 1) を指定する (既定値は 0.99)
+// δ が1に近いほど短いベクトルが求まるが、時間がかかる
+func WithDelta(delta *big.Rat) Option {
+	return func(c *config) {
+		if delta.Cmp(big.NewRat(1, 4)) > 0 && delta.Cmp(big.NewRat(1, 1)) < 0 {
+			c.delta = delta
+		}
+	}
+}
+
+// WithMaxTours() : BKZ() で基底全体をなめる回数の上限を指定する (既定値は 0 で、基底が変わらなくなるまで)
+func WithMaxTours(n int) Option {
+	return func(c *config) {
+		c.maxTours = n
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{delta: big.NewRat(99, 100)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// gso : 基底の Gram-Schmidt 直交化を整数で表したもの
+// b*_i を Gram-Schmidt 直交化したベクトル、μ_ij = <b_i, b*_j> / <b*_j, b*_j> として
+//
+//	d[i+1] = Π_(j <= i) |b*_j|^2 (d[0] = 1)
+//	lambda[i][j] = d[j+1] * μ_ij (j < i)
+//
+// はどちらも整数になる
+type gso struct {
+	b      [][]*big.Int
+	d      []*big.Int
+	lambda [][]*big.Int
+}
+
+func checkBasis(b [][]*big.Int) error {
+	if len(b) == 0 {
+		return fmt.Errorf("%w: empty basis", ErrInvalidBasis)
+	}
+	for i, v := range b {
+		if len(v) != len(b[0]) {
+			return fmt.Errorf("%w: row %d has %d entries, want %d", ErrInvalidBasis, i, len(v), len(b[0]))
+		}
+		for _, x := range v {
+			if x == nil {
+				return fmt.Errorf("%w: nil entry in row %d", ErrInvalidBasis, i)
+			}
+		}
+	}
+	return nil
+}
+
+func dot(x, y []*big.Int) *big.Int {
+	s, t := new(big.Int), new(big.Int)
+	for i := range x {
+		s.Add(s, t.Mul(x[i], y[i]))
+	}
+	return s
+}
+
+// newGSO() : 基底のすべての行について d と lambda を求める
+func newGSO(b [][]*big.Int) (*gso, error) {
+	g := &gso{b: b, d: make([]*big.Int, len(b)+1), lambda: make([][]*big.Int, len(b))}
+	g.d[0] = big.NewInt(1)
+	for k := range b {
+		if err := g.update(k); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// update() : k 行目の d[k+1] と lambda[k] を求め直す (0..k-1 行目は求まっているものとする)
+func (g *gso) update(k int) error {
+	g.lambda[k] = make([]*big.Int, k)
+	t := new(big.Int)
+	for j := 0; j <= k; j++ {
+		u := dot(g.b[k], g.b[j])
+		for i := 0; i < j; i++ {
+			// u = (d_i * u - λ_ki * λ_ji) / d_(i-1)
+			u.Mul(u, g.d[i+1])
+			u.Sub(u, t.Mul(g.lambda[k][i], g.lambda[j][i]))
+			u.Quo(u, g.d[i])
+		}
+		if j < k {
+			g.lambda[k][j] = u
+			continue
+		}
+		if u.Sign() == 0 {
+			return fmt.Errorf("%w: row %d", ErrDependent, k)
+		}
+		g.d[k+1] = u
+	}
+	return nil
+}
+
+// reduce() : b_k から b_l の整数倍を引いて |μ_kl| <= 1/2 にする (Cohen の REDI)
+func (g *gso) reduce(k, l int) {
+	lam := g.lambda[k][l]
+	dl := g.d[l+1]
+	// |2λ| <= d_l なら何もしない
+	if new(big.Int).Abs(new(big.Int).Lsh(lam, 1)).Cmp(dl) <= 0 {
+		return
+	}
+
+	// q = round(λ / d_l)
+	q := new(big.Int).Lsh(lam, 1)
+	q.Add(q, dl)
+	q.Div(q, new(big.Int).Lsh(dl, 1))
+
+	t := new(big.Int)
+	for i := range g.b[k] {
+		g.b[k][i].Sub(g.b[k][i], t.Mul(q, g.b[l][i]))
+	}
+	lam.Sub(lam, t.Mul(q, dl))
+	for i := 0; i < l; i++ {
+		g.lambda[k][i].Sub(g.lambda[k][i], t.Mul(q, g.lambda[l][i]))
+	}
+}
+
+// swap() : b_k と b_(k-1) を入れ替え、d と lambda を更新する (Cohen の SWAPI)
+// n は lambda を更新する行の数 (k+1..n-1 行目)
+func (g *gso) swap(k, n int) {
+	g.b[k], g.b[k-1] = g.b[k-1], g.b[k]
+	for j := 0; j < k-1; j++ {
+		g.lambda[k][j], g.lambda[k-1][j] = g.lambda[k-1][j], g.lambda[k][j]
+	}
+
+	lam := g.lambda[k][k-1]
+	// B = (d_(k-2) * d_k + λ^2) / d_(k-1)
+	B := new(big.Int).Mul(g.d[k-1], g.d[k+1])
+	B.Add(B, new(big.Int).Mul(lam, lam))
+	B.Quo(B, g.d[k])
+
+	t, u := new(big.Int), new(big.Int)
+	for i := k + 1; i < n; i++ {
+		t.Set(g.lambda[i][k])
+		// λ_ik = (d_k * λ_i(k-1) - λ * t) / d_(k-1)
+		v := new(big.Int).Mul(g.d[k+1], g.lambda[i][k-1])
+		v.Sub(v, u.Mul(lam, t))
+		v.Quo(v, g.d[k])
+		g.lambda[i][k] = v
+		// λ_i(k-1) = (B * t + λ * λ_ik) / d_k
+		w := new(big.Int).Mul(B, t)
+		w.Add(w, u.Mul(lam, v))
+		w.Quo(w, g.d[k+1])
+		g.lambda[i][k-1] = w
+	}
+	g.d[k] = B
+}
+
+// lovasz() : Lovász 条件 |b*_k|^2 >= (δ - μ_k(k-1)^2) |b*_(k-1)|^2 を満たすかどうか
+// δ = p/q として q * d_k * d_(k-2) >= p * d_(k-1)^2 - q * λ^2 と同じ
+func (g *gso) lovasz(k int, delta *big.Rat) bool {
+	p, q := delta.Num(), delta.Denom()
+	lhs := new(big.Int).Mul(g.d[k+1], g.d[k-1])
+	lhs.Mul(lhs, q)
+
+	lam := g.lambda[k][k-1]
+	rhs := new(big.Int).Mul(g.d[k], g.d[k])
+	rhs.Mul(rhs, p)
+	rhs.Sub(rhs, new(big.Int).Mul(q, new(big.Int).Mul(lam, lam)))
+	return lhs.Cmp(rhs) >= 0
+}
+
+// lll() : g.b を LLL 簡約する
+func (g *gso) lll(delta *big.Rat) {
+	n := len(g.b)
+	for k := 1; k < n; {
+		g.reduce(k, k-1)
+		if !g.lovasz(k, delta) {
+			g.swap(k, n)
+			if k > 1 {
+				k--
+			}
+			continue
+		}
+		for l := k - 2; l >= 0; l-- {
+			g.reduce(k, l)
+		}
+		k++
+	}
+}
+
+// LLL() : 基底を LLL 簡約する
+// 簡約された基底の最初のベクトルは、最短ベクトルの長さのおよそ (4/3)^((n-1)/2) 倍以内になる
+// 基底ベクトルが1次独立でない場合は ErrDependent
+func LLL(basis [][]*big.Int, opts ...Option) error {
+	if err := checkBasis(basis); err != nil {
+		return err
+	}
+	c := newConfig(opts)
+	g, err := newGSO(basis)
+	if err != nil {
+		return err
+	}
+	g.lll(c.delta)
+	return nil
+}
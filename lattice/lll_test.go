@@ -0,0 +1,298 @@
+package lattice
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func toBasis(rows [][]int64) [][]*big.Int {
+	b := make([][]*big.Int, len(rows))
+	for i, row := range rows {
+		b[i] = make([]*big.Int, len(row))
+		for j, x := range row {
+			b[i][j] = big.NewInt(x)
+		}
+	}
+	return b
+}
+
+func copyBasis(b [][]*big.Int) [][]*big.Int {
+	c := make([][]*big.Int, len(b))
+	for i, row := range b {
+		c[i] = make([]*big.Int, len(row))
+		for j, x := range row {
+			c[i][j] = new(big.Int).Set(x)
+		}
+	}
+	return c
+}
+
+// randomBasis() : 成分が bits ビットの乱数の n 次正方行列 (ほぼ確実に1次独立)
+func randomBasis(n, bits int, seed int64) [][]*big.Int {
+	r := rand.New(rand.NewSource(seed))
+	max := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	b := make([][]*big.Int, n)
+	for i := range b {
+		b[i] = make([]*big.Int, n)
+		for j := range b[i] {
+			b[i][j] = new(big.Int).Rand(r, max)
+		}
+	}
+	return b
+}
+
+// subsetSumBasis() : Σ x_i a_i == S となる 0/1 のベクトル x を、短いベクトル (x, 0) として含む基底 (Lagarias-Odlyzko)
+func subsetSumBasis(n, bits int, seed int64) (basis [][]*big.Int, x []int64) {
+	r := rand.New(rand.NewSource(seed))
+	max := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	K := big.NewInt(int64(n))
+	S := new(big.Int)
+	x = make([]int64, n)
+	basis = make([][]*big.Int, n+1)
+	for i := 0; i <= n; i++ {
+		basis[i] = make([]*big.Int, n+1)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Int)
+		}
+	}
+	for i := 0; i < n; i++ {
+		a := new(big.Int).Rand(r, max)
+		x[i] = r.Int63n(2)
+		if x[i] == 1 {
+			S.Add(S, a)
+		}
+		basis[i][i].SetInt64(1)
+		basis[i][n].Mul(a, K)
+	}
+	basis[n][n].Mul(S, K)
+	return basis, x
+}
+
+func norm2(v []*big.Int) *big.Int {
+	return dot(v, v)
+}
+
+// gramSchmidt() : 有理数で Gram-Schmidt 直交化して、μ_ij と |b*_i|^2 を求める
+func gramSchmidt(b [][]*big.Int) (mu [][]*big.Rat, B []*big.Rat) {
+	n := len(b)
+	star := make([][]*big.Rat, n)
+	mu = make([][]*big.Rat, n)
+	B = make([]*big.Rat, n)
+	for i := 0; i < n; i++ {
+		star[i] = make([]*big.Rat, len(b[i]))
+		for k, x := range b[i] {
+			star[i][k] = new(big.Rat).SetInt(x)
+		}
+		mu[i] = make([]*big.Rat, i)
+		for j := 0; j < i; j++ {
+			num := new(big.Rat)
+			for k, x := range b[i] {
+				num.Add(num, new(big.Rat).Mul(new(big.Rat).SetInt(x), star[j][k]))
+			}
+			mu[i][j] = num.Quo(num, B[j])
+			for k := range star[i] {
+				star[i][k].Sub(star[i][k], new(big.Rat).Mul(mu[i][j], star[j][k]))
+			}
+		}
+		B[i] = new(big.Rat)
+		for _, x := range star[i] {
+			B[i].Add(B[i], new(big.Rat).Mul(x, x))
+		}
+	}
+	return mu, B
+}
+
+// checkReduced() : |μ_ij| <= 1/2 と Lovász 条件を満たすか
+func checkReduced(b [][]*big.Int, delta *big.Rat) error {
+	mu, B := gramSchmidt(b)
+	half := big.NewRat(1, 2)
+	for i := range mu {
+		for j, m := range mu[i] {
+			if new(big.Rat).Abs(m).Cmp(half) > 0 {
+				return fmt.Errorf("|μ_%d%d| = %v > 1/2", i, j, new(big.Rat).Abs(m).FloatString(3))
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		// |b*_i|^2 >= (δ - μ^2) |b*_(i-1)|^2
+		rhs := new(big.Rat).Sub(delta, new(big.Rat).Mul(mu[i][i-1], mu[i][i-1]))
+		rhs.Mul(rhs, B[i-1])
+		if B[i].Cmp(rhs) < 0 {
+			return fmt.Errorf("Lovász condition fails at %d", i)
+		}
+	}
+	return nil
+}
+
+// solve() : x*a == v となる有理数のベクトル x を求める (a は正則な正方行列)
+func solve(a [][]*big.Int, v []*big.Int) []*big.Rat {
+	n := len(a)
+	// 転置して a^T x^T = v^T を解く
+	m := make([][]*big.Rat, n)
+	for i := 0; i < n; i++ {
+		m[i] = make([]*big.Rat, n+1)
+		for j := 0; j < n; j++ {
+			m[i][j] = new(big.Rat).SetInt(a[j][i])
+		}
+		m[i][n] = new(big.Rat).SetInt(v[i])
+	}
+	for c := 0; c < n; c++ {
+		p := c
+		for m[p][c].Sign() == 0 {
+			p++
+		}
+		m[c], m[p] = m[p], m[c]
+		for i := 0; i < n; i++ {
+			if i == c || m[i][c].Sign() == 0 {
+				continue
+			}
+			f := new(big.Rat).Quo(m[i][c], m[c][c])
+			for j := c; j <= n; j++ {
+				m[i][j].Sub(m[i][j], new(big.Rat).Mul(f, m[c][j]))
+			}
+		}
+	}
+	x := make([]*big.Rat, n)
+	for i := range x {
+		x[i] = new(big.Rat).Quo(m[i][n], m[i][i])
+	}
+	return x
+}
+
+// checkSameLattice() : 正方行列の基底 got の各行が want の行の整数係数の和で、行列式の絶対値が等しいか
+func checkSameLattice(got, want [][]*big.Int) error {
+	_, B1 := gramSchmidt(got)
+	_, B2 := gramSchmidt(want)
+	det1, det2 := big.NewRat(1, 1), big.NewRat(1, 1)
+	for i := range B1 {
+		det1.Mul(det1, B1[i])
+		det2.Mul(det2, B2[i])
+	}
+	if det1.Cmp(det2) != 0 {
+		return fmt.Errorf("det^2 = %v, want %v", det1, det2)
+	}
+	for i, v := range got {
+		for j, x := range solve(want, v) {
+			if !x.IsInt() {
+				return fmt.Errorf("row %d has coefficient %v for basis vector %d", i, x, j)
+			}
+		}
+	}
+	return nil
+}
+
+func Test_LLL(t *testing.T) {
+	tests := []struct {
+		name        string
+		basis       [][]*big.Int
+		opts        []Option
+		delta       *big.Rat
+		wantFirst2  int64 // 0 なら確認しない
+		wantFirstIs []int64
+	}{
+		{name: "Cohen example", basis: toBasis([][]int64{{1, 1, 1}, {-1, 0, 2}, {3, 5, 6}}), delta: big.NewRat(99, 100), wantFirst2: 1},
+		{name: "delta 3/4", basis: toBasis([][]int64{{1, 1, 1}, {-1, 0, 2}, {3, 5, 6}}), opts: []Option{WithDelta(big.NewRat(3, 4))}, delta: big.NewRat(3, 4), wantFirst2: 1},
+		{name: "invalid delta is ignored", basis: toBasis([][]int64{{1, 1, 1}, {-1, 0, 2}, {3, 5, 6}}), opts: []Option{WithDelta(big.NewRat(1, 5))}, delta: big.NewRat(99, 100), wantFirst2: 1},
+		{name: "already reduced", basis: toBasis([][]int64{{1, 0}, {0, 1}}), delta: big.NewRat(99, 100), wantFirstIs: []int64{1, 0}},
+		{name: "2 dimensions", basis: toBasis([][]int64{{5, 3}, {8, 5}}), delta: big.NewRat(99, 100), wantFirst2: 1},
+		{name: "random 8x8", basis: randomBasis(8, 64, 1), delta: big.NewRat(99, 100)},
+		{name: "random 16x16", basis: randomBasis(16, 64, 2), delta: big.NewRat(99, 100)},
+		{name: "one vector", basis: toBasis([][]int64{{3, 4}}), delta: big.NewRat(99, 100), wantFirst2: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := copyBasis(tt.basis)
+			if err := LLL(tt.basis, tt.opts...); err != nil {
+				t.Fatalf("%v : LLL() err = %v", tt.name, err)
+			}
+			if err := checkReduced(tt.basis, tt.delta); err != nil {
+				t.Errorf("%v : LLL() is not reduced : %v", tt.name, err)
+			}
+			if len(orig) == len(orig[0]) {
+				if err := checkSameLattice(tt.basis, orig); err != nil {
+					t.Errorf("%v : LLL() changed the lattice : %v", tt.name, err)
+				}
+			}
+			if tt.wantFirst2 != 0 && norm2(tt.basis[0]).Int64() != tt.wantFirst2 {
+				t.Errorf("%v : LLL() |b_0|^2 = %v, want %v", tt.name, norm2(tt.basis[0]), tt.wantFirst2)
+			}
+			if tt.wantFirstIs != nil && fmt.Sprint(tt.basis[0]) != fmt.Sprint(tt.wantFirstIs) {
+				t.Errorf("%v : LLL() b_0 = %v, want %v", tt.name, tt.basis[0], tt.wantFirstIs)
+			}
+		})
+	}
+}
+
+func Test_LLL_SubsetSum(t *testing.T) {
+	for seed := int64(0); seed < 4; seed++ {
+		basis, x := subsetSumBasis(12, 120, seed)
+		if err := LLL(basis); err != nil {
+			t.Fatalf("seed = %v : LLL() err = %v", seed, err)
+		}
+
+		found := false
+		for _, row := range basis {
+			if row[len(x)].Sign() != 0 {
+				continue
+			}
+			// (x, 0) か (-x, 0)
+			for _, sign := range []int64{1, -1} {
+				ok := true
+				for i := range x {
+					if row[i].Int64() != sign*x[i] {
+						ok = false
+						break
+					}
+				}
+				found = found || ok
+			}
+		}
+		if !found {
+			t.Errorf("seed = %v : LLL() did not find x = %v", seed, x)
+		}
+	}
+}
+
+func Test_LLL_Error(t *testing.T) {
+	tests := []struct {
+		name    string
+		basis   [][]*big.Int
+		wantErr error
+	}{
+		{name: "empty", basis: nil, wantErr: ErrInvalidBasis},
+		{name: "ragged", basis: toBasis([][]int64{{1, 2}, {3}}), wantErr: ErrInvalidBasis},
+		{name: "nil entry", basis: [][]*big.Int{{big.NewInt(1), nil}}, wantErr: ErrInvalidBasis},
+		{name: "dependent", basis: toBasis([][]int64{{1, 2, 3}, {2, 4, 6}}), wantErr: ErrDependent},
+		{name: "zero vector", basis: toBasis([][]int64{{1, 0}, {0, 0}}), wantErr: ErrDependent},
+		{name: "too many vectors", basis: toBasis([][]int64{{1, 0}, {0, 1}, {1, 1}}), wantErr: ErrDependent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := LLL(tt.basis); !errors.Is(err, tt.wantErr) {
+				t.Errorf("%v : LLL() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err := BKZ(tt.basis, 2); !errors.Is(err, tt.wantErr) {
+				t.Errorf("%v : BKZ() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func BenchmarkLLL(b *testing.B) {
+	for _, n := range []int{10, 20} {
+		basis := randomBasis(n, 256, 1)
+		b.Run(fmt.Sprintf("%dx%d", n, n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := LLL(copyBasis(basis)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package models
+
+import "math/big"
+
+// 生成元Gのスカラー倍算のための事前計算テーブル
+//
+// k を4ビットずつのウィンドウ k = Σ w_i * 16^i に分けると
+// k*G = Σ w_i * (16^i * G) なので、table[i][j-1] = j * 16^i * G を用意しておけば
+// 2倍算なしに、ウィンドウの数だけの加算で k*G が求まる
+// テーブルは ScalarBaseMultP() などを初めて呼んだときに1回だけ作る
+
+// baseTableWindow : ウィンドウのビット幅
+const baseTableWindow = 4
+
+// newBaseTable() : table[i][j-1] = j * 16^i * G (1 <= j <= 15) を作る
+// すべての点は Z == 1 にそろえておく
+func newBaseTable[P any](g pointArithmetic[P], G P, bitLen int) [][]P {
+	const size = 1<<baseTableWindow - 1
+	windows := (bitLen + baseTableWindow - 1) / baseTableWindow
+
+	points := make([]P, windows*size)
+	table := make([][]P, windows)
+	base := G
+	for i := range table {
+		row := points[i*size : (i+1)*size]
+		row[0] = base
+		for j := 1; j < size; j++ {
+			row[j] = g.add(row[j-1], base)
+		}
+		table[i] = row
+
+		// 16^(i+1) * G = 15 * 16^i * G + 16^i * G
+		base = g.add(row[size-1], base)
+	}
+
+	g.normalize(points)
+	return table
+}
+
+// baseMult() : テーブルを使って k*G を求める (kはbig-endian)
+// kはテーブルのビット数に収まるように、位数で割った余りにしておくこと
+func baseMult[P any](g pointArithmetic[P], table [][]P, k []byte) P {
+	sum := g.zero()
+	for i := 0; i < 2*len(k) && i < len(table); i++ {
+		b := k[len(k)-1-i/2]
+		w := b >> (baseTableWindow * uint(i%2)) & 0xf
+		if w != 0 {
+			sum = g.add(sum, table[i][w-1])
+		}
+	}
+	return sum
+}
+
+// initBaseTable() : 初めて呼ばれたときに生成元Gのテーブルを作る
+func (ec *EllipticCurve) initBaseTable() {
+	ec.baseTableOnce.Do(func() {
+		if ec.isSecp256k1 {
+			g := secp256k1Arithmetic{}
+			ec.secp256k1BaseTable = newBaseTable[secp256k1Point](g, g.fromAffine(ec.g), ec.order.BitLen())
+			return
+		}
+		g := jacobianArithmetic{ec: ec}
+		ec.jacobianBaseTable = newBaseTable[*jacobianPoint](g, g.fromAffine(ec.g), ec.order.BitLen())
+	})
+}
+
+// scalarBaseMult() : 事前計算テーブルを使って k*G を求める
+// Gの位数が分からない曲線では使えないので、ok == false を返す
+// k を n で割った余りにするのは、secp256k1 と Validate() で n*G == 0 を確かめた曲線だけ
+// それ以外では位数が間違っていても k*G そのものを求めるように、k がテーブルに収まらなければ ok == false を返す
+func (ec *EllipticCurve) scalarBaseMult(k []byte) (p *EllipticCurvePoint, ok bool) {
+	if ec.g == nil || ec.g.IsZero || ec.order == nil || ec.order.Sign() <= 0 {
+		return nil, false
+	}
+	// ScalarMultP() と同じく、Gが曲線上にない場合はテーブルを作る前に panic する
+	panicIfNotOnCurveP(ec, ec.g)
+	ec.initBaseTable()
+
+	if ec.isSecp256k1 {
+		s := scalarFromBytes(k)
+		g := secp256k1Arithmetic{}
+		return g.toAffine(baseMult[secp256k1Point](g, ec.secp256k1BaseTable, s.bytes())), true
+	}
+
+	kk := new(big.Int).SetBytes(k)
+	if ec.orderChecked.Load() {
+		kk.Mod(kk, ec.order)
+	} else if kk.BitLen() > baseTableWindow*len(ec.jacobianBaseTable) {
+		return nil, false
+	}
+	g := jacobianArithmetic{ec: ec}
+	return g.toAffine(baseMult[*jacobianPoint](g, ec.jacobianBaseTable, kk.Bytes())), true
+}
+
+// secp256k1BaseMultConstTime() : テーブルを使って定数時間で k*G を求める
+// 各ウィンドウで15個の点をすべて走査して選び、w == 0 の場合は無限遠点を足す
+func (ec *EllipticCurve) secp256k1BaseMultConstTime(k *scalarVal) *EllipticCurvePoint {
+	ec.initBaseTable()
+
+	r := projectiveZero()
+	for i, row := range ec.secp256k1BaseTable {
+		w := k[i/16] >> (baseTableWindow * (uint(i) % 16)) & 0xf
+
+		t := projectiveZero()
+		for j := range row {
+			e := projectivePoint{x: row[j].x, y: row[j].y, z: fieldVal{1}}
+			t.cmov(&e, ctEqual(uint64(j+1), w))
+		}
+		r = completeAdd(&r, &t)
+	}
+
+	return r.toAffine()
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func Test_newBaseTable(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "secp256k1", ec: testSecp256k1()},
+		{name: "P-256", ec: testP256()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := tt.ec
+			ec.initBaseTable()
+
+			var entry func(i, j int) *EllipticCurvePoint
+			var windows int
+			if ec.isSecp256k1 {
+				g := secp256k1Arithmetic{}
+				windows = len(ec.secp256k1BaseTable)
+				entry = func(i, j int) *EllipticCurvePoint {
+					p := ec.secp256k1BaseTable[i][j-1]
+					if !p.z.isOne() {
+						t.Fatalf("%v : table[%v][%v] is not normalized", tt.name, i, j-1)
+					}
+					return g.toAffine(p)
+				}
+			} else {
+				g := jacobianArithmetic{ec: ec}
+				windows = len(ec.jacobianBaseTable)
+				entry = func(i, j int) *EllipticCurvePoint {
+					return g.toAffine(ec.jacobianBaseTable[i][j-1])
+				}
+			}
+
+			if want := (ec.order.BitLen() + 3) / 4; windows != want {
+				t.Fatalf("%v : len(table) = %v, want %v", tt.name, windows, want)
+			}
+
+			// table[i][j-1] = j * 16^i * G を通常のスカラー倍算と比べる
+			for _, i := range []int{0, 1, 2, windows / 2, windows - 1} {
+				for j := 1; j < 16; j++ {
+					k := new(big.Int).Lsh(big.NewInt(int64(j)), uint(4*i))
+					want := ec.ScalarMultP(ec.g, k.Bytes())
+					if got := entry(i, j); !got.equals(want) {
+						t.Errorf("%v : table[%v][%v] = %v, want %v", tt.name, i, j-1, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_ScalarBaseMultP(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "secp256k1", ec: testSecp256k1()},
+		{name: "P-256", ec: testP256()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.ec.order
+			scalars := [][]byte{
+				{},
+				{0x00},
+				{0x01},
+				{0x10},
+				make([]byte, 32),
+				new(big.Int).Sub(n, big.NewInt(1)).Bytes(),
+				n.Bytes(),
+				new(big.Int).Add(n, big.NewInt(5)).Bytes(),
+				new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)).Bytes(),
+			}
+			r := rand.New(rand.NewSource(4))
+			for i := 0; i < 8; i++ {
+				scalars = append(scalars, new(big.Int).Rand(r, n).Bytes())
+			}
+
+			for _, k := range scalars {
+				want := tt.ec.ScalarMultP(tt.ec.g, k)
+				if got := tt.ec.ScalarBaseMultP(k); !got.equals(want) {
+					t.Errorf("%v : ScalarBaseMultP(%x) = %v, want %v", tt.name, k, got, want)
+				}
+				if got := tt.ec.ScalarBaseMultConstTimeP(k); !got.equals(want) {
+					t.Errorf("%v : ScalarBaseMultConstTimeP(%x) = %v, want %v", tt.name, k, got, want)
+				}
+			}
+		})
+	}
+}
+
+// Test_EllipticCurve_ScalarBaseMultP_Panics : 曲線上にないGではテーブルを作らずに panic する
+func Test_EllipticCurve_ScalarBaseMultP_Panics(t *testing.T) {
+	for _, ec := range []*EllipticCurve{testSecp256k1(), testP256()} {
+		g := NewEllipticCurvePoint(ec.g.X, NewFiniteField(new(big.Int).Add(ec.g.Y.Value, big.NewInt(1)), ec.prime), false)
+		bad := NewEllipticCurve(ec.a, ec.b, ec.prime, g, ec.bitSize, ec.name, ec.order)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v : ScalarBaseMultP() with a generator not on the curve did not panic", ec.name)
+				}
+			}()
+			bad.ScalarBaseMultP([]byte{1})
+		}()
+	}
+}
+
+// Test_EllipticCurve_ScalarBaseMultP_Order : 位数を確かめていない曲線では、k を位数で割らずに k*G そのものを求める
+func Test_EllipticCurve_ScalarBaseMultP_Order(t *testing.T) {
+	p256 := testP256()
+	n := p256.order
+	// 位数を間違えた P-256
+	wrong := NewEllipticCurve(p256.a, p256.b, p256.prime, p256.g, p256.bitSize, p256.name, new(big.Int).Sub(n, big.NewInt(2)))
+	// 位数を確かめた P-256
+	validated := testP256()
+	if report := validated.Validate(); !report.OK() {
+		t.Fatalf("Validate() = %v", report.Failed())
+	}
+
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+		k    *big.Int
+		want *big.Int // want*G になる
+	}{
+		{name: "wrong order", ec: wrong, k: new(big.Int).Sub(n, big.NewInt(2)), want: new(big.Int).Sub(n, big.NewInt(2))},
+		{name: "wrong order, k = n", ec: wrong, k: n, want: big.NewInt(0)},
+		{name: "k larger than the table", ec: testP256(), k: new(big.Int).Add(new(big.Int).Lsh(n, 40), big.NewInt(3)), want: big.NewInt(3)},
+		{name: "validated", ec: validated, k: new(big.Int).Add(n, big.NewInt(5)), want: big.NewInt(5)},
+		{name: "validated, k larger than the table", ec: validated, k: new(big.Int).Add(new(big.Int).Lsh(n, 40), big.NewInt(3)), want: big.NewInt(3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := jacobianArithmetic{ec: p256}
+			want := j.toAffine(scalarMult[*jacobianPoint](j, j.fromAffine(p256.g), tt.want.Bytes()))
+			if got := tt.ec.ScalarBaseMultP(tt.k.Bytes()); !got.equals(want) {
+				t.Errorf("%v : ScalarBaseMultP(%x) = %v, want %v", tt.name, tt.k, got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkEllipticCurve_ScalarBaseMultP(b *testing.B) {
+	ec := testSecp256k1()
+	k := testHex("83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8").Bytes()
+	ec.initBaseTable()
+
+	b.Run("table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ec.ScalarBaseMultP(k)
+		}
+	})
+	b.Run("table constant time", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ec.ScalarBaseMultConstTimeP(k)
+		}
+	})
+	b.Run("no table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ec.ScalarMultP(ec.g, k)
+		}
+	})
+	b.Run("no table constant time", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ec.ScalarMultConstTimeP(ec.g, k)
+		}
+	})
+	b.Run("build table", func(b *testing.B) {
+		g := secp256k1Arithmetic{}
+		for i := 0; i < b.N; i++ {
+			newBaseTable[secp256k1Point](g, g.fromAffine(ec.g), 256)
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package models
+
+import "math/big"
+
+// 秘密のスカラー (秘密鍵・nonce) によるスカラー倍算
+//
+// ScalarMultP() は k のビットごとに加算するかどうかを分岐するので、
+// 実行時間から k が漏れる。秘密のスカラーを使う場合はこちらを使うこと
+//
+// secp256k1 の場合は fieldVal と完全な加算公式 (Renes-Costello-Batina 2016) を使い、
+// 4ビットの固定ウィンドウ法で、テーブルの参照も全要素を走査して行うので、
+// k によって分岐もメモリアクセスも変わらない
+//
+// それ以外の曲線では Montgomery ladder で常に同じ順序の加算・2倍算を行うが、
+// 秘密のビットによる添字アクセスや加算の分岐が残り、FiniteField (big.Int) の演算も定数時間ではないので、
+// あくまで緩和策で、定数時間ではない
+
+// projectivePoint : fieldVal で表した斉次射影座標の点 (secp256k1 専用)
+// アフィン座標では (X / Z, Y / Z) を表し、無限遠点は (0 : 1 : 0)
+// 完全な加算公式は無限遠点や P == Q を特別扱いしなくてよいので、分岐がない
+type projectivePoint struct {
+	x, y, z fieldVal
+}
+
+// 3 * b (secp256k1 では b = 7)
+var fieldB3 = fieldVal{21}
+
+func projectiveZero() projectivePoint {
+	return projectivePoint{y: fieldVal{1}}
+}
+
+// cmov() : cond == 1 なら p = q (分岐しない)
+func (p *projectivePoint) cmov(q *projectivePoint, cond uint64) {
+	p.x.cmov(&q.x, cond)
+	p.y.cmov(&q.y, cond)
+	p.z.cmov(&q.z, cond)
+}
+
+// completeAdd() : P + Q を求める (a = 0 の完全な加算公式, RCB16 Algorithm 7)
+// (https://eprint.iacr.org/2015/1060)
+func completeAdd(p, q *projectivePoint) projectivePoint {
+	var t0, t1, t2, t3, t4 fieldVal
+	var r projectivePoint
+
+	t0.mul(&p.x, &q.x)
+	t1.mul(&p.y, &q.y)
+	t2.mul(&p.z, &q.z)
+	t3.add(&p.x, &p.y)
+	t4.add(&q.x, &q.y)
+	t3.mul(&t3, &t4)
+	t4.add(&t0, &t1)
+	t3.sub(&t3, &t4)
+	t4.add(&p.y, &p.z)
+	r.x.add(&q.y, &q.z)
+	t4.mul(&t4, &r.x)
+	r.x.add(&t1, &t2)
+	t4.sub(&t4, &r.x)
+	r.x.add(&p.x, &p.z)
+	r.y.add(&q.x, &q.z)
+	r.x.mul(&r.x, &r.y)
+	r.y.add(&t0, &t2)
+	r.y.sub(&r.x, &r.y)
+	r.x.add(&t0, &t0)
+	t0.add(&r.x, &t0)
+	t2.mul(&fieldB3, &t2)
+	r.z.add(&t1, &t2)
+	t1.sub(&t1, &t2)
+	r.y.mul(&fieldB3, &r.y)
+	r.x.mul(&t4, &r.y)
+	t2.mul(&t3, &t1)
+	r.x.sub(&t2, &r.x)
+	r.y.mul(&r.y, &t0)
+	t1.mul(&t1, &r.z)
+	r.y.add(&t1, &r.y)
+	t0.mul(&t0, &t3)
+	r.z.mul(&r.z, &t4)
+	r.z.add(&r.z, &t0)
+
+	return r
+}
+
+// completeDouble() : 2P を求める (a = 0 の完全な2倍算公式, RCB16 Algorithm 9)
+func completeDouble(p *projectivePoint) projectivePoint {
+	var t0, t1, t2 fieldVal
+	var r projectivePoint
+
+	t0.square(&p.y)
+	r.z.add(&t0, &t0)
+	r.z.add(&r.z, &r.z)
+	r.z.add(&r.z, &r.z)
+	t1.mul(&p.y, &p.z)
+	t2.square(&p.z)
+	t2.mul(&fieldB3, &t2)
+	r.x.mul(&t2, &r.z)
+	r.y.add(&t0, &t2)
+	r.z.mul(&t1, &r.z)
+	t1.add(&t2, &t2)
+	t2.add(&t1, &t2)
+	t0.sub(&t0, &t2)
+	r.y.mul(&t0, &r.y)
+	r.y.add(&r.x, &r.y)
+	t1.mul(&p.x, &p.y)
+	r.x.mul(&t0, &t1)
+	r.x.add(&r.x, &r.x)
+
+	return r
+}
+
+// ctEqual() : a == b なら 1、そうでなければ 0 (分岐しない)
+func ctEqual(a, b uint64) uint64 {
+	x := a ^ b
+	return 1 ^ ((x | -x) >> 63)
+}
+
+// scalarFromBytes() : big-endian の k を N で割った余りにする
+// 32バイト以下であれば big.Int を使わない
+func scalarFromBytes(k []byte) scalarVal {
+	var s scalarVal
+	if len(k) > 32 {
+		s.setBig(new(big.Int).SetBytes(k))
+		return s
+	}
+
+	var b [32]byte
+	copy(b[32-len(k):], k)
+	s.setBytes(&b)
+	return s
+}
+
+// secp256k1ScalarMultConstTime() : 4ビットの固定ウィンドウ法で k*P を求める
+// table[i] = i*P を用意し、上位から4ビットずつ「4回2倍して table[w] を足す」を64回繰り返す
+func secp256k1ScalarMultConstTime(p *EllipticCurvePoint, k *scalarVal) *EllipticCurvePoint {
+	var base projectivePoint
+	base.x.setBig(p.X.Value)
+	base.y.setBig(p.Y.Value)
+	base.z.setInt(1)
+
+	var table [16]projectivePoint
+	table[0] = projectiveZero()
+	table[1] = base
+	for i := 2; i < len(table); i++ {
+		table[i] = completeAdd(&table[i-1], &base)
+	}
+
+	r := projectiveZero()
+	for i := 63; i >= 0; i-- {
+		for j := 0; j < 4; j++ {
+			r = completeDouble(&r)
+		}
+
+		w := k[i/16] >> (4 * (uint(i) % 16)) & 0xf
+		var t projectivePoint
+		for j := range table {
+			t.cmov(&table[j], ctEqual(uint64(j), w))
+		}
+		r = completeAdd(&r, &t)
+	}
+
+	return r.toAffine()
+}
+
+// toAffine() : (X : Y : Z) -> (X / Z, Y / Z)
+func (p *projectivePoint) toAffine() *EllipticCurvePoint {
+	if p.z.isZero() {
+		return NewEllipticCurvePoint(nil, nil, true)
+	}
+
+	var zInv, x, y fieldVal
+	zInv.inverse(&p.z)
+	x.mul(&p.x, &zInv)
+	y.mul(&p.y, &zInv)
+
+	return NewEllipticCurvePoint(
+		NewFiniteField(x.toBig(), secp256k1Prime),
+		NewFiniteField(y.toBig(), secp256k1Prime),
+		false,
+	)
+}
+
+// montgomeryLadder() : 常に「加算1回と2倍算1回」を繰り返して k*P を求める
+// R0 = 0, R1 = P として、ビットが0なら (R0, R1) = (2R0, R0+R1)、1なら (R0+R1, 2R1)
+// kの長さは位数のバイト長に揃えて、ループの回数もkの値によらないようにする
+// 揃うのは演算の回数と順序だけで、定数時間ではない
+// r[bit] の参照はkのビットによってメモリアクセスが変わり、jacobianArithmetic の add(), double() も
+// 無限遠点や Z == 1 で分岐し、FiniteField (big.Int) の演算も値によって時間が変わる
+func (ec *EllipticCurve) montgomeryLadder(p *EllipticCurvePoint, k []byte) *EllipticCurvePoint {
+	if ec.order != nil {
+		if size := (ec.order.BitLen() + 7) / 8; len(k) < size {
+			padded := make([]byte, size)
+			copy(padded[size-len(k):], k)
+			k = padded
+		}
+	}
+
+	g := jacobianArithmetic{ec: ec}
+	r := [2]*jacobianPoint{g.zero(), g.fromAffine(p)}
+	for _, b := range k {
+		for i := 7; i >= 0; i-- {
+			bit := b >> uint(i) & 1
+			sum := g.add(r[0], r[1])
+			r[bit] = g.double(r[bit])
+			r[1^bit] = sum
+		}
+	}
+
+	return g.toAffine(r[0])
+}
+
+// ScalarMultConstTimeP() : 秘密のスカラーkを使って k*P を求める (kはbig-endian)
+// 秘密鍵から公開鍵を求める場合や、署名・鍵共有ではこちらを使う
+// kの長さも漏れないように、位数のバイト長に揃えて渡すこと
+// 定数時間になるのは secp256k1 の場合だけで、それ以外の曲線は montgomeryLadder() による緩和策にすぎず、定数時間ではない
+func (ec *EllipticCurve) ScalarMultConstTimeP(p *EllipticCurvePoint, k []byte) *EllipticCurvePoint {
+	return mustPoint(ec.ScalarMultConstTimePChecked(p, k))
+}
+
+// ScalarMultConstTimePChecked() : ScalarMultConstTimeP() と同じだが、点が曲線上にない場合は panic する代わりにエラーを返す
+// 鍵共有で相手から受け取った公開鍵に使う
+// ScalarMultConstTimeP() と同じく、定数時間になるのは secp256k1 の場合だけ
+func (ec *EllipticCurve) ScalarMultConstTimePChecked(p *EllipticCurvePoint, k []byte) (*EllipticCurvePoint, error) {
+	if err := ec.checkPoint(p); err != nil {
+		return nil, err
+	}
+
+	if p.IsZero {
+		return new(EllipticCurvePoint).deepCopy(p), nil
+	}
+
+	if ec.isSecp256k1 {
+		s := scalarFromBytes(k)
+		return secp256k1ScalarMultConstTime(p, &s), nil
+	}
+	return ec.montgomeryLadder(p, k), nil
+}
+
+// ScalarBaseMultConstTimeP() : 秘密のスカラーkを使って k*G を求める (kはbig-endian)
+// secp256k1 の場合は事前計算テーブルを使う
+// ScalarMultConstTimeP() と同じく、定数時間になるのは secp256k1 の場合だけ
+func (ec *EllipticCurve) ScalarBaseMultConstTimeP(k []byte) *EllipticCurvePoint {
+	if ec.isSecp256k1 {
+		panicIfNotOnCurveP(ec, ec.g)
+		s := scalarFromBytes(k)
+		return ec.secp256k1BaseMultConstTime(&s)
+	}
+	return ec.ScalarMultConstTimeP(ec.g, k)
+}
+
+// ScalarMulAddConstTime() : 秘密のスカラーを含む a*b + c mod N を求める (a, b, c はbig-endian)
+// BIP340 の署名 s = k + e*d や、秘密のスカラーの符号の反転 -d = d*(N-1) の計算に使う
+// secp256k1 の場合は scalarVal で分岐なしに計算する
+// それ以外の曲線では big.Int の Mul と Mod で計算するので、定数時間ではない
+func (ec *EllipticCurve) ScalarMulAddConstTime(a, b, c []byte) *big.Int {
+	if ec.isSecp256k1 {
+		sa, sb, sc := scalarFromBytes(a), scalarFromBytes(b), scalarFromBytes(c)
+		var s scalarVal
+		s.mul(&sa, &sb)
+		return s.add(&s, &sc).toBig()
+	}
+
+	s := new(big.Int).Mul(new(big.Int).SetBytes(a), new(big.Int).SetBytes(b))
+	s.Add(s, new(big.Int).SetBytes(c))
+	return s.Mod(s, ec.order)
+}
+
+// ScalarMulAddDivConstTime() : 秘密のスカラーを含む (a*b + c) / d mod N を求める (a, b, c, d はbig-endian)
+// ECDSA の署名 s = (r*秘密鍵 + z) / k の計算に使う。d == 0 (mod N) のときは 0 を返す
+// secp256k1 の場合は scalarVal で分岐なしに計算する
+// それ以外の曲線では big.Int の Mul と ModInverse で計算するので、定数時間ではない
+func (ec *EllipticCurve) ScalarMulAddDivConstTime(a, b, c, d []byte) *big.Int {
+	if ec.isSecp256k1 {
+		sa, sb, sc, sd := scalarFromBytes(a), scalarFromBytes(b), scalarFromBytes(c), scalarFromBytes(d)
+		var s scalarVal
+		s.mul(&sa, &sb)
+		s.add(&s, &sc)
+		sd.inverse(&sd)
+		return s.mul(&s, &sd).toBig()
+	}
+
+	n := ec.order
+	s := new(big.Int).Mul(new(big.Int).SetBytes(a), new(big.Int).SetBytes(b))
+	s.Add(s, new(big.Int).SetBytes(c))
+	inv := new(big.Int).ModInverse(new(big.Int).Mod(new(big.Int).SetBytes(d), n), n)
+	if inv == nil {
+		return new(big.Int)
+	}
+	s.Mul(s, inv)
+	return s.Mod(s, n)
+}
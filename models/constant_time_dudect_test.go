@@ -0,0 +1,47 @@
+//go:build dudect
+
+// 実行時間の統計的な検定は時間がかかり、計測環境の影響も受けるので、
+// 通常のテストでは実行しない
+//
+//	go test -tags dudect -run Dudect -v ./models
+
+package models
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/internal/dudect"
+)
+
+// dudectScalars() : クラス0は固定のスカラー、クラス1は一様乱数のスカラー
+func dudectScalars(class int) []byte {
+	k := make([]byte, 32)
+	if class == 0 {
+		// ビットがほとんど0の値は、分岐する実装との差が最も出やすい
+		k[31] = 0x01
+		return k
+	}
+	if _, err := rand.Read(k); err != nil {
+		panic(err)
+	}
+	return k
+}
+
+func Test_ScalarBaseMultConstTimeP_Dudect(t *testing.T) {
+	ec := testSecp256k1()
+
+	got := dudect.Test(20000, 1, dudectScalars, func(k []byte) {
+		ec.ScalarBaseMultConstTimeP(k)
+	})
+	t.Logf("ScalarBaseMultConstTimeP : t = %.2f (%d samples)", got.T, got.Samples)
+	if got.Leaks() {
+		t.Errorf("ScalarBaseMultConstTimeP : |t| = %.2f > %v, timing depends on the scalar", got.T, dudect.Threshold)
+	}
+
+	// 比較のため、分岐する ScalarBaseMultP() の t 値も表示する
+	ref := dudect.Test(20000, 1, dudectScalars, func(k []byte) {
+		ec.ScalarBaseMultP(k)
+	})
+	t.Logf("ScalarBaseMultP (variable time) : t = %.2f (%d samples)", ref.T, ref.Samples)
+}
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func Test_completeAdd(t *testing.T) {
+	ec := testSecp256k1()
+	g := secp256k1Arithmetic{}
+
+	toProjective := func(p *EllipticCurvePoint, z uint64) projectivePoint {
+		if p.IsZero {
+			return projectivePoint{y: fieldVal{z}}
+		}
+		// (x, y) -> (x*Z : y*Z : Z)
+		var r projectivePoint
+		r.z.setInt(z)
+		r.x.mul(r.x.setBig(p.X.Value), &r.z)
+		r.y.mul(r.y.setBig(p.Y.Value), &r.z)
+		return r
+	}
+
+	P := ec.g
+	Q := ec.ScalarBaseMultP([]byte{0x05})
+	points := []*EllipticCurvePoint{
+		NewEllipticCurvePoint(nil, nil, true),
+		P,
+		Q,
+		g.toAffine(g.neg(g.fromAffine(P))),
+	}
+
+	for _, p := range points {
+		want := ec.AddP(p, p)
+		pp := toProjective(p, 3)
+		if got := completeDouble(&pp); !got.toAffine().equals(want) {
+			t.Errorf("completeDouble(%v) = %v, want %v", p, got.toAffine(), want)
+		}
+
+		for _, q := range points {
+			want := ec.AddP(p, q)
+			qq := toProjective(q, 7)
+			if got := completeAdd(&pp, &qq); !got.toAffine().equals(want) {
+				t.Errorf("completeAdd(%v, %v) = %v, want %v", p, q, got.toAffine(), want)
+			}
+		}
+	}
+}
+
+func Test_EllipticCurve_ScalarMultConstTimeP(t *testing.T) {
+	secp256k1 := testSecp256k1()
+	n := secp256k1.order
+
+	r := rand.New(rand.NewSource(3))
+	scalars := [][]byte{
+		{},
+		{0x00},
+		{0x01},
+		make([]byte, 32),
+		new(big.Int).Sub(n, big.NewInt(1)).Bytes(),
+		n.Bytes(),
+		new(big.Int).Add(n, big.NewInt(1)).Bytes(),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)).Bytes(),
+		append([]byte{0x01}, make([]byte, 32)...), // 2^256
+	}
+	for i := 0; i < 8; i++ {
+		scalars = append(scalars, new(big.Int).Rand(r, n).Bytes())
+	}
+
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "secp256k1", ec: secp256k1},
+		{name: "P-256", ec: testP256()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			P := tt.ec.ScalarBaseMultP([]byte{0x07})
+			for _, k := range scalars {
+				if got, want := tt.ec.ScalarMultConstTimeP(P, k), tt.ec.ScalarMultP(P, k); !got.equals(want) {
+					t.Errorf("%v : ScalarMultConstTimeP(P, %x) = %v, want %v", tt.name, k, got, want)
+				}
+				if got, want := tt.ec.ScalarBaseMultConstTimeP(k), tt.ec.ScalarBaseMultP(k); !got.equals(want) {
+					t.Errorf("%v : ScalarBaseMultConstTimeP(%x) = %v, want %v", tt.name, k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_ScalarMultConstTimeP_ToyCurve(t *testing.T) {
+	prime := big.NewInt(223)
+	ec := NewEllipticCurve(
+		NewFiniteField(big.NewInt(0), prime),
+		NewFiniteField(big.NewInt(7), prime),
+		prime,
+		nil,
+		0,
+		"test elliptic curve",
+		nil,
+	)
+
+	for _, p := range allPoints(ec) {
+		for k := 0; k < 300; k += 7 {
+			kb := big.NewInt(int64(k)).Bytes()
+			if got, want := ec.ScalarMultConstTimeP(p, kb), ec.ScalarMultP(p, kb); !got.equals(want) {
+				t.Fatalf("ScalarMultConstTimeP(%v, %v) = %v, want %v", p, k, got, want)
+			}
+		}
+	}
+}
+
+func Test_EllipticCurve_ScalarMulAddConstTime(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "secp256k1", ec: testSecp256k1()},
+		{name: "P-256", ec: testP256()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.ec.order
+			// N 以上の32バイトの値 (BIP340 のハッシュ) もそのまま渡せる
+			max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+			values := append(testFieldValues(n, 6), n, max)
+			for _, a := range values {
+				for _, b := range values {
+					c := new(big.Int).Xor(a, b)
+
+					want := new(big.Int).Mul(a, b)
+					want.Add(want, c)
+					want.Mod(want, n)
+					got := tt.ec.ScalarMulAddConstTime(a.Bytes(), b.Bytes(), c.Bytes())
+					if got.Cmp(want) != 0 {
+						t.Fatalf("%v : ScalarMulAddConstTime(%x, %x, %x) = %x, want %x", tt.name, a, b, c, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_ScalarMulAddDivConstTime(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "secp256k1", ec: testSecp256k1()},
+		{name: "P-256", ec: testP256()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.ec.order
+			values := testFieldValues(n, 6)
+			for _, a := range values {
+				for _, d := range values {
+					// b, c は a, d から作る
+					b := new(big.Int).Rsh(d, 3)
+					c := new(big.Int).Xor(a, d)
+					c.Mod(c, n)
+
+					want := new(big.Int)
+					if inv := new(big.Int).ModInverse(d, n); inv != nil {
+						want.Mul(a, b)
+						want.Add(want, c)
+						want.Mul(want, inv)
+						want.Mod(want, n)
+					}
+					got := tt.ec.ScalarMulAddDivConstTime(a.Bytes(), b.Bytes(), c.Bytes(), d.Bytes())
+					if got.Cmp(want) != 0 {
+						t.Fatalf("%v : ScalarMulAddDivConstTime(%x, %x, %x, %x) = %x, want %x", tt.name, a, b, c, d, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEllipticCurve_ScalarMultConstTimeP(b *testing.B) {
+	ec := testSecp256k1()
+	k := testHex("83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8").Bytes()
+
+	b.Run("secp256k1", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ec.ScalarMultConstTimeP(ec.g, k)
+		}
+	})
+
+	p256 := testP256()
+	b.Run("P-256", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p256.ScalarMultConstTimeP(p256.g, k)
+		}
+	})
+}
@@ -2,10 +2,16 @@ package models
 
 import (
 	"crypto/elliptic"
+	"errors"
+	"fmt"
 	"math/big"
-	"math/bits"
+	"sync"
+	"sync/atomic"
 )
 
+// ErrNotOnCurve : 点が曲線上にない (座標が範囲外の場合も含む)
+var ErrNotOnCurve = errors.New("models: point is not on the curve")
+
 type EllipticCurvePoint struct {
 	X      *FiniteField
 	Y      *FiniteField
@@ -59,15 +65,54 @@ func ToEllipticCurvePoint(x, y, prime *big.Int) *EllipticCurvePoint {
 }
 
 func NewEllipticCurvePoint(x, y *FiniteField, isZero bool) *EllipticCurvePoint {
-	if !isZero && x.Prime.Cmp(y.Prime) != 0 {
-		panic("NewEllipticCurvePoint(): the primes of x and y are not same")
+	p, err := NewEllipticCurvePointChecked(x, y, isZero)
+	if err != nil {
+		panic(fmt.Sprintf("NewEllipticCurvePoint(): %v", err))
+	}
+	return p
+}
+
+// NewEllipticCurvePointChecked() : NewEllipticCurvePoint() と同じだが、panic する代わりにエラーを返す
+// x, y の法が異なる場合は ErrPrimeMismatch、座標がない場合は ErrNotOnCurve
+func NewEllipticCurvePointChecked(x, y *FiniteField, isZero bool) (*EllipticCurvePoint, error) {
+	if !isZero {
+		if x == nil || y == nil {
+			return nil, fmt.Errorf("%w: missing coordinate", ErrNotOnCurve)
+		}
+		if err := checkPrimes(x, y); err != nil {
+			return nil, err
+		}
 	}
 
 	return &EllipticCurvePoint{
 		X:      x,
 		Y:      y,
 		IsZero: isZero,
+	}, nil
+}
+
+// fromPoint() : 点を座標の組に変換する (無限遠点は (0, 0))
+func fromPoint(p *EllipticCurvePoint) (*big.Int, *big.Int) {
+	if p.IsZero {
+		return new(big.Int), new(big.Int)
+	}
+	return p.X.Value, p.Y.Value
+}
+
+// mustPoint() : err が nil でなければ panic する
+func mustPoint(p *EllipticCurvePoint, err error) *EllipticCurvePoint {
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// mustCoordinates() : err が nil でなければ panic する
+func mustCoordinates(x, y *big.Int, err error) (*big.Int, *big.Int) {
+	if err != nil {
+		panic(err)
 	}
+	return x, y
 }
 
 type EllipticCurve struct {
@@ -75,31 +120,99 @@ type EllipticCurve struct {
 	b       *FiniteField
 	prime   *big.Int
 	g       *EllipticCurvePoint
-	bigSize int
+	bitSize int
 	name    string
 	order   *big.Int // 位数
+
+	// Validate() で n*G が無限遠点になることを確かめたかどうか
+	// 確かめた曲線だけ、ScalarBaseMultP() で k を n で割った余りにしてよい
+	orderChecked atomic.Bool
+
+	// secp256k1 の場合は FiniteField の代わりに fieldVal で計算する
+	isSecp256k1 bool
+
+	// GLV法で使う自己準同型写像 (設定されていなければ nil)
+	endomorphism *Endomorphism
+
+	// 生成元Gの事前計算テーブル (base_table.go)
+	baseTableOnce      sync.Once
+	secp256k1BaseTable [][]secp256k1Point
+	jacobianBaseTable  [][]*jacobianPoint
 }
 
 func panicIfNotOnCurveP(ec *EllipticCurve, p *EllipticCurvePoint) {
-	if p.IsZero {
-		return
+	if err := ec.checkPoint(p); err != nil {
+		panic(fmt.Sprintf("attempted operation on invalid point: %v", err))
 	}
+}
 
+// checkPoint() : 点がこの曲線上の点 (または無限遠点) かどうかを確認する
+// 座標の法が曲線と異なる場合は ErrPrimeMismatch、
+// 座標がない・[0, p) の範囲外・曲線の方程式を満たさない場合は ErrNotOnCurve を返す
+func (ec *EllipticCurve) checkPoint(p *EllipticCurvePoint) error {
+	if p == nil {
+		return fmt.Errorf("%w: nil point", ErrNotOnCurve)
+	}
+	if p.IsZero {
+		return nil
+	}
+	if p.X == nil || p.Y == nil || p.X.Value == nil || p.Y.Value == nil || p.X.Prime == nil || p.Y.Prime == nil {
+		return fmt.Errorf("%w: missing coordinate", ErrNotOnCurve)
+	}
+	if p.X.Prime.Cmp(ec.prime) != 0 || p.Y.Prime.Cmp(ec.prime) != 0 {
+		return fmt.Errorf("%w: the coordinates are not in F_%v", ErrPrimeMismatch, ec.prime)
+	}
+	if !ec.inField(p.X.Value) || !ec.inField(p.Y.Value) {
+		return fmt.Errorf("%w: coordinate out of range", ErrNotOnCurve)
+	}
 	if !ec.IsOnCurveP(p) {
-		panic("attempted operation on invalid point")
+		return ErrNotOnCurve
+	}
+	return nil
+}
+
+// inField() : 0 <= v < p かどうか
+func (ec *EllipticCurve) inField(v *big.Int) bool {
+	return v.Sign() >= 0 && v.Cmp(ec.prime) < 0
+}
+
+// toPointChecked() : 座標の組を点に変換し、曲線上の点であることを確認する
+// (0, 0) は無限遠点として扱う。ToEllipticCurvePoint() と違い、p 以上の座標は p で割らずにエラーにする
+func (ec *EllipticCurve) toPointChecked(x, y *big.Int) (*EllipticCurvePoint, error) {
+	if x == nil || y == nil {
+		return nil, fmt.Errorf("%w: missing coordinate", ErrNotOnCurve)
+	}
+	if !ec.inField(x) || !ec.inField(y) {
+		return nil, fmt.Errorf("%w: coordinate out of range", ErrNotOnCurve)
+	}
+
+	p := ToEllipticCurvePoint(x, y, ec.prime)
+	if err := ec.checkPoint(p); err != nil {
+		return nil, err
 	}
+	return p, nil
 }
 
+// Params() : 曲線のパラメータ
+// 生成元が設定されていない曲線では Gx, Gy は nil になる
 func (ec *EllipticCurve) Params() *elliptic.CurveParams {
-	return &elliptic.CurveParams{
+	params := &elliptic.CurveParams{
 		P:       ec.prime,
 		N:       ec.order,
 		B:       ec.b.Value,
-		Gx:      ec.g.X.Value,
-		Gy:      ec.g.Y.Value,
-		BitSize: ec.bigSize,
+		BitSize: ec.bitSize,
 		Name:    ec.name,
 	}
+	if ec.g != nil && !ec.g.IsZero {
+		params.Gx = ec.g.X.Value
+		params.Gy = ec.g.Y.Value
+	}
+	return params
+}
+
+// A() : 曲線の係数a (elliptic.CurveParams は a = -3 を仮定しているので含まれない)
+func (ec *EllipticCurve) A() *big.Int {
+	return new(big.Int).Set(ec.a.Value)
 }
 
 func (ec *EllipticCurve) IsOnCurve(x, y *big.Int) bool {
@@ -118,22 +231,78 @@ func (ec *EllipticCurve) IsOnCurveP(p *EllipticCurvePoint) bool {
 	return lhs.Equals(rhs)
 }
 
+// LiftX() : x座標とyの偶奇から曲線上の点(x, y)を求める
+// y^2 = x^3 + a*x + b を満たすyが存在しない場合は ok == false を返す
+func (ec *EllipticCurve) LiftX(x *big.Int, odd bool) (p *EllipticCurvePoint, ok bool) {
+	if x.Sign() < 0 || x.Cmp(ec.prime) >= 0 {
+		return nil, false
+	}
+
+	X := NewFiniteField(x, ec.prime)
+
+	// y^2 = (x * x + a) * x + b
+	rhs := new(FiniteField).Mul(X, X)
+	rhs.Add(rhs, ec.a)
+	rhs.Mul(rhs, X)
+	rhs.Add(rhs, ec.b)
+
+	y := new(FiniteField).Sqrt(rhs)
+	if y == nil {
+		return nil, false
+	}
+	// y == 0 のときは偶奇を選べない
+	if y.IsZero() && odd {
+		return nil, false
+	}
+	if (y.Value.Bit(0) == 1) != odd {
+		y.Neg(y)
+	}
+
+	return NewEllipticCurvePoint(X, y, false), true
+}
+
 func (ec *EllipticCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
-	p1 := ToEllipticCurvePoint(x1, y1, ec.prime)
-	p2 := ToEllipticCurvePoint(x2, y2, ec.prime)
-	result := ec.AddP(p1, p2)
-	return result.X.Value, result.Y.Value
+	return mustCoordinates(ec.AddChecked(x1, y1, x2, y2))
+}
+
+// AddChecked() : Add() と同じだが、panic する代わりにエラーを返す (無限遠点は (0, 0))
+func (ec *EllipticCurve) AddChecked(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int, error) {
+	p1, err := ec.toPointChecked(x1, y1)
+	if err != nil {
+		return nil, nil, err
+	}
+	p2, err := ec.toPointChecked(x2, y2)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := ec.AddPChecked(p1, p2)
+	if err != nil {
+		return nil, nil, err
+	}
+	x, y := fromPoint(result)
+	return x, y, nil
 }
 
 func (ec *EllipticCurve) AddP(p1, p2 *EllipticCurvePoint) *EllipticCurvePoint {
-	panicIfNotOnCurveP(ec, p1)
-	panicIfNotOnCurveP(ec, p2)
+	return mustPoint(ec.AddPChecked(p1, p2))
+}
+
+// AddPChecked() : AddP() と同じだが、panic する代わりにエラーを返す
+// 点が曲線上にない場合は ErrNotOnCurve か ErrPrimeMismatch、
+// 法が素数でなく傾きの分母が逆元を持たない場合は ErrDivisionByZero
+func (ec *EllipticCurve) AddPChecked(p1, p2 *EllipticCurvePoint) (*EllipticCurvePoint, error) {
+	if err := ec.checkPoint(p1); err != nil {
+		return nil, err
+	}
+	if err := ec.checkPoint(p2); err != nil {
+		return nil, err
+	}
 
 	if p1.IsZero {
-		return new(EllipticCurvePoint).deepCopy(p2)
+		return new(EllipticCurvePoint).deepCopy(p2), nil
 	}
 	if p2.IsZero {
-		return new(EllipticCurvePoint).deepCopy(p2)
+		return new(EllipticCurvePoint).deepCopy(p1), nil
 	}
 
 	x1 := p1.X
@@ -146,7 +315,7 @@ func (ec *EllipticCurve) AddP(p1, p2 *EllipticCurvePoint) *EllipticCurvePoint {
 	if x1.Equals(x2) {
 		// P + (-P) = 0
 		if y1.Equals(new(FiniteField).Neg(y2)) {
-			return NewEllipticCurvePoint(nil, nil, true)
+			return NewEllipticCurvePoint(nil, nil, true), nil
 		}
 
 		// L = (3 * x1^2 + a) / (2 * y1)
@@ -154,11 +323,15 @@ func (ec *EllipticCurve) AddP(p1, p2 *EllipticCurvePoint) *EllipticCurvePoint {
 		L.Add(x1Square, x1Square)
 		L.Add(L, x1Square)
 		L.Add(L, ec.a)
-		L.Div(L, new(FiniteField).Add(y1, y1))
+		if _, err := L.DivChecked(L, new(FiniteField).Add(y1, y1)); err != nil {
+			return nil, err
+		}
 	} else {
 		// L = (y2 - y1) / (x2 - x1)
 		L.Sub(y2, y1)
-		L.Div(L, new(FiniteField).Sub(x2, x1))
+		if _, err := L.DivChecked(L, new(FiniteField).Sub(x2, x1)); err != nil {
+			return nil, err
+		}
 	}
 
 	// x3 = L^2 - x1 - x2
@@ -171,29 +344,60 @@ func (ec *EllipticCurve) AddP(p1, p2 *EllipticCurvePoint) *EllipticCurvePoint {
 	y3.Mul(y3, L)
 	y3.Sub(y3, y1)
 
-	return NewEllipticCurvePoint(x3, y3, false)
+	return NewEllipticCurvePoint(x3, y3, false), nil
 }
 
 func (ec *EllipticCurve) Double(x, y *big.Int) (*big.Int, *big.Int) {
-	p := ToEllipticCurvePoint(x, y, ec.prime)
-	result := ec.DoubleP(p)
-	return result.X.Value, result.Y.Value
+	return mustCoordinates(ec.DoubleChecked(x, y))
+}
+
+// DoubleChecked() : Double() と同じだが、panic する代わりにエラーを返す (無限遠点は (0, 0))
+func (ec *EllipticCurve) DoubleChecked(x, y *big.Int) (*big.Int, *big.Int, error) {
+	return ec.AddChecked(x, y, x, y)
 }
 
 func (ec *EllipticCurve) DoubleP(p *EllipticCurvePoint) *EllipticCurvePoint {
 	return ec.AddP(p, p)
 }
 
+// DoublePChecked() : DoubleP() と同じだが、panic する代わりにエラーを返す
+func (ec *EllipticCurve) DoublePChecked(p *EllipticCurvePoint) (*EllipticCurvePoint, error) {
+	return ec.AddPChecked(p, p)
+}
+
 func (ec *EllipticCurve) ScalarMult(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
-	p := ToEllipticCurvePoint(x, y, ec.prime)
-	result := ec.ScalarMultP(p, k)
-	return result.X.Value, result.Y.Value
+	return mustCoordinates(ec.ScalarMultChecked(x, y, k))
+}
+
+// ScalarMultChecked() : ScalarMult() と同じだが、panic する代わりにエラーを返す (無限遠点は (0, 0))
+func (ec *EllipticCurve) ScalarMultChecked(x, y *big.Int, k []byte) (*big.Int, *big.Int, error) {
+	p, err := ec.toPointChecked(x, y)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := ec.ScalarMultPChecked(p, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	rx, ry := fromPoint(result)
+	return rx, ry, nil
 }
 
 // k is big-endian
 func (ec *EllipticCurve) ScalarMultP(p *EllipticCurvePoint, k []byte) *EllipticCurvePoint {
-	panicIfNotOnCurveP(ec, p)
+	return mustPoint(ec.ScalarMultPChecked(p, k))
+}
 
+// ScalarMultPChecked() : ScalarMultP() と同じだが、点が曲線上にない場合は panic する代わりにエラーを返す
+func (ec *EllipticCurve) ScalarMultPChecked(p *EllipticCurvePoint, k []byte) (*EllipticCurvePoint, error) {
+	if err := ec.checkPoint(p); err != nil {
+		return nil, err
+	}
+	return ec.scalarMult(p, k), nil
+}
+
+// scalarMult() : 点が曲線上にあることを確認済みの場合の ScalarMultP()
+func (ec *EllipticCurve) scalarMult(p *EllipticCurvePoint, k []byte) *EllipticCurvePoint {
 	if len(k) == 0 { // k == 0
 		return NewEllipticCurvePoint(nil, nil, true)
 	}
@@ -202,43 +406,61 @@ func (ec *EllipticCurve) ScalarMultP(p *EllipticCurvePoint, k []byte) *EllipticC
 		return new(EllipticCurvePoint).deepCopy(p)
 	}
 
-	// 繰り返し2乗法の応用
-	// P + P = 2P
-	// 2P + 2P = 4P
-	// 4P + 4P = 8P
-	// ... を用いて、k倍したP を Θ(log n) で求める
-	sum := NewEllipticCurvePoint(nil, nil, true)
-	for _, b := range k {
-		rb := bits.Reverse8(b)
-		for i := 0; i < 8; i++ {
-			sum = ec.AddP(sum, sum)
-			if rb&byte(1) == 1 {
-				sum = ec.AddP(sum, p)
-			}
-			rb >>= 1
-		}
+	if ec.endomorphism != nil {
+		return ec.scalarMultGLV(p, k)
+	}
+
+	// 途中の計算はヤコビアン座標で行い、最後に1回だけアフィン座標に戻す
+	if ec.isSecp256k1 {
+		// secp256k1 のすべての点の位数は N なので、k を N で割った余りにしてよい
+		var s scalarVal
+		s.setBig(new(big.Int).SetBytes(k))
+
+		g := secp256k1Arithmetic{}
+		return g.toAffine(scalarMult[secp256k1Point](g, g.fromAffine(p), s.bytes()))
 	}
 
-	return sum
+	g := jacobianArithmetic{ec: ec}
+	return g.toAffine(scalarMult[*jacobianPoint](g, g.fromAffine(p), k))
 }
 
 func (ec *EllipticCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
-	result := ec.ScalarBaseMultP(k)
-	return result.X.Value, result.Y.Value
+	return fromPoint(ec.ScalarBaseMultP(k))
 }
 
+// ScalarBaseMultP() : k*G を求める (kはbig-endian)
+// Gの位数が分かっている場合は事前計算テーブルを使う
+// k を位数で割った余りにするのは secp256k1 と Validate() で位数を確かめた曲線だけで、それ以外では常に k*G そのものになる
 func (ec *EllipticCurve) ScalarBaseMultP(k []byte) *EllipticCurvePoint {
+	if p, ok := ec.scalarBaseMult(k); ok {
+		return p
+	}
 	return ec.ScalarMultP(ec.g, k)
 }
 
 func NewEllipticCurve(a, b *FiniteField, prime *big.Int, G *EllipticCurvePoint, bitSize int, name string, order *big.Int) *EllipticCurve {
 	return &EllipticCurve{
-		a:       a,
-		b:       b,
-		prime:   prime,
-		g:       G,
-		bigSize: 0,
-		name:    "",
-		order:   order,
+		a:           a,
+		b:           b,
+		prime:       prime,
+		g:           G,
+		bitSize:     bitSize,
+		name:        name,
+		order:       order,
+		isSecp256k1: isSecp256k1(a, b, prime, order),
 	}
 }
+
+// IsSecp256k1() : secp256k1 の曲線かどうか
+// BIP340 など、secp256k1 の上でしか定義されていない方式で使う
+func (ec *EllipticCurve) IsSecp256k1() bool {
+	return ec.isSecp256k1
+}
+
+// isSecp256k1() : y^2 = x^3 + 7 over F_p (p = 2^256 - 2^32 - 977)、位数 N の曲線かどうか
+func isSecp256k1(a, b *FiniteField, prime, order *big.Int) bool {
+	return prime.Cmp(secp256k1Prime) == 0 &&
+		order != nil && order.Cmp(secp256k1Order) == 0 &&
+		a.Value.Sign() == 0 &&
+		b.Value.Cmp(big.NewInt(7)) == 0
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 )
@@ -48,6 +49,22 @@ func Test_EllipticCurve_Add(t *testing.T) {
 			},
 			want: NewEllipticCurvePoint(nil, nil, true),
 		},
+		{
+			name: "P + 0 = P",
+			args: args{
+				x: NewEllipticCurvePoint(
+					NewFiniteField(big.NewInt(170), prime),
+					NewFiniteField(big.NewInt(142), prime),
+					false,
+				),
+				y: NewEllipticCurvePoint(nil, nil, true),
+			},
+			want: NewEllipticCurvePoint(
+				NewFiniteField(big.NewInt(170), prime),
+				NewFiniteField(big.NewInt(142), prime),
+				false,
+			),
+		},
 		{
 			name: "(170, 142) + (60, 139) = (220, 181)",
 			args: args{
@@ -164,3 +181,200 @@ func Test_EllipticCurve_MulByScalar(t *testing.T) {
 		})
 	}
 }
+
+func Test_EllipticCurve_LiftX(t *testing.T) {
+	type args struct {
+		x   *big.Int
+		odd bool
+	}
+
+	prime := big.NewInt(223)
+
+	a := NewFiniteField(big.NewInt(0), prime)
+	b := NewFiniteField(big.NewInt(7), prime)
+	ec := NewEllipticCurve(
+		a,
+		b,
+		prime,
+		nil,
+		0,
+		"test elliptic curve",
+		nil,
+	)
+
+	tests := []struct {
+		name   string
+		args   args
+		want   *EllipticCurvePoint
+		wantOk bool
+	}{
+		{
+			name: "x = 47, odd y = 71",
+			args: args{x: big.NewInt(47), odd: true},
+			want: NewEllipticCurvePoint(
+				NewFiniteField(big.NewInt(47), prime),
+				NewFiniteField(big.NewInt(71), prime),
+				false,
+			),
+			wantOk: true,
+		},
+		{
+			name: "x = 47, even y = 152",
+			args: args{x: big.NewInt(47), odd: false},
+			want: NewEllipticCurvePoint(
+				NewFiniteField(big.NewInt(47), prime),
+				NewFiniteField(big.NewInt(152), prime),
+				false,
+			),
+			wantOk: true,
+		},
+		{
+			name:   "x = 4 is not on the curve",
+			args:   args{x: big.NewInt(4), odd: false},
+			want:   nil,
+			wantOk: false,
+		},
+		{
+			name:   "x = prime is out of range",
+			args:   args{x: prime, odd: false},
+			want:   nil,
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ec.LiftX(tt.args.x, tt.args.odd)
+			if ok != tt.wantOk {
+				t.Fatalf("%v : EllipticCurve.LiftX() ok = %v, want %v", tt.name, ok, tt.wantOk)
+			}
+			if ok && !got.equals(tt.want) {
+				t.Errorf("%v : EllipticCurve.LiftX() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_Checked(t *testing.T) {
+	prime := big.NewInt(223)
+	ec := NewEllipticCurve(
+		NewFiniteField(big.NewInt(0), prime),
+		NewFiniteField(big.NewInt(7), prime),
+		prime,
+		nil,
+		0,
+		"test elliptic curve",
+		nil,
+	)
+
+	p := NewEllipticCurvePoint(NewFiniteField(big.NewInt(47), prime), NewFiniteField(big.NewInt(71), prime), false)
+	negP := NewEllipticCurvePoint(NewFiniteField(big.NewInt(47), prime), NewFiniteField(big.NewInt(152), prime), false)
+	zero := NewEllipticCurvePoint(nil, nil, true)
+
+	notOnCurve := NewEllipticCurvePoint(NewFiniteField(big.NewInt(47), prime), NewFiniteField(big.NewInt(70), prime), false)
+	otherPrime := big.NewInt(227)
+	wrongPrime := NewEllipticCurvePoint(NewFiniteField(big.NewInt(47), otherPrime), NewFiniteField(big.NewInt(71), otherPrime), false)
+	// 47 + 223 は p で割ると曲線上の点になるが、範囲外の座標として扱う
+	outOfRange := &EllipticCurvePoint{
+		X: &FiniteField{Value: big.NewInt(47 + 223), Prime: prime},
+		Y: &FiniteField{Value: big.NewInt(71), Prime: prime},
+	}
+	noCoordinate := &EllipticCurvePoint{X: NewFiniteField(big.NewInt(47), prime)}
+
+	tests := []struct {
+		name    string
+		q       *EllipticCurvePoint
+		want    *EllipticCurvePoint
+		wantErr error
+	}{
+		{name: "P + P", q: p, want: ec.AddP(p, p)},
+		{name: "P + -P", q: negP, want: zero},
+		{name: "P + 0", q: zero, want: p},
+		{name: "not on curve", q: notOnCurve, wantErr: ErrNotOnCurve},
+		{name: "different prime", q: wrongPrime, wantErr: ErrPrimeMismatch},
+		{name: "coordinate out of range", q: outOfRange, wantErr: ErrNotOnCurve},
+		{name: "missing coordinate", q: noCoordinate, wantErr: ErrNotOnCurve},
+		{name: "nil", q: nil, wantErr: ErrNotOnCurve},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := map[string]func() (*EllipticCurvePoint, error){
+				"AddPChecked": func() (*EllipticCurvePoint, error) {
+					return ec.AddPChecked(p, tt.q)
+				},
+				"DoubleScalarMultPChecked": func() (*EllipticCurvePoint, error) {
+					return ec.DoubleScalarMultPChecked([]byte{1}, p, []byte{1}, tt.q)
+				},
+				"MultiScalarMultChecked": func() (*EllipticCurvePoint, error) {
+					return ec.MultiScalarMultChecked([]*EllipticCurvePoint{p, tt.q}, [][]byte{{1}, {1}})
+				},
+			}
+			for name, f := range results {
+				got, err := f()
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("%v : %v() err = %v, want %v", tt.name, name, err, tt.wantErr)
+					continue
+				}
+				if err == nil && !got.equals(tt.want) {
+					t.Errorf("%v : %v() = %v, want %v", tt.name, name, got, tt.want)
+				}
+			}
+
+			if _, err := ec.ScalarMultPChecked(tt.q, []byte{3}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("%v : ScalarMultPChecked() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if _, err := ec.ScalarMultConstTimePChecked(tt.q, []byte{3}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("%v : ScalarMultConstTimePChecked() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_AddChecked(t *testing.T) {
+	prime := big.NewInt(223)
+	ec := NewEllipticCurve(
+		NewFiniteField(big.NewInt(0), prime),
+		NewFiniteField(big.NewInt(7), prime),
+		prime,
+		nil,
+		0,
+		"test elliptic curve",
+		nil,
+	)
+
+	tests := []struct {
+		name           string
+		x1, y1, x2, y2 int64
+		wantX, wantY   int64
+		wantErr        error
+	}{
+		{name: "(47, 71) + (17, 56)", x1: 47, y1: 71, x2: 17, y2: 56, wantX: 215, wantY: 68},
+		{name: "(47, 71) + (47, 152) = (0, 0)", x1: 47, y1: 71, x2: 47, y2: 152, wantX: 0, wantY: 0},
+		{name: "(0, 0) + (47, 71)", x1: 0, y1: 0, x2: 47, y2: 71, wantX: 47, wantY: 71},
+		{name: "not on curve", x1: 47, y1: 71, x2: 47, y2: 70, wantErr: ErrNotOnCurve},
+		{name: "x >= p", x1: 47 + 223, y1: 71, x2: 47, y2: 71, wantErr: ErrNotOnCurve},
+		{name: "negative y", x1: 47, y1: -152, x2: 47, y2: 71, wantErr: ErrNotOnCurve},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, err := ec.AddChecked(big.NewInt(tt.x1), big.NewInt(tt.y1), big.NewInt(tt.x2), big.NewInt(tt.y2))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : AddChecked() err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && (x.Int64() != tt.wantX || y.Int64() != tt.wantY) {
+				t.Errorf("%v : AddChecked() = (%v, %v), want (%v, %v)", tt.name, x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func Test_NewEllipticCurvePointChecked(t *testing.T) {
+	_, err := NewEllipticCurvePointChecked(NewFiniteField(big.NewInt(1), big.NewInt(223)), NewFiniteField(big.NewInt(1), big.NewInt(227)), false)
+	if !errors.Is(err, ErrPrimeMismatch) {
+		t.Errorf("NewEllipticCurvePointChecked() err = %v, want %v", err, ErrPrimeMismatch)
+	}
+	if _, err := NewEllipticCurvePointChecked(nil, nil, true); err != nil {
+		t.Errorf("NewEllipticCurvePointChecked() for zero err = %v, want nil", err)
+	}
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidEndomorphism = errors.New("models: invalid endomorphism")
+
+// Endomorphism : GLV法 (Gallant-Lambert-Vanstone) で使う自己準同型写像の定数
+// φ(x, y) = (Beta*x, y) が φ(P) = Lambda*P を満たすとき、
+// k = k1 + k2*Lambda (mod N) と約128ビットずつに分解すれば
+// k*P = k1*P + k2*φ(P) となり、2倍算の回数が半分になる
+//
+// (A1, B1), (A2, B2) は a + b*Lambda = 0 (mod N) を満たす短いベクトルで、分解に使う
+// (Guide to Elliptic Curve Cryptography, Algorithm 3.74)
+type Endomorphism struct {
+	Beta   *big.Int // pを法とする1の原始3乗根
+	Lambda *big.Int // Nを法とする1の原始3乗根
+	A1, B1 *big.Int
+	A2, B2 *big.Int
+}
+
+// SetEndomorphism() : 曲線に自己準同型写像の定数を設定し、ScalarMultP() でGLV法を使うようにする
+// 曲線上のすべての点の位数がNである (余因子が1の) 曲線でなければならない
+// 定数が正しいかは、Gについて φ(G) == Lambda*G となるかなどで確認する
+func (ec *EllipticCurve) SetEndomorphism(endo *Endomorphism) error {
+	if ec.g == nil || ec.order == nil {
+		return fmt.Errorf("%w: the curve has no generator or order", ErrInvalidEndomorphism)
+	}
+	if endo == nil || endo.Beta == nil || endo.Lambda == nil ||
+		endo.A1 == nil || endo.B1 == nil || endo.A2 == nil || endo.B2 == nil {
+		return fmt.Errorf("%w: missing constants", ErrInvalidEndomorphism)
+	}
+
+	one := big.NewInt(1)
+	three := big.NewInt(3)
+	if new(big.Int).Exp(endo.Beta, three, ec.prime).Cmp(one) != 0 || endo.Beta.Cmp(one) == 0 {
+		return fmt.Errorf("%w: beta is not a primitive cube root of unity mod p", ErrInvalidEndomorphism)
+	}
+	if new(big.Int).Exp(endo.Lambda, three, ec.order).Cmp(one) != 0 || endo.Lambda.Cmp(one) == 0 {
+		return fmt.Errorf("%w: lambda is not a primitive cube root of unity mod N", ErrInvalidEndomorphism)
+	}
+
+	// a + b*lambda = 0 (mod N)
+	for _, v := range [][2]*big.Int{{endo.A1, endo.B1}, {endo.A2, endo.B2}} {
+		t := new(big.Int).Mul(v[1], endo.Lambda)
+		t.Add(t, v[0])
+		if t.Mod(t, ec.order).Sign() != 0 {
+			return fmt.Errorf("%w: basis vector is not in the kernel", ErrInvalidEndomorphism)
+		}
+	}
+
+	// φ(G) == lambda*G
+	want := ec.ScalarMultP(ec.g, endo.Lambda.Bytes())
+	if got := endo.apply(ec.g); !got.equals(want) {
+		return fmt.Errorf("%w: beta and lambda do not match", ErrInvalidEndomorphism)
+	}
+
+	ec.endomorphism = endo
+	return nil
+}
+
+// apply() : φ(x, y) = (beta*x, y)
+func (endo *Endomorphism) apply(p *EllipticCurvePoint) *EllipticCurvePoint {
+	if p.IsZero {
+		return p
+	}
+	beta := NewFiniteField(endo.Beta, p.X.Prime)
+	return NewEllipticCurvePoint(new(FiniteField).Mul(p.X, beta), p.Y, false)
+}
+
+// split() : k = k1 + k2*lambda (mod N) となる |k1|, |k2| がおよそ sqrt(N) の k1, k2 を求める
+// c1 = round(B2*k / N), c2 = round(-B1*k / N)
+// k1 = k - c1*A1 - c2*A2, k2 = -c1*B1 - c2*B2
+func (endo *Endomorphism) split(k, n *big.Int) (k1, k2 *big.Int) {
+	c1 := roundDiv(new(big.Int).Mul(endo.B2, k), n)
+	c2 := roundDiv(new(big.Int).Mul(new(big.Int).Neg(endo.B1), k), n)
+
+	k1 = new(big.Int).Sub(k, new(big.Int).Mul(c1, endo.A1))
+	k1.Sub(k1, new(big.Int).Mul(c2, endo.A2))
+
+	k2 = new(big.Int).Mul(c1, endo.B1)
+	k2.Add(k2, new(big.Int).Mul(c2, endo.B2))
+	k2.Neg(k2)
+	return k1, k2
+}
+
+// roundDiv() : a/n を最も近い整数に丸める (n > 0)
+func roundDiv(a, n *big.Int) *big.Int {
+	// floor((2a + n) / 2n)
+	num := new(big.Int).Lsh(a, 1)
+	num.Add(num, n)
+	return num.Div(num, new(big.Int).Lsh(n, 1))
+}
+
+// scalarMultGLV() : k*P = k1*P + k2*φ(P) として求める
+func (ec *EllipticCurve) scalarMultGLV(p *EllipticCurvePoint, k []byte) *EllipticCurvePoint {
+	return ec.linearCombination([]*EllipticCurvePoint{p}, [][]byte{k})
+}
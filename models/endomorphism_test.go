@@ -0,0 +1,172 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// testSecp256k1Endomorphism() : secp256k1 の GLV 定数 (libsecp256k1 と同じ値)
+func testSecp256k1Endomorphism() *Endomorphism {
+	return &Endomorphism{
+		Beta:   testHex("7AE96A2B657C07106E64479EAC3434E99CF0497512F58995C1396C28719501EE"),
+		Lambda: testHex("5363AD4CC05C30E0A5261C028812645A122E22EA20816678DF02967C1B23BD72"),
+		A1:     testHex("3086D221A7D46BCDE86C90E49284EB15"),
+		B1:     new(big.Int).Neg(testHex("E4437ED6010E88286F547FA90ABFE4C3")),
+		A2:     testHex("114CA50F7A8E2F3F657C1108D9D44CFD8"),
+		B2:     testHex("3086D221A7D46BCDE86C90E49284EB15"),
+	}
+}
+
+func Test_EllipticCurve_SetEndomorphism(t *testing.T) {
+	ec := testSecp256k1()
+	p := ec.prime
+	n := ec.order
+
+	modify := func(f func(e *Endomorphism)) *Endomorphism {
+		e := testSecp256k1Endomorphism()
+		f(e)
+		return e
+	}
+
+	tests := []struct {
+		name    string
+		endo    *Endomorphism
+		wantErr bool
+	}{
+		{name: "secp256k1", endo: testSecp256k1Endomorphism()},
+		{name: "nil", endo: nil, wantErr: true},
+		{name: "missing lambda", endo: modify(func(e *Endomorphism) { e.Lambda = nil }), wantErr: true},
+		{name: "beta = 1", endo: modify(func(e *Endomorphism) { e.Beta = big.NewInt(1) }), wantErr: true},
+		{name: "beta is not a cube root", endo: modify(func(e *Endomorphism) { e.Beta = big.NewInt(2) }), wantErr: true},
+		{
+			// beta^2 も1の原始3乗根だが、lambda とは対応しない
+			name:    "beta does not match lambda",
+			endo:    modify(func(e *Endomorphism) { e.Beta = new(big.Int).Exp(e.Beta, big.NewInt(2), p) }),
+			wantErr: true,
+		},
+		{
+			name:    "lambda is not a cube root",
+			endo:    modify(func(e *Endomorphism) { e.Lambda = new(big.Int).Sub(n, big.NewInt(1)) }),
+			wantErr: true,
+		},
+		{name: "bad basis", endo: modify(func(e *Endomorphism) { e.A1 = new(big.Int).Add(e.A1, big.NewInt(1)) }), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := testSecp256k1()
+			err := ec.SetEndomorphism(tt.endo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%v : EllipticCurve.SetEndomorphism() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidEndomorphism) {
+					t.Errorf("%v : EllipticCurve.SetEndomorphism() error = %v, want ErrInvalidEndomorphism", tt.name, err)
+				}
+				if ec.endomorphism != nil {
+					t.Errorf("%v : endomorphism is set despite the error", tt.name)
+				}
+			}
+		})
+	}
+}
+
+func Test_Endomorphism_split(t *testing.T) {
+	endo := testSecp256k1Endomorphism()
+	n := testSecp256k1().order
+	bound := new(big.Int).Lsh(big.NewInt(1), 129)
+
+	r := rand.New(rand.NewSource(5))
+	ks := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(n, big.NewInt(1)), endo.Lambda}
+	for i := 0; i < 100; i++ {
+		ks = append(ks, new(big.Int).Rand(r, n))
+	}
+
+	for _, k := range ks {
+		k1, k2 := endo.split(k, n)
+
+		// k1 + k2*lambda = k (mod N)
+		got := new(big.Int).Mul(k2, endo.Lambda)
+		got.Add(got, k1)
+		got.Mod(got, n)
+		if got.Cmp(k) != 0 {
+			t.Errorf("split(%x) : k1 + k2*lambda = %x, want %x", k, got, k)
+		}
+		if new(big.Int).Abs(k1).Cmp(bound) >= 0 || new(big.Int).Abs(k2).Cmp(bound) >= 0 {
+			t.Errorf("split(%x) = (%x, %x), want both less than 2^129", k, k1, k2)
+		}
+	}
+}
+
+func Test_EllipticCurve_ScalarMultP_GLV(t *testing.T) {
+	plain := testSecp256k1()
+	fast := testSecp256k1()
+	if err := fast.SetEndomorphism(testSecp256k1Endomorphism()); err != nil {
+		t.Fatal(err)
+	}
+	// fieldVal を使わない汎用の経路でもGLV法を試す
+	generic := testSecp256k1()
+	generic.isSecp256k1 = false
+	if err := generic.SetEndomorphism(testSecp256k1Endomorphism()); err != nil {
+		t.Fatal(err)
+	}
+
+	n := plain.order
+	r := rand.New(rand.NewSource(6))
+	scalars := [][]byte{
+		{0x01},
+		{0x02},
+		new(big.Int).Sub(n, big.NewInt(1)).Bytes(),
+		n.Bytes(),
+		new(big.Int).Add(n, big.NewInt(3)).Bytes(),
+		testSecp256k1Endomorphism().Lambda.Bytes(),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)).Bytes(),
+	}
+	for i := 0; i < 8; i++ {
+		scalars = append(scalars, new(big.Int).Rand(r, n).Bytes())
+	}
+
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "fieldVal", ec: fast},
+		{name: "FiniteField", ec: generic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			P := plain.ScalarBaseMultP([]byte{0x2a})
+			for _, k := range scalars {
+				want := plain.ScalarMultP(P, k)
+				if got := tt.ec.ScalarMultP(P, k); !got.equals(want) {
+					t.Errorf("%v : ScalarMultP(P, %x) = %v, want %v", tt.name, k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEllipticCurve_ScalarMultP_GLV(b *testing.B) {
+	k := testHex("83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8").Bytes()
+
+	plain := testSecp256k1()
+	glv := testSecp256k1()
+	if err := glv.SetEndomorphism(testSecp256k1Endomorphism()); err != nil {
+		b.Fatal(err)
+	}
+	P := plain.ScalarBaseMultP([]byte{0x2a})
+
+	b.Run("GLV", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			glv.ScalarMultP(P, k)
+		}
+	})
+	b.Run("double-and-add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			plain.ScalarMultP(P, k)
+		}
+	})
+}
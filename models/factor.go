@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var ErrNotPositive = errors.New("models: the number is not positive")
+
+// PrimePower : 素因数分解の1つの項 Prime^Exponent
+type PrimePower struct {
+	Prime    *big.Int
+	Exponent int
+}
+
+// trialDivisionBound : この値未満の素因数は割り算で探す
+const trialDivisionBound = 1 << 12
+
+// Factorize() : n (>= 1) を素因数分解し、素数の小さい順に返す
+// 小さい素因数は割り算で、残りは Pollard の rho 法で探すので、
+// 2番目に大きい素因数がおよそ 2^64 を超えると時間がかかる
+// nが正でない場合は ErrNotPositive
+func Factorize(n *big.Int) ([]PrimePower, error) {
+	if n == nil || n.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: Factorize(%v)", ErrNotPositive, n)
+	}
+
+	exps := make(map[string]int)
+	primes := make(map[string]*big.Int)
+	addPrime := func(q *big.Int) {
+		key := q.String()
+		if _, ok := primes[key]; !ok {
+			primes[key] = new(big.Int).Set(q)
+		}
+		exps[key]++
+	}
+
+	m := new(big.Int).Set(n)
+	q, r := new(big.Int), new(big.Int)
+	for d := int64(2); d < trialDivisionBound; d++ {
+		dd := big.NewInt(d)
+		for {
+			q.QuoRem(m, dd, r)
+			if r.Sign() != 0 {
+				break
+			}
+			addPrime(dd)
+			m.Set(q)
+		}
+		if m.Cmp(big.NewInt(d*d)) < 0 {
+			break
+		}
+	}
+
+	// 残りは素数の積なので、分解できるまで rho 法で割っていく
+	stack := []*big.Int{m}
+	for len(stack) > 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if x.Cmp(big.NewInt(1)) == 0 {
+			continue
+		}
+		if x.ProbablyPrime(20) {
+			addPrime(x)
+			continue
+		}
+		d := pollardRho(x)
+		stack = append(stack, d, new(big.Int).Quo(x, d))
+	}
+
+	factors := make([]PrimePower, 0, len(primes))
+	for key, p := range primes {
+		factors = append(factors, PrimePower{Prime: p, Exponent: exps[key]})
+	}
+	sort.Slice(factors, func(i, j int) bool {
+		return factors[i].Prime.Cmp(factors[j].Prime) < 0
+	})
+	return factors, nil
+}
+
+// pollardRho() : 合成数 n の 1 < d < n となる約数dを1つ求める (Brent の変形)
+// x_{i+1} = x_i^2 + c (mod n) の周期を探し、gcd(|x_i - x_j|, n) が自明でなければ約数になる
+func pollardRho(n *big.Int) *big.Int {
+	if n.Bit(0) == 0 {
+		return big.NewInt(2)
+	}
+
+	one := big.NewInt(1)
+	for c := int64(1); ; c++ {
+		cc := big.NewInt(c)
+		f := func(x *big.Int) *big.Int {
+			x.Mul(x, x)
+			x.Add(x, cc)
+			return x.Mod(x, n)
+		}
+
+		x, y := big.NewInt(2), big.NewInt(2)
+		d := big.NewInt(1)
+		diff := new(big.Int)
+		for power, lam := 1, 1; d.Cmp(one) == 0; lam++ {
+			// y は 2^k 回ごとに x に追いつかせる
+			if power == lam {
+				y.Set(x)
+				power *= 2
+				lam = 0
+			}
+			f(x)
+			diff.Sub(x, y)
+			d.GCD(nil, nil, diff.Abs(diff), n)
+		}
+		if d.Cmp(n) != 0 {
+			return d
+		}
+		// 約数が見つからずに1周してしまったら、c を変えてやり直す
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func Test_Factorize(t *testing.T) {
+	secp256k1N, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	// 2^64 + 1 = 274177 * 67280421310721
+	fermat, _ := new(big.Int).SetString("18446744073709551617", 10)
+
+	tests := []struct {
+		name string
+		n    *big.Int
+		want string
+	}{
+		{name: "1", n: big.NewInt(1), want: "[]"},
+		{name: "prime", n: big.NewInt(223), want: "[223^1]"},
+		{name: "252", n: big.NewInt(252), want: "[2^2 3^2 7^1]"},
+		{name: "prime power", n: big.NewInt(7 * 7 * 7 * 7 * 7), want: "[7^5]"},
+		{name: "2^64 + 1", n: fermat, want: "[274177^1 67280421310721^1]"},
+		{name: "two 32-bit primes", n: new(big.Int).Mul(big.NewInt(2147483647), big.NewInt(4294967291)), want: "[2147483647^1 4294967291^1]"},
+		{name: "secp256k1 order", n: secp256k1N, want: fmt.Sprintf("[%v^1]", secp256k1N)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Factorize(tt.n)
+			if err != nil {
+				t.Fatalf("%v : Factorize(%v) err = %v", tt.name, tt.n, err)
+			}
+			if s := formatFactors(got); s != tt.want {
+				t.Errorf("%v : Factorize(%v) = %v, want %v", tt.name, tt.n, s, tt.want)
+			}
+
+			prod := big.NewInt(1)
+			for _, f := range got {
+				prod.Mul(prod, new(big.Int).Exp(f.Prime, big.NewInt(int64(f.Exponent)), nil))
+			}
+			if prod.Cmp(tt.n) != 0 {
+				t.Errorf("%v : product of Factorize(%v) = %v", tt.name, tt.n, prod)
+			}
+		})
+	}
+}
+
+func Test_Factorize_NotPositive(t *testing.T) {
+	for _, n := range []*big.Int{big.NewInt(0), big.NewInt(-12), nil} {
+		if _, err := Factorize(n); !errors.Is(err, ErrNotPositive) {
+			t.Errorf("Factorize(%v) err = %v, want %v", n, err, ErrNotPositive)
+		}
+	}
+}
+
+func formatFactors(factors []PrimePower) string {
+	s := "["
+	for i, f := range factors {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%v^%d", f.Prime, f.Exponent)
+	}
+	return s + "]"
+}
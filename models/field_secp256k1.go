@@ -0,0 +1,215 @@
+package models
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// fieldVal : secp256k1 の素数 p = 2^256 - 2^32 - 977 を法とする有限体の元
+// 64ビット×4 (リトルエンディアン) で表し、常に [0, p) に正規化しておく
+// big.Int と違ってメモリ確保も汎用の剰余演算も行わず、分岐もしない
+type fieldVal [4]uint64
+
+var (
+	fieldPrime = fieldVal{0xFFFFFFFEFFFFFC2F, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF}
+
+	// p - 2 (逆元: x^(p-2))
+	fieldPMinus2 = [4]uint64{0xFFFFFFFEFFFFFC2D, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF}
+
+	secp256k1Prime = new(big.Int).SetBytes(fieldPrime.bytes())
+)
+
+// 2^256 = 2^32 + 977 (mod p)
+const fieldReduction = 0x1000003D1
+
+// setBig() : z = x mod p
+func (z *fieldVal) setBig(x *big.Int) *fieldVal {
+	var b [32]byte
+	new(big.Int).Mod(x, secp256k1Prime).FillBytes(b[:])
+	return z.setBytes(&b)
+}
+
+// setBytes() : z = b mod p (bはbig-endian)
+func (z *fieldVal) setBytes(b *[32]byte) *fieldVal {
+	var t fieldVal
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			t[i] |= uint64(b[31-8*i-j]) << (8 * j)
+		}
+	}
+	return z.reduce(&t, 0)
+}
+
+func (z *fieldVal) setInt(x uint64) *fieldVal {
+	*z = fieldVal{x}
+	return z
+}
+
+// bytes() : 32バイトのbig-endianに変換する
+func (z *fieldVal) bytes() []byte {
+	b := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			b[31-8*i-j] = byte(z[i] >> (8 * j))
+		}
+	}
+	return b
+}
+
+func (z *fieldVal) toBig() *big.Int {
+	return new(big.Int).SetBytes(z.bytes())
+}
+
+func (z *fieldVal) isZero() bool {
+	return z[0]|z[1]|z[2]|z[3] == 0
+}
+
+func (z *fieldVal) isOne() bool {
+	return (z[0]^1)|z[1]|z[2]|z[3] == 0
+}
+
+func (z *fieldVal) equal(x *fieldVal) bool {
+	return (z[0]^x[0])|(z[1]^x[1])|(z[2]^x[2])|(z[3]^x[3]) == 0
+}
+
+// reduce() : z = t + carry * 2^256 mod p (t + carry * 2^256 < 2p であること)
+func (z *fieldVal) reduce(t *fieldVal, carry uint64) *fieldVal {
+	var u fieldVal
+	var b uint64
+	u[0], b = bits.Sub64(t[0], fieldPrime[0], 0)
+	u[1], b = bits.Sub64(t[1], fieldPrime[1], b)
+	u[2], b = bits.Sub64(t[2], fieldPrime[2], b)
+	u[3], b = bits.Sub64(t[3], fieldPrime[3], b)
+
+	// 繰り上がりがある、または t >= p なら t - p を選ぶ
+	mask := -(carry | (b ^ 1))
+	for i := 0; i < 4; i++ {
+		z[i] = t[i] ^ ((t[i] ^ u[i]) & mask)
+	}
+	return z
+}
+
+// add() : z = x + y mod p
+func (z *fieldVal) add(x, y *fieldVal) *fieldVal {
+	var t fieldVal
+	var c uint64
+	t[0], c = bits.Add64(x[0], y[0], 0)
+	t[1], c = bits.Add64(x[1], y[1], c)
+	t[2], c = bits.Add64(x[2], y[2], c)
+	t[3], c = bits.Add64(x[3], y[3], c)
+	return z.reduce(&t, c)
+}
+
+// sub() : z = x - y mod p
+func (z *fieldVal) sub(x, y *fieldVal) *fieldVal {
+	var t fieldVal
+	var b uint64
+	t[0], b = bits.Sub64(x[0], y[0], 0)
+	t[1], b = bits.Sub64(x[1], y[1], b)
+	t[2], b = bits.Sub64(x[2], y[2], b)
+	t[3], b = bits.Sub64(x[3], y[3], b)
+
+	// 桁借りがあれば p を足す
+	mask := -b
+	var c uint64
+	z[0], c = bits.Add64(t[0], fieldPrime[0]&mask, 0)
+	z[1], c = bits.Add64(t[1], fieldPrime[1]&mask, c)
+	z[2], c = bits.Add64(t[2], fieldPrime[2]&mask, c)
+	z[3], _ = bits.Add64(t[3], fieldPrime[3]&mask, c)
+	return z
+}
+
+// neg() : z = -x mod p
+func (z *fieldVal) neg(x *fieldVal) *fieldVal {
+	var zero fieldVal
+	return z.sub(&zero, x)
+}
+
+// mul() : z = x * y mod p
+func (z *fieldVal) mul(x, y *fieldVal) *fieldVal {
+	var r [8]uint64
+	for i := 0; i < 4; i++ {
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(x[i], y[j])
+			var cc uint64
+			lo, cc = bits.Add64(lo, r[i+j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			r[i+j] = lo
+			c = hi
+		}
+		r[i+4] = c
+	}
+	return z.reduceWide(&r)
+}
+
+// square() : z = x^2 mod p
+func (z *fieldVal) square(x *fieldVal) *fieldVal {
+	return z.mul(x, x)
+}
+
+// reduceWide() : 512ビットの r を p で割った余りを z に入れる
+// 2^256 = 2^32 + 977 (mod p) を使って上位の桁を下位に畳み込む
+func (z *fieldVal) reduceWide(r *[8]uint64) *fieldVal {
+	// t = r[0:4] + r[4:8] * (2^32 + 977) < 2^290
+	var t [5]uint64
+	var c uint64
+	for i := 0; i < 4; i++ {
+		hi, lo := bits.Mul64(r[4+i], fieldReduction)
+		var cc uint64
+		lo, cc = bits.Add64(lo, r[i], 0)
+		hi += cc
+		lo, cc = bits.Add64(lo, c, 0)
+		hi += cc
+		t[i] = lo
+		c = hi
+	}
+	t[4] = c
+
+	// u = t[0:4] + t[4] * (2^32 + 977) < 2^257
+	var u fieldVal
+	hi, lo := bits.Mul64(t[4], fieldReduction)
+	u[0], c = bits.Add64(t[0], lo, 0)
+	u[1], c = bits.Add64(t[1], hi, c)
+	u[2], c = bits.Add64(t[2], 0, c)
+	u[3], c = bits.Add64(t[3], 0, c)
+
+	// 2^256 を超えた場合は u は十分小さいので、もう一度 2^32 + 977 を足してもあふれない
+	u[0], c = bits.Add64(u[0], fieldReduction&-c, 0)
+	u[1], c = bits.Add64(u[1], 0, c)
+	u[2], c = bits.Add64(u[2], 0, c)
+	u[3], _ = bits.Add64(u[3], 0, c)
+
+	return z.reduce(&u, 0)
+}
+
+// exp() : z = x^e mod p (eは公開された定数なので、分岐してもよい)
+func (z *fieldVal) exp(x *fieldVal, e *[4]uint64) *fieldVal {
+	base := *x
+	var r fieldVal
+	r.setInt(1)
+	for i := 255; i >= 0; i-- {
+		r.square(&r)
+		if e[i/64]>>(uint(i)%64)&1 == 1 {
+			r.mul(&r, &base)
+		}
+	}
+	*z = r
+	return z
+}
+
+// inverse() : z = 1/x mod p (x == 0 のときは 0)
+func (z *fieldVal) inverse(x *fieldVal) *fieldVal {
+	return z.exp(x, &fieldPMinus2)
+}
+
+// cmov() : cond == 1 なら z = x (分岐しない)
+func (z *fieldVal) cmov(x *fieldVal, cond uint64) *fieldVal {
+	mask := -cond
+	for i := 0; i < 4; i++ {
+		z[i] ^= (z[i] ^ x[i]) & mask
+	}
+	return z
+}
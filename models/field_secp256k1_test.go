@@ -0,0 +1,193 @@
+package models
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// testFieldValues() : 境界値と乱数を混ぜた [0, m) の値
+func testFieldValues(m *big.Int, n int) []*big.Int {
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(m, big.NewInt(1)),
+		new(big.Int).Sub(m, big.NewInt(2)),
+		new(big.Int).Rsh(m, 1),
+		new(big.Int).Lsh(big.NewInt(1), 255),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1)),
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		values = append(values, new(big.Int).Rand(r, m))
+	}
+	return values
+}
+
+func Test_fieldVal(t *testing.T) {
+	p := secp256k1Prime
+	values := testFieldValues(p, 40)
+
+	for _, x := range values {
+		var fx fieldVal
+		fx.setBig(x)
+		if got := fx.toBig(); got.Cmp(x) != 0 {
+			t.Fatalf("fieldVal.setBig(%x).toBig() = %x", x, got)
+		}
+
+		for _, y := range values {
+			var fy, z fieldVal
+			fy.setBig(y)
+
+			tests := []struct {
+				name string
+				got  *big.Int
+				want *big.Int
+			}{
+				{name: "add", got: z.add(&fx, &fy).toBig(), want: new(big.Int).Mod(new(big.Int).Add(x, y), p)},
+				{name: "sub", got: z.sub(&fx, &fy).toBig(), want: new(big.Int).Mod(new(big.Int).Sub(x, y), p)},
+				{name: "mul", got: z.mul(&fx, &fy).toBig(), want: new(big.Int).Mod(new(big.Int).Mul(x, y), p)},
+			}
+			for _, tt := range tests {
+				if tt.got.Cmp(tt.want) != 0 {
+					t.Fatalf("%v : fieldVal.%v(%x, %x) = %x, want %x", tt.name, tt.name, x, y, tt.got, tt.want)
+				}
+			}
+		}
+
+		var z fieldVal
+		if got, want := z.neg(&fx).toBig(), new(big.Int).Mod(new(big.Int).Neg(x), p); got.Cmp(want) != 0 {
+			t.Errorf("fieldVal.neg(%x) = %x, want %x", x, got, want)
+		}
+		if x.Sign() != 0 {
+			if got, want := z.inverse(&fx).toBig(), new(big.Int).ModInverse(x, p); got.Cmp(want) != 0 {
+				t.Errorf("fieldVal.inverse(%x) = %x, want %x", x, got, want)
+			}
+		}
+	}
+}
+
+func Test_fieldVal_setBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *big.Int
+		want *big.Int
+	}{
+		{name: "p", in: secp256k1Prime, want: big.NewInt(0)},
+		{name: "p + 1", in: new(big.Int).Add(secp256k1Prime, big.NewInt(1)), want: big.NewInt(1)},
+		{name: "2^256 - 1", in: new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)), want: big.NewInt(0x1000003D0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b [32]byte
+			tt.in.FillBytes(b[:])
+			if got := new(fieldVal).setBytes(&b).toBig(); got.Cmp(tt.want) != 0 {
+				t.Errorf("%v : fieldVal.setBytes() = %x, want %x", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_scalarVal(t *testing.T) {
+	n := secp256k1Order
+	values := testFieldValues(n, 40)
+
+	for _, x := range values {
+		var sx scalarVal
+		sx.setBig(x)
+		if got := sx.toBig(); got.Cmp(x) != 0 {
+			t.Fatalf("scalarVal.setBig(%x).toBig() = %x", x, got)
+		}
+
+		for _, y := range values {
+			var sy, z scalarVal
+			sy.setBig(y)
+
+			tests := []struct {
+				name string
+				got  *big.Int
+				want *big.Int
+			}{
+				{name: "add", got: z.add(&sx, &sy).toBig(), want: new(big.Int).Mod(new(big.Int).Add(x, y), n)},
+				{name: "sub", got: z.sub(&sx, &sy).toBig(), want: new(big.Int).Mod(new(big.Int).Sub(x, y), n)},
+				{name: "mul", got: z.mul(&sx, &sy).toBig(), want: new(big.Int).Mod(new(big.Int).Mul(x, y), n)},
+			}
+			for _, tt := range tests {
+				if tt.got.Cmp(tt.want) != 0 {
+					t.Fatalf("%v : scalarVal.%v(%x, %x) = %x, want %x", tt.name, tt.name, x, y, tt.got, tt.want)
+				}
+			}
+		}
+
+		var z scalarVal
+		if got, want := z.neg(&sx).toBig(), new(big.Int).Mod(new(big.Int).Neg(x), n); got.Cmp(want) != 0 {
+			t.Errorf("scalarVal.neg(%x) = %x, want %x", x, got, want)
+		}
+		if x.Sign() != 0 {
+			if got, want := z.inverse(&sx).toBig(), new(big.Int).ModInverse(x, n); got.Cmp(want) != 0 {
+				t.Errorf("scalarVal.inverse(%x) = %x, want %x", x, got, want)
+			}
+		}
+	}
+
+	// 2^256 - 1 は N で1回引いて正規化される
+	var b [32]byte
+	for i := range b {
+		b[i] = 0xff
+	}
+	want := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	want.Mod(want, n)
+	if got := new(scalarVal).setBytes(&b).toBig(); got.Cmp(want) != 0 {
+		t.Errorf("scalarVal.setBytes(2^256 - 1) = %x, want %x", got, want)
+	}
+}
+
+func Test_secp256k1Arithmetic_MatchesJacobian(t *testing.T) {
+	ec := testSecp256k1()
+	fast := secp256k1Arithmetic{}
+	slow := jacobianArithmetic{ec: ec}
+
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 8; i++ {
+		k := new(big.Int).Rand(r, ec.order).Bytes()
+		p := ec.ScalarBaseMultP(k)
+
+		want := slow.toAffine(scalarMult[*jacobianPoint](slow, slow.fromAffine(p), k))
+		if got := fast.toAffine(scalarMult[secp256k1Point](fast, fast.fromAffine(p), k)); !got.equals(want) {
+			t.Fatalf("scalarMult(%v, %x) = %v, want %v", p, k, got, want)
+		}
+
+		// P + P と P + (-P) の特殊な場合
+		fp := fast.double(fast.fromAffine(p))
+		if got, want := fast.toAffine(fast.add(fp, fp)), ec.AddP(ec.AddP(p, p), ec.AddP(p, p)); !got.equals(want) {
+			t.Errorf("add(2P, 2P) = %v, want %v", got, want)
+		}
+		if got := fast.toAffine(fast.add(fp, fast.neg(fp))); !got.IsZero {
+			t.Errorf("add(2P, -2P) = %v, want zero", got)
+		}
+	}
+}
+
+func BenchmarkFieldMul(b *testing.B) {
+	x := testHex("83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8")
+	y := testHex("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798")
+
+	b.Run("fieldVal", func(b *testing.B) {
+		var fx, fy fieldVal
+		fx.setBig(x)
+		fy.setBig(y)
+		for i := 0; i < b.N; i++ {
+			fx.mul(&fx, &fy)
+		}
+	})
+	b.Run("FiniteField", func(b *testing.B) {
+		fx := NewFiniteField(x, secp256k1Prime)
+		fy := NewFiniteField(y, secp256k1Prime)
+		for i := 0; i < b.N; i++ {
+			fx.Mul(fx, fy)
+		}
+	})
+}
@@ -1,10 +1,18 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 )
 
+var (
+	// ErrPrimeMismatch : the operands belong to fields with different primes
+	ErrPrimeMismatch = errors.New("models: the primes are not same")
+	// ErrDivisionByZero : the divisor has no multiplicative inverse
+	ErrDivisionByZero = errors.New("models: division by zero")
+)
+
 type FiniteField struct {
 	Value *big.Int
 	Prime *big.Int
@@ -24,9 +32,19 @@ func (f *FiniteField) Neg(x *FiniteField) *FiniteField {
 }
 
 // Add() : sets z to the sum x+y and returns z
+// panics if the primes of x and y are not same
 func (f *FiniteField) Add(x, y *FiniteField) *FiniteField {
-	if x.Prime.Cmp(y.Prime) != 0 {
-		panic(fmt.Sprintf("Add() : the primes of x and y are not same. x prime: %v y prime: %v", x.Prime, y.Prime))
+	z, err := f.AddChecked(x, y)
+	if err != nil {
+		panic(fmt.Sprintf("Add() : %v", err))
+	}
+	return z
+}
+
+// AddChecked() : same as Add() but returns ErrPrimeMismatch instead of panicking
+func (f *FiniteField) AddChecked(x, y *FiniteField) (*FiniteField, error) {
+	if err := checkPrimes(x, y); err != nil {
+		return nil, err
 	}
 
 	if f.Value == nil {
@@ -35,15 +53,25 @@ func (f *FiniteField) Add(x, y *FiniteField) *FiniteField {
 		f.Value.Add(x.Value, y.Value)
 	}
 
-	f.Value.Mod(f.Value, x.Prime)
 	f.Prime = x.Prime
-	return f
+	f.reduce()
+	return f, nil
 }
 
 // Sub() : sets z to the difference x-y and returns z
+// panics if the primes of x and y are not same
 func (f *FiniteField) Sub(x, y *FiniteField) *FiniteField {
-	if x.Prime.Cmp(y.Prime) != 0 {
-		panic(fmt.Sprintf("Sub() : the primes of x and y are not same. x prime: %v y prime: %v", x.Prime, y.Prime))
+	z, err := f.SubChecked(x, y)
+	if err != nil {
+		panic(fmt.Sprintf("Sub() : %v", err))
+	}
+	return z
+}
+
+// SubChecked() : same as Sub() but returns ErrPrimeMismatch instead of panicking
+func (f *FiniteField) SubChecked(x, y *FiniteField) (*FiniteField, error) {
+	if err := checkPrimes(x, y); err != nil {
+		return nil, err
 	}
 
 	if f.Value == nil {
@@ -52,15 +80,25 @@ func (f *FiniteField) Sub(x, y *FiniteField) *FiniteField {
 		f.Value.Sub(x.Value, y.Value)
 	}
 
-	f.Value.Mod(f.Value, x.Prime)
 	f.Prime = x.Prime
-	return f
+	f.reduce()
+	return f, nil
 }
 
 // Mul() : sets z to the product x*y and returns z
+// panics if the primes of x and y are not same
 func (f *FiniteField) Mul(x, y *FiniteField) *FiniteField {
-	if x.Prime.Cmp(y.Prime) != 0 {
-		panic(fmt.Sprintf("Mul() : the primes of x and y are not same. x prime: %v y prime: %v", x.Prime, y.Prime))
+	z, err := f.MulChecked(x, y)
+	if err != nil {
+		panic(fmt.Sprintf("Mul() : %v", err))
+	}
+	return z
+}
+
+// MulChecked() : same as Mul() but returns ErrPrimeMismatch instead of panicking
+func (f *FiniteField) MulChecked(x, y *FiniteField) (*FiniteField, error) {
+	if err := checkPrimes(x, y); err != nil {
+		return nil, err
 	}
 
 	if f.Value == nil {
@@ -71,16 +109,32 @@ func (f *FiniteField) Mul(x, y *FiniteField) *FiniteField {
 
 	f.Value.Mod(f.Value, x.Prime)
 	f.Prime = x.Prime
-	return f
+	return f, nil
 }
 
 // Div() : sets z to the quotient x/y and returns z
+// panics if the primes of x and y are not same or y is not invertible
 func (f *FiniteField) Div(x, y *FiniteField) *FiniteField {
-	if x.Prime.Cmp(y.Prime) != 0 {
-		panic(fmt.Sprintf("Div() : the primes of x and y are not same. x prime: %v y prime: %v", x.Prime, y.Prime))
+	z, err := f.DivChecked(x, y)
+	if err != nil {
+		panic(fmt.Sprintf("Div() : %v", err))
+	}
+	return z
+}
+
+// DivChecked() : same as Div() but returns an error instead of panicking
+// ErrPrimeMismatch if the primes of x and y are not same,
+// ErrDivisionByZero if y has no inverse (y is zero, or the modulus is not a prime and gcd(y, modulus) != 1)
+// z is unchanged on error
+func (f *FiniteField) DivChecked(x, y *FiniteField) (*FiniteField, error) {
+	if err := checkPrimes(x, y); err != nil {
+		return nil, err
 	}
 
 	inv := new(big.Int).ModInverse(y.Value, y.Prime)
+	if inv == nil {
+		return nil, fmt.Errorf("%w: %v has no inverse modulo %v", ErrDivisionByZero, y.Value, y.Prime)
+	}
 
 	if f.Value == nil {
 		f.Value = new(big.Int).Mul(x.Value, inv)
@@ -89,10 +143,185 @@ func (f *FiniteField) Div(x, y *FiniteField) *FiniteField {
 	}
 
 	f.Value.Mod(f.Value, x.Prime)
+	f.Prime = x.Prime
+	return f, nil
+}
+
+// Inverse() : sets z to the multiplicative inverse 1/x and returns z
+// panics if x is not invertible
+func (f *FiniteField) Inverse(x *FiniteField) *FiniteField {
+	z, err := f.InverseChecked(x)
+	if err != nil {
+		panic(fmt.Sprintf("Inverse() : %v", err))
+	}
+	return z
+}
+
+// InverseChecked() : same as Inverse() but returns ErrDivisionByZero instead of panicking
+func (f *FiniteField) InverseChecked(x *FiniteField) (*FiniteField, error) {
+	inv := new(big.Int).ModInverse(x.Value, x.Prime)
+	if x.IsZero() || inv == nil {
+		return nil, fmt.Errorf("%w: %v has no inverse modulo %v", ErrDivisionByZero, x.Value, x.Prime)
+	}
+
+	if f.Value == nil {
+		f.Value = inv
+	} else {
+		f.Value.Set(inv)
+	}
+
+	f.Prime = x.Prime
+	return f, nil
+}
+
+// Exp() : sets z to x**e and returns z
+// if e < 0, z is set to (1/x)**(-e), and panics if x is not invertible
+func (f *FiniteField) Exp(x *FiniteField, e *big.Int) *FiniteField {
+	z, err := f.ExpChecked(x, e)
+	if err != nil {
+		panic(fmt.Sprintf("Exp() : %v", err))
+	}
+	return z
+}
+
+// ExpChecked() : same as Exp() but returns ErrDivisionByZero instead of panicking
+func (f *FiniteField) ExpChecked(x *FiniteField, e *big.Int) (*FiniteField, error) {
+	base := x.Value
+	if e.Sign() < 0 {
+		inv, err := new(FiniteField).InverseChecked(x)
+		if err != nil {
+			return nil, err
+		}
+		base = inv.Value
+		e = new(big.Int).Neg(e)
+	}
+
+	if f.Value == nil {
+		f.Value = new(big.Int).Exp(base, e, x.Prime)
+	} else {
+		f.Value.Exp(base, e, x.Prime)
+	}
+
+	f.Prime = x.Prime
+	return f, nil
+}
+
+// Legendre() : returns the Legendre symbol (x/p)
+// 1 if x is a non-zero square, -1 if x is not a square and 0 if x is zero
+// Euler's criterion: (x/p) = x**((p-1)/2)
+func (f FiniteField) Legendre() int {
+	if f.IsZero() {
+		return 0
+	}
+
+	e := new(big.Int).Sub(f.Prime, big.NewInt(1))
+	e.Rsh(e, 1)
+	if new(FiniteField).Exp(&f, e).Value.Cmp(big.NewInt(1)) == 0 {
+		return 1
+	}
+	return -1
+}
+
+// IsSquare() : reports whether x has a square root
+func (f FiniteField) IsSquare() bool {
+	return f.Legendre() >= 0
+}
+
+// Sqrt() : sets z to a square root of x and returns z
+// if x is not a square, z is unchanged and nil is returned
+func (f *FiniteField) Sqrt(x *FiniteField) *FiniteField {
+	if !x.IsSquare() {
+		return nil
+	}
+
+	var root *FiniteField
+	switch {
+	case x.IsZero():
+		root = NewFiniteField(big.NewInt(0), x.Prime)
+	case x.Prime.Bit(0) == 1 && x.Prime.Bit(1) == 1:
+		// p = 3 (mod 4) : sqrt(x) = x**((p+1)/4)
+		e := new(big.Int).Add(x.Prime, big.NewInt(1))
+		e.Rsh(e, 2)
+		root = new(FiniteField).Exp(x, e)
+	default:
+		root = tonelliShanks(x)
+	}
+
+	if f.Value == nil {
+		f.Value = root.Value
+	} else {
+		f.Value.Set(root.Value)
+	}
+
 	f.Prime = x.Prime
 	return f
 }
 
+// tonelliShanks() : returns a square root of a non-zero square x for any odd prime
+func tonelliShanks(x *FiniteField) *FiniteField {
+	prime := x.Prime
+	one := NewFiniteField(big.NewInt(1), prime)
+
+	// p - 1 = q * 2^s (q is odd)
+	q := new(big.Int).Sub(prime, big.NewInt(1))
+	s := 0
+	for q.Bit(0) == 0 {
+		q.Rsh(q, 1)
+		s++
+	}
+
+	// z : a quadratic non-residue
+	z := NewFiniteField(big.NewInt(2), prime)
+	for z.Legendre() != -1 {
+		z.Add(z, one)
+	}
+
+	m := s
+	c := new(FiniteField).Exp(z, q)
+	t := new(FiniteField).Exp(x, q)
+	r := new(FiniteField).Exp(x, new(big.Int).Rsh(new(big.Int).Add(q, big.NewInt(1)), 1))
+
+	for !t.Equals(one) {
+		// find the least i (0 < i < m) such that t**(2**i) = 1
+		i := 0
+		for t2 := NewFiniteField(t.Value, prime); !t2.Equals(one); t2.Mul(t2, t2) {
+			i++
+		}
+
+		// b = c**(2**(m-i-1))
+		b := NewFiniteField(c.Value, prime)
+		for j := 0; j < m-i-1; j++ {
+			b.Mul(b, b)
+		}
+
+		m = i
+		c.Mul(b, b)
+		t.Mul(t, c)
+		r.Mul(r, b)
+	}
+
+	return r
+}
+
+// IsZero() : reports whether x == 0
+func (f FiniteField) IsZero() bool {
+	return f.Value.Sign() == 0
+}
+
+// reduce() : sets z to z mod prime
+// the sum or difference of two reduced values only needs one addition or subtraction of prime
+func (f *FiniteField) reduce() {
+	if f.Value.Sign() < 0 {
+		f.Value.Add(f.Value, f.Prime)
+	} else if f.Value.Cmp(f.Prime) >= 0 {
+		f.Value.Sub(f.Value, f.Prime)
+	}
+
+	if f.Value.Sign() < 0 || f.Value.Cmp(f.Prime) >= 0 {
+		f.Value.Mod(f.Value, f.Prime)
+	}
+}
+
 func (f FiniteField) String() string {
 	return f.Value.String()
 }
@@ -101,6 +330,14 @@ func (f FiniteField) Equals(x *FiniteField) bool {
 	return f.Value.Cmp(x.Value) == 0
 }
 
+// checkPrimes() : returns ErrPrimeMismatch if the primes of x and y are not same
+func checkPrimes(x, y *FiniteField) error {
+	if x.Prime.Cmp(y.Prime) != 0 {
+		return fmt.Errorf("%w: x prime: %v y prime: %v", ErrPrimeMismatch, x.Prime, y.Prime)
+	}
+	return nil
+}
+
 // NewFiniteField() : constructor of FiniteField
 func NewFiniteField(value *big.Int, prime *big.Int) *FiniteField {
 	return &FiniteField{
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 )
@@ -281,3 +282,336 @@ func Test_FiniteField_Div(t *testing.T) {
 		})
 	}
 }
+
+func Test_FiniteField_Inverse(t *testing.T) {
+	type arg struct {
+		a *FiniteField
+	}
+
+	prime := big.NewInt(223)
+	secp256k1 := testHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F")
+	inv2, _ := new(big.Int).SetString("57896044618658097711785492504343953926634992332820282019728792003954417335832", 10)
+
+	tests := []struct {
+		name string
+		arg  arg
+		want FiniteField
+	}{
+		{
+			name: "1 / 1 = 1",
+			arg:  arg{a: NewFiniteField(big.NewInt(1), prime)},
+			want: *NewFiniteField(big.NewInt(1), prime),
+		},
+		{
+			name: "1 / 2 = 112",
+			arg:  arg{a: NewFiniteField(big.NewInt(2), prime)},
+			want: *NewFiniteField(big.NewInt(112), prime),
+		},
+		{
+			name: "1 / -1 = -1",
+			arg:  arg{a: NewFiniteField(big.NewInt(-1), prime)},
+			want: *NewFiniteField(big.NewInt(-1), prime),
+		},
+		{
+			name: "secp256k1 1 / 2",
+			arg:  arg{a: NewFiniteField(big.NewInt(2), secp256k1)},
+			want: *NewFiniteField(inv2, secp256k1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := new(FiniteField).Inverse(tt.arg.a); !got.Equals(&tt.want) {
+				t.Errorf("%v : Inverse() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_FiniteField_Exp(t *testing.T) {
+	type args struct {
+		a *FiniteField
+		e *big.Int
+	}
+
+	prime := big.NewInt(223)
+	secp256k1 := testHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F")
+
+	tests := []struct {
+		name string
+		args args
+		want FiniteField
+	}{
+		{
+			name: "3 ** 0 = 1",
+			args: args{a: NewFiniteField(big.NewInt(3), prime), e: big.NewInt(0)},
+			want: *NewFiniteField(big.NewInt(1), prime),
+		},
+		{
+			name: "3 ** 5 = 20",
+			args: args{a: NewFiniteField(big.NewInt(3), prime), e: big.NewInt(5)},
+			want: *NewFiniteField(big.NewInt(20), prime),
+		},
+		{
+			name: "3 ** (prime - 1) = 1",
+			args: args{a: NewFiniteField(big.NewInt(3), prime), e: big.NewInt(222)},
+			want: *NewFiniteField(big.NewInt(1), prime),
+		},
+		{
+			name: "2 ** -1 = 112",
+			args: args{a: NewFiniteField(big.NewInt(2), prime), e: big.NewInt(-1)},
+			want: *NewFiniteField(big.NewInt(112), prime),
+		},
+		{
+			name: "0 ** 5 = 0",
+			args: args{a: NewFiniteField(big.NewInt(0), prime), e: big.NewInt(5)},
+			want: *NewFiniteField(big.NewInt(0), prime),
+		},
+		{
+			name: "secp256k1 2 ** (prime - 1) = 1",
+			args: args{a: NewFiniteField(big.NewInt(2), secp256k1), e: new(big.Int).Sub(secp256k1, big.NewInt(1))},
+			want: *NewFiniteField(big.NewInt(1), secp256k1),
+		},
+		{
+			name: "secp256k1 2 ** 256 = 2 ** 32 + 977",
+			args: args{a: NewFiniteField(big.NewInt(2), secp256k1), e: big.NewInt(256)},
+			want: *NewFiniteField(big.NewInt(1<<32+977), secp256k1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := new(FiniteField).Exp(tt.args.a, tt.args.e); !got.Equals(&tt.want) {
+				t.Errorf("%v : Exp() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_FiniteField_Legendre(t *testing.T) {
+	type arg struct {
+		a *FiniteField
+	}
+
+	prime := big.NewInt(223)
+	secp256k1 := testHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F")
+
+	tests := []struct {
+		name string
+		arg  arg
+		want int
+	}{
+		{name: "(0/223) = 0", arg: arg{a: NewFiniteField(big.NewInt(0), prime)}, want: 0},
+		{name: "(1/223) = 1", arg: arg{a: NewFiniteField(big.NewInt(1), prime)}, want: 1},
+		{name: "(2/223) = 1", arg: arg{a: NewFiniteField(big.NewInt(2), prime)}, want: 1},
+		{name: "(3/223) = -1", arg: arg{a: NewFiniteField(big.NewInt(3), prime)}, want: -1},
+		{name: "(-1/223) = -1", arg: arg{a: NewFiniteField(big.NewInt(-1), prime)}, want: -1},
+		{name: "secp256k1 (4/p) = 1", arg: arg{a: NewFiniteField(big.NewInt(4), secp256k1)}, want: 1},
+		{name: "secp256k1 (7/p) = -1", arg: arg{a: NewFiniteField(big.NewInt(7), secp256k1)}, want: -1},
+		{name: "secp256k1 (-1/p) = -1", arg: arg{a: NewFiniteField(big.NewInt(-1), secp256k1)}, want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.arg.a.Legendre(); got != tt.want {
+				t.Errorf("%v : Legendre() = %v, want %v", tt.name, got, tt.want)
+			}
+			if got, want := tt.arg.a.IsSquare(), tt.want >= 0; got != want {
+				t.Errorf("%v : IsSquare() = %v, want %v", tt.name, got, want)
+			}
+		})
+	}
+}
+
+func Test_FiniteField_Sqrt(t *testing.T) {
+	secp256k1 := testHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F")
+	// p = 1 (mod 4) なので Tonelli-Shanks を使う (p - 1 = q * 2^96)
+	p224 := testHex("ffffffffffffffffffffffffffffffff000000000000000000000001")
+
+	tests := []struct {
+		name       string
+		prime      *big.Int
+		values     []int64
+		nonSquares []int64
+	}{
+		{name: "prime 223 (3 mod 4)", prime: big.NewInt(223), values: []int64{0, 1, 2, 4, 7, 222}, nonSquares: []int64{3, 5, 6, 10}},
+		{name: "prime 113 (1 mod 8)", prime: big.NewInt(113), values: []int64{0, 1, 2, 4, 7, 112}, nonSquares: []int64{3, 5, 6}},
+		{name: "secp256k1 prime", prime: secp256k1, values: []int64{0, 1, 2, 4, 8, 11, -2}, nonSquares: []int64{3, 5, 7, -1}},
+		{name: "P-224 prime", prime: p224, values: []int64{0, 1, 2, 3, 4, 5, 7, 9}, nonSquares: []int64{11, 19, 22, 23}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, v := range tt.values {
+				x := NewFiniteField(big.NewInt(v), tt.prime)
+				if !x.IsSquare() {
+					continue
+				}
+				root := new(FiniteField).Sqrt(x)
+				if root == nil {
+					t.Fatalf("%v : Sqrt(%v) = nil, want a square root", tt.name, x)
+				}
+				if got := new(FiniteField).Mul(root, root); !got.Equals(x) {
+					t.Errorf("%v : Sqrt(%v)^2 = %v, want %v", tt.name, x, got, x)
+				}
+			}
+
+			for _, v := range tt.nonSquares {
+				x := NewFiniteField(big.NewInt(v), tt.prime)
+				if root := new(FiniteField).Sqrt(x); root != nil {
+					t.Errorf("%v : Sqrt(%v) = %v, want nil", tt.name, x, root)
+				}
+			}
+		})
+	}
+}
+
+func Test_FiniteField_Sqrt_AllElements(t *testing.T) {
+	for _, p := range []int64{223, 113, 97, 17} {
+		prime := big.NewInt(p)
+		squares := 0
+		for v := int64(0); v < p; v++ {
+			x := NewFiniteField(big.NewInt(v), prime)
+			root := new(FiniteField).Sqrt(x)
+			if root == nil {
+				continue
+			}
+			squares++
+			if got := new(FiniteField).Mul(root, root); !got.Equals(x) {
+				t.Errorf("prime %v : Sqrt(%v)^2 = %v, want %v", p, x, got, x)
+			}
+		}
+
+		// 0 と (p-1)/2 個の平方剰余
+		if want := int((p-1)/2 + 1); squares != want {
+			t.Errorf("prime %v : number of squares = %v, want %v", p, squares, want)
+		}
+	}
+}
+
+func Test_FiniteField_IsZero(t *testing.T) {
+	prime := big.NewInt(223)
+
+	tests := []struct {
+		name string
+		a    *FiniteField
+		want bool
+	}{
+		{name: "0", a: NewFiniteField(big.NewInt(0), prime), want: true},
+		{name: "prime", a: NewFiniteField(prime, prime), want: true},
+		{name: "1", a: NewFiniteField(big.NewInt(1), prime), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.IsZero(); got != tt.want {
+				t.Errorf("%v : IsZero() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_FiniteField_Checked(t *testing.T) {
+	prime := big.NewInt(223)
+	other := big.NewInt(227)
+	composite := big.NewInt(15)
+
+	tests := []struct {
+		name    string
+		f       func(z *FiniteField) (*FiniteField, error)
+		wantErr error
+	}{
+		{
+			name: "AddChecked different primes",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.AddChecked(NewFiniteField(big.NewInt(1), prime), NewFiniteField(big.NewInt(1), other))
+			},
+			wantErr: ErrPrimeMismatch,
+		},
+		{
+			name: "SubChecked different primes",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.SubChecked(NewFiniteField(big.NewInt(1), prime), NewFiniteField(big.NewInt(1), other))
+			},
+			wantErr: ErrPrimeMismatch,
+		},
+		{
+			name: "MulChecked different primes",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.MulChecked(NewFiniteField(big.NewInt(1), prime), NewFiniteField(big.NewInt(1), other))
+			},
+			wantErr: ErrPrimeMismatch,
+		},
+		{
+			name: "DivChecked different primes",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.DivChecked(NewFiniteField(big.NewInt(1), prime), NewFiniteField(big.NewInt(1), other))
+			},
+			wantErr: ErrPrimeMismatch,
+		},
+		{
+			name: "DivChecked by zero",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.DivChecked(NewFiniteField(big.NewInt(1), prime), NewFiniteField(big.NewInt(0), prime))
+			},
+			wantErr: ErrDivisionByZero,
+		},
+		{
+			// 法が素数でなければ、0でなくても逆元を持たないことがある
+			name: "DivChecked 1/5 mod 15",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.DivChecked(NewFiniteField(big.NewInt(1), composite), NewFiniteField(big.NewInt(5), composite))
+			},
+			wantErr: ErrDivisionByZero,
+		},
+		{
+			name: "InverseChecked zero",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.InverseChecked(NewFiniteField(big.NewInt(0), prime))
+			},
+			wantErr: ErrDivisionByZero,
+		},
+		{
+			name: "ExpChecked 0^-1",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.ExpChecked(NewFiniteField(big.NewInt(0), prime), big.NewInt(-1))
+			},
+			wantErr: ErrDivisionByZero,
+		},
+		{
+			name: "DivChecked 1/7 mod 15",
+			f: func(z *FiniteField) (*FiniteField, error) {
+				return z.DivChecked(NewFiniteField(big.NewInt(1), composite), NewFiniteField(big.NewInt(7), composite))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := NewFiniteField(big.NewInt(42), prime)
+			got, err := tt.f(z)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%v : err = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				if got != nil {
+					t.Errorf("%v : got %v with error, want nil", tt.name, got)
+				}
+				// エラーの場合は z を変更しない
+				if z.Value.Int64() != 42 || z.Prime.Cmp(prime) != 0 {
+					t.Errorf("%v : z = %v (mod %v), want unchanged", tt.name, z.Value, z.Prime)
+				}
+			}
+		})
+	}
+}
+
+func Test_FiniteField_Div_Panics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Div() by a non-invertible value did not panic")
+		}
+	}()
+	composite := big.NewInt(15)
+	new(FiniteField).Div(NewFiniteField(big.NewInt(1), composite), NewFiniteField(big.NewInt(5), composite))
+}
@@ -0,0 +1,235 @@
+package models
+
+import "math/big"
+
+// jacobianPoint : ヤコビアン座標 (X : Y : Z) で表した楕円曲線上の点
+// アフィン座標では (X / Z^2, Y / Z^3) を表し、Z == 0 のときは無限遠点
+// 加算・2倍算で逆元を計算しなくてよいので、スカラー倍算の途中ではこちらを使う
+type jacobianPoint struct {
+	x *FiniteField
+	y *FiniteField
+	z *FiniteField
+}
+
+func (p *jacobianPoint) isZero() bool {
+	return p.z.IsZero()
+}
+
+// jacobianZero() : 無限遠点 (1 : 1 : 0)
+func (ec *EllipticCurve) jacobianZero() *jacobianPoint {
+	return &jacobianPoint{
+		x: NewFiniteField(big.NewInt(1), ec.prime),
+		y: NewFiniteField(big.NewInt(1), ec.prime),
+		z: NewFiniteField(big.NewInt(0), ec.prime),
+	}
+}
+
+// toJacobian() : (x, y) -> (x : y : 1)
+func (ec *EllipticCurve) toJacobian(p *EllipticCurvePoint) *jacobianPoint {
+	if p.IsZero {
+		return ec.jacobianZero()
+	}
+	return &jacobianPoint{
+		x: NewFiniteField(p.X.Value, ec.prime),
+		y: NewFiniteField(p.Y.Value, ec.prime),
+		z: NewFiniteField(big.NewInt(1), ec.prime),
+	}
+}
+
+// toAffine() : (X : Y : Z) -> (X / Z^2, Y / Z^3)
+// 逆元の計算はここで1回だけ行う
+func (ec *EllipticCurve) toAffine(p *jacobianPoint) *EllipticCurvePoint {
+	if p.isZero() {
+		return NewEllipticCurvePoint(nil, nil, true)
+	}
+
+	zInv := new(FiniteField).Inverse(p.z)
+	zInv2 := new(FiniteField).Mul(zInv, zInv)
+	zInv3 := new(FiniteField).Mul(zInv2, zInv)
+
+	return NewEllipticCurvePoint(
+		new(FiniteField).Mul(p.x, zInv2),
+		new(FiniteField).Mul(p.y, zInv3),
+		false,
+	)
+}
+
+// jacobianDouble() : 2P を求める
+// a == 0 の場合は dbl-2009-l、それ以外は dbl-2007-bl
+// (https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html)
+func (ec *EllipticCurve) jacobianDouble(p *jacobianPoint) *jacobianPoint {
+	if p.isZero() || p.y.IsZero() {
+		return ec.jacobianZero()
+	}
+
+	// XX = X1^2, YY = Y1^2, YYYY = YY^2
+	xx := new(FiniteField).Mul(p.x, p.x)
+	yy := new(FiniteField).Mul(p.y, p.y)
+	yyyy := new(FiniteField).Mul(yy, yy)
+
+	// S = 2 * ((X1 + YY)^2 - XX - YYYY)
+	s := new(FiniteField).Add(p.x, yy)
+	s.Mul(s, s)
+	s.Sub(s, xx)
+	s.Sub(s, yyyy)
+	s.Add(s, s)
+
+	// M = 3 * XX + a * ZZ^2
+	m := new(FiniteField).Add(xx, xx)
+	m.Add(m, xx)
+
+	var z3 *FiniteField
+	if ec.a.IsZero() {
+		// Z3 = 2 * Y1 * Z1
+		z3 = new(FiniteField).Mul(p.y, p.z)
+		z3.Add(z3, z3)
+	} else {
+		zz := new(FiniteField).Mul(p.z, p.z)
+		azzzz := new(FiniteField).Mul(zz, zz)
+		azzzz.Mul(azzzz, ec.a)
+		m.Add(m, azzzz)
+
+		// Z3 = (Y1 + Z1)^2 - YY - ZZ
+		z3 = new(FiniteField).Add(p.y, p.z)
+		z3.Mul(z3, z3)
+		z3.Sub(z3, yy)
+		z3.Sub(z3, zz)
+	}
+
+	// X3 = M^2 - 2 * S
+	x3 := new(FiniteField).Mul(m, m)
+	x3.Sub(x3, s)
+	x3.Sub(x3, s)
+
+	// Y3 = M * (S - X3) - 8 * YYYY
+	yyyy8 := new(FiniteField).Add(yyyy, yyyy)
+	yyyy8.Add(yyyy8, yyyy8)
+	yyyy8.Add(yyyy8, yyyy8)
+	y3 := new(FiniteField).Sub(s, x3)
+	y3.Mul(y3, m)
+	y3.Sub(y3, yyyy8)
+
+	return &jacobianPoint{x: x3, y: y3, z: z3}
+}
+
+// jacobianAdd() : P + Q を求める (add-2007-bl)
+func (ec *EllipticCurve) jacobianAdd(p, q *jacobianPoint) *jacobianPoint {
+	if p.isZero() {
+		return q
+	}
+	if q.isZero() {
+		return p
+	}
+
+	// Z1Z1 = Z1^2, Z2Z2 = Z2^2
+	z1z1 := new(FiniteField).Mul(p.z, p.z)
+	z2z2 := new(FiniteField).Mul(q.z, q.z)
+
+	// U1 = X1 * Z2Z2, U2 = X2 * Z1Z1
+	u1 := new(FiniteField).Mul(p.x, z2z2)
+	u2 := new(FiniteField).Mul(q.x, z1z1)
+
+	// S1 = Y1 * Z2 * Z2Z2, S2 = Y2 * Z1 * Z1Z1
+	s1 := new(FiniteField).Mul(p.y, q.z)
+	s1.Mul(s1, z2z2)
+	s2 := new(FiniteField).Mul(q.y, p.z)
+	s2.Mul(s2, z1z1)
+
+	// H = U2 - U1, r = 2 * (S2 - S1)
+	h := new(FiniteField).Sub(u2, u1)
+	r := new(FiniteField).Sub(s2, s1)
+	r.Add(r, r)
+
+	if h.IsZero() {
+		// P == Q なら2倍算、P == -Q なら無限遠点
+		if r.IsZero() {
+			return ec.jacobianDouble(p)
+		}
+		return ec.jacobianZero()
+	}
+
+	// I = (2 * H)^2, J = H * I, V = U1 * I
+	i := new(FiniteField).Add(h, h)
+	i.Mul(i, i)
+	j := new(FiniteField).Mul(h, i)
+	v := new(FiniteField).Mul(u1, i)
+
+	// X3 = r^2 - J - 2 * V
+	x3 := new(FiniteField).Mul(r, r)
+	x3.Sub(x3, j)
+	x3.Sub(x3, v)
+	x3.Sub(x3, v)
+
+	// Y3 = r * (V - X3) - 2 * S1 * J
+	s1j := new(FiniteField).Mul(s1, j)
+	y3 := new(FiniteField).Sub(v, x3)
+	y3.Mul(y3, r)
+	y3.Sub(y3, s1j)
+	y3.Sub(y3, s1j)
+
+	// Z3 = ((Z1 + Z2)^2 - Z1Z1 - Z2Z2) * H
+	z3 := new(FiniteField).Add(p.z, q.z)
+	z3.Mul(z3, z3)
+	z3.Sub(z3, z1z1)
+	z3.Sub(z3, z2z2)
+	z3.Mul(z3, h)
+
+	return &jacobianPoint{x: x3, y: y3, z: z3}
+}
+
+// jacobianAddMixed() : ヤコビアン座標の点Pとアフィン座標の点Qの和を求める (madd-2007-bl)
+// Qの Z == 1 を利用して乗算を減らす
+func (ec *EllipticCurve) jacobianAddMixed(p *jacobianPoint, q *EllipticCurvePoint) *jacobianPoint {
+	if q.IsZero {
+		return p
+	}
+	if p.isZero() {
+		return ec.toJacobian(q)
+	}
+
+	// Z1Z1 = Z1^2, U2 = X2 * Z1Z1, S2 = Y2 * Z1 * Z1Z1
+	z1z1 := new(FiniteField).Mul(p.z, p.z)
+	u2 := new(FiniteField).Mul(q.X, z1z1)
+	s2 := new(FiniteField).Mul(q.Y, p.z)
+	s2.Mul(s2, z1z1)
+
+	// H = U2 - X1, r = 2 * (S2 - Y1)
+	h := new(FiniteField).Sub(u2, p.x)
+	r := new(FiniteField).Sub(s2, p.y)
+	r.Add(r, r)
+
+	if h.IsZero() {
+		if r.IsZero() {
+			return ec.jacobianDouble(p)
+		}
+		return ec.jacobianZero()
+	}
+
+	// HH = H^2, I = 4 * HH, J = H * I, V = X1 * I
+	hh := new(FiniteField).Mul(h, h)
+	i := new(FiniteField).Add(hh, hh)
+	i.Add(i, i)
+	j := new(FiniteField).Mul(h, i)
+	v := new(FiniteField).Mul(p.x, i)
+
+	// X3 = r^2 - J - 2 * V
+	x3 := new(FiniteField).Mul(r, r)
+	x3.Sub(x3, j)
+	x3.Sub(x3, v)
+	x3.Sub(x3, v)
+
+	// Y3 = r * (V - X3) - 2 * Y1 * J
+	y1j := new(FiniteField).Mul(p.y, j)
+	y3 := new(FiniteField).Sub(v, x3)
+	y3.Mul(y3, r)
+	y3.Sub(y3, y1j)
+	y3.Sub(y3, y1j)
+
+	// Z3 = (Z1 + H)^2 - Z1Z1 - HH
+	z3 := new(FiniteField).Add(p.z, h)
+	z3.Mul(z3, z3)
+	z3.Sub(z3, z1z1)
+	z3.Sub(z3, hh)
+
+	return &jacobianPoint{x: x3, y: y3, z: z3}
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"math/bits"
+	"testing"
+)
+
+// scalarMultAffine() : ヤコビアン座標を使わない、アフィン座標だけのスカラー倍算
+// ScalarMultP() の結果と比較するための参照実装
+func scalarMultAffine(ec *EllipticCurve, p *EllipticCurvePoint, k []byte) *EllipticCurvePoint {
+	sum := NewEllipticCurvePoint(nil, nil, true)
+	for _, b := range k {
+		rb := bits.Reverse8(b)
+		for i := 0; i < 8; i++ {
+			sum = ec.AddP(sum, sum)
+			if rb&byte(1) == 1 {
+				sum = ec.AddP(sum, p)
+			}
+			rb >>= 1
+		}
+	}
+	return sum
+}
+
+// allPoints() : 小さい素数上の曲線のすべての点(無限遠点を含む)
+func allPoints(ec *EllipticCurve) []*EllipticCurvePoint {
+	points := []*EllipticCurvePoint{NewEllipticCurvePoint(nil, nil, true)}
+	for x := int64(0); x < ec.prime.Int64(); x++ {
+		for _, odd := range []bool{false, true} {
+			if p, ok := ec.LiftX(big.NewInt(x), odd); ok {
+				points = append(points, p)
+			}
+		}
+	}
+	return points
+}
+
+func testP256() *EllipticCurve {
+	params := elliptic.P256().Params()
+	G := NewEllipticCurvePoint(
+		NewFiniteField(params.Gx, params.P),
+		NewFiniteField(params.Gy, params.P),
+		false,
+	)
+	return NewEllipticCurve(
+		NewFiniteField(big.NewInt(-3), params.P),
+		NewFiniteField(params.B, params.P),
+		params.P,
+		G,
+		params.BitSize,
+		params.Name,
+		params.N,
+	)
+}
+
+// toJacobianScaled() : Z != 1 のヤコビアン座標 (X*Z^2 : Y*Z^3 : Z) を作る
+func toJacobianScaled(ec *EllipticCurve, p *EllipticCurvePoint, z int64) *jacobianPoint {
+	if p.IsZero {
+		return ec.jacobianZero()
+	}
+	Z := NewFiniteField(big.NewInt(z), ec.prime)
+	Z2 := new(FiniteField).Mul(Z, Z)
+	Z3 := new(FiniteField).Mul(Z2, Z)
+	return &jacobianPoint{
+		x: new(FiniteField).Mul(p.X, Z2),
+		y: new(FiniteField).Mul(p.Y, Z3),
+		z: Z,
+	}
+}
+
+func Test_EllipticCurve_Jacobian(t *testing.T) {
+	tests := []struct {
+		name string
+		a    int64
+		b    int64
+		p    int64
+	}{
+		{name: "y^2 = x^3 + 7 over F_223 (a = 0)", a: 0, b: 7, p: 223},
+		{name: "y^2 = x^3 + 2x + 3 over F_97 (a != 0)", a: 2, b: 3, p: 97},
+		{name: "y^2 = x^3 - 3x + 5 over F_103 (a = -3)", a: -3, b: 5, p: 103},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prime := big.NewInt(tt.p)
+			ec := NewEllipticCurve(
+				NewFiniteField(big.NewInt(tt.a), prime),
+				NewFiniteField(big.NewInt(tt.b), prime),
+				prime,
+				nil,
+				0,
+				"test elliptic curve",
+				nil,
+			)
+			points := allPoints(ec)
+
+			for _, p := range points {
+				want := ec.AddP(p, p)
+				if got := ec.toAffine(ec.jacobianDouble(toJacobianScaled(ec, p, 5))); !got.equals(want) {
+					t.Fatalf("%v : jacobianDouble(%v) = %v, want %v", tt.name, p, got, want)
+				}
+
+				for _, q := range points {
+					want := ec.AddP(p, q)
+					if got := ec.toAffine(ec.jacobianAdd(toJacobianScaled(ec, p, 3), toJacobianScaled(ec, q, 7))); !got.equals(want) {
+						t.Fatalf("%v : jacobianAdd(%v, %v) = %v, want %v", tt.name, p, q, got, want)
+					}
+					if got := ec.toAffine(ec.jacobianAddMixed(toJacobianScaled(ec, p, 11), q)); !got.equals(want) {
+						t.Fatalf("%v : jacobianAddMixed(%v, %v) = %v, want %v", tt.name, p, q, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_ScalarMultP_MatchesAffine(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "secp256k1", ec: testSecp256k1()},
+		{name: "P-256", ec: testP256()},
+	}
+
+	scalars := [][]byte{
+		{0x00},
+		{0x01},
+		{0x02},
+		{0xff, 0xff},
+		testHex("83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8").Bytes(),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range scalars {
+				want := scalarMultAffine(tt.ec, tt.ec.g, k)
+				if got := tt.ec.ScalarMultP(tt.ec.g, k); !got.equals(want) {
+					t.Errorf("%v : ScalarMultP(G, %x) = %v, want %v", tt.name, k, got, want)
+				}
+			}
+
+			// N * G = 0
+			if got := tt.ec.ScalarMultP(tt.ec.g, tt.ec.order.Bytes()); !got.IsZero {
+				t.Errorf("%v : ScalarMultP(G, N) = %v, want zero", tt.name, got)
+			}
+		})
+	}
+}
+
+func BenchmarkEllipticCurve_ScalarMultP(b *testing.B) {
+	ec := testSecp256k1()
+	k := testHex("83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8").Bytes()
+
+	b.Run("fieldVal", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ec.ScalarMultP(ec.g, k)
+		}
+	})
+	b.Run("jacobian", func(b *testing.B) {
+		g := jacobianArithmetic{ec: ec}
+		for i := 0; i < b.N; i++ {
+			g.toAffine(scalarMult[*jacobianPoint](g, g.fromAffine(ec.g), k))
+		}
+	})
+	b.Run("affine", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			scalarMultAffine(ec, ec.g, k)
+		}
+	})
+}
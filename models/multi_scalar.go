@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// pippengerThreshold : この項数以上なら strausWNAF() ではなく pippenger() を使う
+// BenchmarkMultiScalarMult の結果から決めた (secp256k1 では 64 項でほぼ同じ、96 項以上で pippenger() の方が速い)
+// GLV法を使う曲線では、1つの点が2項に分かれたあとの項数で比べる
+const pippengerThreshold = 96
+
+// MultiScalarMult() : Σ k_i * P_i を求める (k_iはbig-endian)
+// 項数が少なければ Straus の方法 (interleaved wNAF)、多ければ Pippenger のバケット法を使う
+// 実行時間はスカラーに依存するので、秘密のスカラーには使わないこと
+func (ec *EllipticCurve) MultiScalarMult(points []*EllipticCurvePoint, scalars [][]byte) *EllipticCurvePoint {
+	return mustPoint(ec.MultiScalarMultChecked(points, scalars))
+}
+
+// MultiScalarMultChecked() : MultiScalarMult() と同じだが、panic する代わりにエラーを返す
+func (ec *EllipticCurve) MultiScalarMultChecked(points []*EllipticCurvePoint, scalars [][]byte) (*EllipticCurvePoint, error) {
+	if len(points) != len(scalars) {
+		return nil, fmt.Errorf("models: the numbers of points (%d) and scalars (%d) are not same", len(points), len(scalars))
+	}
+	for i, p := range points {
+		if err := ec.checkPoint(p); err != nil {
+			return nil, fmt.Errorf("point %d: %w", i, err)
+		}
+	}
+
+	return ec.linearCombination(points, scalars), nil
+}
+
+// pippengerWindow() : bitLen ビットのスカラー n 個に対して、加算の回数がおよそ最小になるウィンドウ幅
+// ウィンドウ1つあたり、点をバケットに入れる n 回と、バケットを足し合わせる 2^c 回の加算がかかる
+func pippengerWindow(n, bitLen int) int {
+	best, bestCost := 1, -1
+	for c := 1; c <= 16; c++ {
+		windows := bitLen/c + 1
+		cost := windows * (n + 1<<c)
+		if bestCost < 0 || cost < bestCost {
+			best, bestCost = c, cost
+		}
+	}
+	return best
+}
+
+// signedDigits() : k (>= 0) を 2^c 進数で表し、各桁を (-2^(c-1), 2^(c-1)] に寄せたものを下位の桁から返す
+// k = Σ d_i * 2^(c*i)
+// 桁が負の場合は点を反転させて足せばよいので、バケットの数が半分で済む
+func signedDigits(k *big.Int, c, windows int) []int32 {
+	digits := make([]int32, windows)
+	half := int32(1) << (c - 1)
+	carry := int32(0)
+	for i := range digits {
+		d := carry
+		for j := 0; j < c; j++ {
+			d += int32(k.Bit(i*c+j)) << j
+		}
+		carry = 0
+		if d > half {
+			d -= half << 1
+			carry = 1
+		}
+		digits[i] = d
+	}
+	return digits
+}
+
+// pippenger() : Σ k_i * P_i を Pippenger のバケット法で求める
+// スカラーを幅cのウィンドウに分け、上位のウィンドウから
+//
+//	sum = 2^c * sum + Σ_{j=1}^{2^(c-1)} j * B_j
+//
+// を繰り返す。B_j はそのウィンドウの桁が ±j である点 (負なら反転した点) の和で、
+// Σ j * B_j は B_m + (B_m + B_{m-1}) + ... と累積和を足していけば 2^c 回程度の加算で求まる
+// k_i は0以上でなければならない
+func pippenger[P any](g pointArithmetic[P], points []P, scalars []*big.Int) P {
+	bitLen := 0
+	for _, k := range scalars {
+		if k.BitLen() > bitLen {
+			bitLen = k.BitLen()
+		}
+	}
+	c := pippengerWindow(len(points), bitLen)
+	// 最上位の桁からの繰り上がりの分だけ、ウィンドウを1つ多くとる
+	windows := bitLen/c + 1
+
+	digits := make([][]int32, len(scalars))
+	for i, k := range scalars {
+		digits[i] = signedDigits(k, c, windows)
+	}
+
+	buckets := make([]P, 1<<(c-1))
+	sum := g.zero()
+	for w := windows - 1; w >= 0; w-- {
+		for i := 0; i < c; i++ {
+			sum = g.double(sum)
+		}
+
+		for j := range buckets {
+			buckets[j] = g.zero()
+		}
+		for i, d := range digits {
+			if d := d[w]; d > 0 {
+				buckets[d-1] = g.add(buckets[d-1], points[i])
+			} else if d < 0 {
+				buckets[-d-1] = g.add(buckets[-d-1], g.neg(points[i]))
+			}
+		}
+
+		running, total := g.zero(), g.zero()
+		for j := len(buckets) - 1; j >= 0; j-- {
+			running = g.add(running, buckets[j])
+			total = g.add(total, running)
+		}
+		sum = g.add(sum, total)
+	}
+	return sum
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func Test_signedDigits(t *testing.T) {
+	r := rand.New(rand.NewSource(10))
+	for _, c := range []int{1, 2, 4, 7, 11} {
+		for i := 0; i < 50; i++ {
+			k := new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), 256))
+			windows := k.BitLen()/c + 1
+			digits := signedDigits(k, c, windows)
+
+			got := new(big.Int)
+			half := int32(1) << (c - 1)
+			for j := len(digits) - 1; j >= 0; j-- {
+				if digits[j] > half || digits[j] <= -half {
+					t.Fatalf("signedDigits(%x, %v) : digit %v is out of range", k, c, digits[j])
+				}
+				got.Lsh(got, uint(c))
+				got.Add(got, big.NewInt(int64(digits[j])))
+			}
+			if got.Cmp(k) != 0 {
+				t.Errorf("signedDigits(%x, %v) = %v, which represents %x", k, c, digits, got)
+			}
+		}
+	}
+}
+
+func Test_pippenger(t *testing.T) {
+	ec := testSecp256k1()
+	g := secp256k1Arithmetic{}
+	r := rand.New(rand.NewSource(11))
+	G := ec.Generator()
+
+	tests := []struct {
+		name    string
+		points  []*EllipticCurvePoint
+		scalars []*big.Int
+	}{
+		{name: "empty"},
+		{name: "one", points: []*EllipticCurvePoint{G}, scalars: []*big.Int{big.NewInt(3)}},
+		{
+			// 同じ点が同じバケットに入る
+			name:    "same points",
+			points:  []*EllipticCurvePoint{G, G, G},
+			scalars: []*big.Int{big.NewInt(5), big.NewInt(5), big.NewInt(5)},
+		},
+		{
+			// 5G + (N-5)G = 0
+			name:    "cancel out",
+			points:  []*EllipticCurvePoint{G, G},
+			scalars: []*big.Int{big.NewInt(5), new(big.Int).Sub(ec.order, big.NewInt(5))},
+		},
+	}
+
+	random := struct {
+		name    string
+		points  []*EllipticCurvePoint
+		scalars []*big.Int
+	}{name: "random"}
+	for i := 0; i < 20; i++ {
+		random.points = append(random.points, ec.ScalarBaseMultP(big.NewInt(r.Int63()).Bytes()))
+		random.scalars = append(random.scalars, new(big.Int).Rand(r, ec.order))
+	}
+	tests = append(tests, random)
+
+	for _, tt := range tests {
+		ps := make([]secp256k1Point, len(tt.points))
+		for i, p := range tt.points {
+			ps[i] = g.fromAffine(p)
+		}
+
+		want := g.toAffine(strausWNAF[secp256k1Point](g, ps, tt.scalars))
+		if got := g.toAffine(pippenger[secp256k1Point](g, ps, tt.scalars)); !got.equals(want) {
+			t.Errorf("%v : pippenger() = %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func Test_EllipticCurve_MultiScalarMult(t *testing.T) {
+	glv := testSecp256k1()
+	if err := glv.SetEndomorphism(testSecp256k1Endomorphism()); err != nil {
+		t.Fatal(err)
+	}
+	generic := testSecp256k1()
+	generic.isSecp256k1 = false
+
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+		n    int
+	}{
+		{name: "secp256k1 small", ec: testSecp256k1(), n: 5},
+		{name: "secp256k1 large", ec: testSecp256k1(), n: pippengerThreshold + 3},
+		{name: "secp256k1 GLV small", ec: glv, n: 5},
+		{name: "secp256k1 GLV large", ec: glv, n: pippengerThreshold},
+		{name: "secp256k1 FiniteField large", ec: generic, n: pippengerThreshold},
+		{name: "P-256 small", ec: testP256(), n: 7},
+		{name: "P-256 large", ec: testP256(), n: pippengerThreshold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := tt.ec
+			r := rand.New(rand.NewSource(12))
+
+			var points []*EllipticCurvePoint
+			var scalars [][]byte
+			want := NewEllipticCurvePoint(nil, nil, true)
+			for i := 0; i < tt.n; i++ {
+				var p *EllipticCurvePoint
+				switch i % 10 {
+				case 0:
+					p = NewEllipticCurvePoint(nil, nil, true)
+				case 1:
+					p = ec.Generator()
+				default:
+					p = ec.ScalarBaseMultP(big.NewInt(r.Int63()).Bytes())
+				}
+				k := new(big.Int).Rand(r, ec.order).Bytes()
+				if i%7 == 3 {
+					k = []byte{}
+				}
+
+				points = append(points, p)
+				scalars = append(scalars, k)
+				want = ec.AddP(want, ec.ScalarMultP(p, k))
+			}
+
+			if got := ec.MultiScalarMult(points, scalars); !got.equals(want) {
+				t.Errorf("%v : MultiScalarMult() = %v, want %v", tt.name, got, want)
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_MultiScalarMult_ToyCurve(t *testing.T) {
+	prime := big.NewInt(223)
+	ec := NewEllipticCurve(
+		NewFiniteField(big.NewInt(0), prime),
+		NewFiniteField(big.NewInt(7), prime),
+		prime,
+		nil,
+		0,
+		"test elliptic curve",
+		nil,
+	)
+	points := allPoints(ec)
+
+	// すべての点を1回ずつ使う (点の数は pippengerThreshold より多い)
+	r := rand.New(rand.NewSource(13))
+	scalars := make([][]byte, len(points))
+	for i := range points {
+		scalars[i] = big.NewInt(r.Int63n(1000)).Bytes()
+	}
+
+	for _, n := range []int{3, len(points)} {
+		want := NewEllipticCurvePoint(nil, nil, true)
+		for i := 0; i < n; i++ {
+			want = ec.AddP(want, ec.ScalarMultP(points[i], scalars[i]))
+		}
+		if got := ec.MultiScalarMult(points[:n], scalars[:n]); !got.equals(want) {
+			t.Errorf("MultiScalarMult() with %v points = %v, want %v", n, got, want)
+		}
+	}
+}
+
+// BenchmarkMultiScalarMult : pippengerThreshold を決めるためのベンチマーク
+// go test -run xxx -bench MultiScalarMult ./models
+func BenchmarkMultiScalarMult(b *testing.B) {
+	ec := testSecp256k1()
+	g := secp256k1Arithmetic{}
+	r := rand.New(rand.NewSource(14))
+
+	for _, n := range []int{16, 32, 64, 96, 128, 256, 512} {
+		points := make([]secp256k1Point, n)
+		scalars := make([]*big.Int, n)
+		for i := range points {
+			points[i] = g.fromAffine(ec.ScalarBaseMultP(big.NewInt(r.Int63()).Bytes()))
+			scalars[i] = new(big.Int).Rand(r, ec.order)
+		}
+
+		b.Run(fmt.Sprintf("straus/n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				strausWNAF[secp256k1Point](g, points, scalars)
+			}
+		})
+		b.Run(fmt.Sprintf("pippenger/n=%v", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pippenger[secp256k1Point](g, points, scalars)
+			}
+		})
+	}
+}
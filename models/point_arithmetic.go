@@ -0,0 +1,81 @@
+package models
+
+import "math/bits"
+
+// pointArithmetic : スカラー倍算のアルゴリズムが使う、点の表現ごとの演算
+// FiniteField を使う汎用の実装 (jacobianArithmetic) と
+// secp256k1 専用の fieldVal を使う実装 (secp256k1Arithmetic) があり、
+// アルゴリズムはどちらの表現に対しても1回書けばよいようにしている
+type pointArithmetic[P any] interface {
+	zero() P
+	fromAffine(p *EllipticCurvePoint) P
+	toAffine(p P) *EllipticCurvePoint
+	double(p P) P
+	add(p, q P) P
+	neg(p P) P
+
+	// normalize() : すべての点を Z == 1 にそろえる (無限遠点はそのまま)
+	// Z == 1 の点との加算は乗算が少なくて済むので、事前計算したテーブルに使う
+	normalize(ps []P)
+}
+
+// scalarMult() : 繰り返し2乗法の応用で k*P を求める (kはbig-endian)
+// P + P = 2P
+// 2P + 2P = 4P
+// 4P + 4P = 8P
+// ... を用いて、k倍したP を Θ(log n) で求める
+func scalarMult[P any](g pointArithmetic[P], p P, k []byte) P {
+	sum := g.zero()
+	for _, b := range k {
+		rb := bits.Reverse8(b)
+		for i := 0; i < 8; i++ {
+			sum = g.double(sum)
+			if rb&byte(1) == 1 {
+				sum = g.add(sum, p)
+			}
+			rb >>= 1
+		}
+	}
+	return sum
+}
+
+// jacobianArithmetic : FiniteField のヤコビアン座標による pointArithmetic
+type jacobianArithmetic struct {
+	ec *EllipticCurve
+}
+
+func (g jacobianArithmetic) zero() *jacobianPoint {
+	return g.ec.jacobianZero()
+}
+
+func (g jacobianArithmetic) fromAffine(p *EllipticCurvePoint) *jacobianPoint {
+	return g.ec.toJacobian(p)
+}
+
+func (g jacobianArithmetic) toAffine(p *jacobianPoint) *EllipticCurvePoint {
+	return g.ec.toAffine(p)
+}
+
+func (g jacobianArithmetic) double(p *jacobianPoint) *jacobianPoint {
+	return g.ec.jacobianDouble(p)
+}
+
+// add() : Q の Z == 1 の場合は乗算の少ない jacobianAddMixed() を使う
+func (g jacobianArithmetic) add(p, q *jacobianPoint) *jacobianPoint {
+	if !q.isZero() && q.z.Value.IsInt64() && q.z.Value.Int64() == 1 {
+		return g.ec.jacobianAddMixed(p, NewEllipticCurvePoint(q.x, q.y, false))
+	}
+	return g.ec.jacobianAdd(p, q)
+}
+
+func (g jacobianArithmetic) normalize(ps []*jacobianPoint) {
+	for i, p := range ps {
+		if !p.isZero() {
+			ps[i] = g.ec.toJacobian(g.ec.toAffine(p))
+		}
+	}
+}
+
+func (g jacobianArithmetic) neg(p *jacobianPoint) *jacobianPoint {
+	return &jacobianPoint{x: p.x, y: new(FiniteField).Neg(p.y), z: p.z}
+}
@@ -0,0 +1,356 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+)
+
+var ErrCurveTooLarge = errors.New("models: the curve is too large")
+
+const (
+	// maxEnumerateBits : Points(), OrderNaive(), Subgroups() を使えるpのビット数
+	maxEnumerateBits = 16
+	// maxBSGSBits : OrderBSGS() を使えるpのビット数 (およそ p^(1/4) 回の加算がかかる)
+	maxBSGSBits = 64
+)
+
+// countingCurve() : 位数の計算に使う、同じ方程式の曲線
+// GLV法や secp256k1 専用の実装はスカラーを位数で割ってしまうので、生成元も位数も持たせない
+func (ec *EllipticCurve) countingCurve() *EllipticCurve {
+	return NewEllipticCurve(ec.a, ec.b, ec.prime, nil, ec.bitSize, ec.name, nil)
+}
+
+// checkOddPrime() : 点を数えるときは平方根やルジャンドル記号を使うので、pが奇素数でなければ ErrInvalidCurve
+// pが合成数だと Tonelli–Shanks が終わらないことがあり、p = 2 だと big.Jacobi が panic する
+func (ec *EllipticCurve) checkOddPrime() error {
+	if ec.prime.Cmp(big.NewInt(2)) <= 0 || !ec.prime.ProbablyPrime(20) {
+		return fmt.Errorf("%w: p = %v is not an odd prime", ErrInvalidCurve, ec.prime)
+	}
+	return nil
+}
+
+// rhs() : x^3 + a*x + b (mod p)
+func (ec *EllipticCurve) rhs(x *big.Int) *big.Int {
+	r := new(big.Int).Mul(x, x)
+	r.Add(r, ec.a.Value)
+	r.Mul(r, x)
+	r.Add(r, ec.b.Value)
+	return r.Mod(r, ec.prime)
+}
+
+// Points() : 曲線上のすべての点を、無限遠点、xの小さい順、同じxならyの小さい順で返す
+// pが maxEnumerateBits ビットより大きい場合は ErrCurveTooLarge、奇素数でない場合は ErrInvalidCurve
+func (ec *EllipticCurve) Points() ([]*EllipticCurvePoint, error) {
+	if ec.prime.BitLen() > maxEnumerateBits {
+		return nil, fmt.Errorf("%w: p has more than %d bits", ErrCurveTooLarge, maxEnumerateBits)
+	}
+	if err := ec.checkOddPrime(); err != nil {
+		return nil, err
+	}
+
+	points := []*EllipticCurvePoint{NewEllipticCurvePoint(nil, nil, true)}
+	p := ec.prime.Int64()
+	for x := int64(0); x < p; x++ {
+		X := NewFiniteField(big.NewInt(x), ec.prime)
+		r := NewFiniteField(ec.rhs(X.Value), ec.prime)
+		y := new(FiniteField).Sqrt(r)
+		if y == nil {
+			continue
+		}
+
+		negY := new(FiniteField).Neg(y)
+		if y.Value.Cmp(negY.Value) > 0 {
+			y, negY = negY, y
+		}
+		points = append(points, NewEllipticCurvePoint(X, y, false))
+		if !y.Equals(negY) {
+			points = append(points, NewEllipticCurvePoint(X, negY, false))
+		}
+	}
+	return points, nil
+}
+
+// OrderNaive() : 群の位数 #E = p + 1 + Σ_x (x^3 + a*x + b / p) をすべてのxについて数えて求める
+// pが maxEnumerateBits ビットより大きい場合は ErrCurveTooLarge、奇素数でない場合は ErrInvalidCurve
+func (ec *EllipticCurve) OrderNaive() (*big.Int, error) {
+	if ec.prime.BitLen() > maxEnumerateBits {
+		return nil, fmt.Errorf("%w: p has more than %d bits", ErrCurveTooLarge, maxEnumerateBits)
+	}
+	if err := ec.checkOddPrime(); err != nil {
+		return nil, err
+	}
+
+	sum := int64(0)
+	p := ec.prime.Int64()
+	x := new(big.Int)
+	for i := int64(0); i < p; i++ {
+		x.SetInt64(i)
+		sum += int64(big.Jacobi(ec.rhs(x), ec.prime))
+	}
+	return big.NewInt(p + 1 + sum), nil
+}
+
+// Order() : 群の位数 #E を求める
+// pが小さければ OrderNaive()、そうでなければ OrderBSGS() を使う
+func (ec *EllipticCurve) Order() (*big.Int, error) {
+	if ec.prime.BitLen() <= maxEnumerateBits {
+		return ec.OrderNaive()
+	}
+	return ec.OrderBSGS()
+}
+
+// hasseInterval() : Hasse の定理による #E の範囲 [p+1-2*sqrt(p), p+1+2*sqrt(p)]
+func hasseInterval(p *big.Int) (lo, hi *big.Int) {
+	t := new(big.Int).Sqrt(new(big.Int).Lsh(p, 2))
+	pPlus1 := new(big.Int).Add(p, big.NewInt(1))
+	return new(big.Int).Sub(pPlus1, t), new(big.Int).Add(pPlus1, t)
+}
+
+// OrderBSGS() : 群の位数 #E を baby-step giant-step と Mestre の方法で求める
+//
+// ランダムな点Pについて、Hasse の範囲 [lo, hi] で N*P == 0 となるNを BSGS で探し、Pの位数を求める
+// 点の位数の最小公倍数 L の倍数が [lo, hi] に1つしかなければ、それが #E になる
+// 群の構造によっては1つに決まらないことがあるが、p > 229 なら曲線か二次ツイスト E' のどちらかは
+// 4*sqrt(p) より大きい位数の点を持つ (Mestre) ので、#E = 2p + 2 - #E' から求める
+//
+// pが maxBSGSBits ビットより大きい場合は ErrCurveTooLarge、奇素数でない場合は ErrInvalidCurve
+func (ec *EllipticCurve) OrderBSGS() (*big.Int, error) {
+	p := ec.prime
+	if p.BitLen() > maxBSGSBits {
+		return nil, fmt.Errorf("%w: p has more than %d bits", ErrCurveTooLarge, maxBSGSBits)
+	}
+	if err := ec.checkOddPrime(); err != nil {
+		return nil, err
+	}
+	if p.Cmp(big.NewInt(229)) <= 0 {
+		return ec.OrderNaive()
+	}
+
+	curves := [2]*EllipticCurve{ec.countingCurve(), ec.twist()}
+	lcms := [2]*big.Int{big.NewInt(1), big.NewInt(1)}
+	lo, hi := hasseInterval(p)
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		c := i % 2
+		P := curves[c].randomPoint(r)
+		ord, err := curves[c].pointOrderInInterval(P, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+
+		gcd := new(big.Int).GCD(nil, nil, lcms[c], ord)
+		lcms[c].Mul(lcms[c], new(big.Int).Quo(ord, gcd))
+
+		if m, ok := uniqueMultiple(lcms[c], lo, hi); ok {
+			if c == 1 {
+				// #E + #E' = 2p + 2
+				m.Sub(new(big.Int).Lsh(new(big.Int).Add(p, big.NewInt(1)), 1), m)
+			}
+			return m, nil
+		}
+	}
+	return nil, errors.New("models: OrderBSGS() did not converge")
+}
+
+// uniqueMultiple() : [lo, hi] にある l の倍数がただ1つならそれを返す
+func uniqueMultiple(l, lo, hi *big.Int) (*big.Int, bool) {
+	kLo := new(big.Int).Add(lo, new(big.Int).Sub(l, big.NewInt(1)))
+	kLo.Quo(kLo, l)
+	kHi := new(big.Int).Quo(hi, l)
+	if kLo.Cmp(kHi) != 0 {
+		return nil, false
+	}
+	return kLo.Mul(kLo, l), true
+}
+
+// twist() : 二次ツイスト y^2 = x^3 + a*d^2*x + b*d^3 (dは平方非剰余)
+// x^3 + a*x + b が平方剰余でないxに対して、ツイストでは d*x が点になる
+func (ec *EllipticCurve) twist() *EllipticCurve {
+	p := ec.prime
+	d := big.NewInt(2)
+	for big.Jacobi(d, p) != -1 {
+		d.Add(d, big.NewInt(1))
+	}
+	d2 := new(big.Int).Mul(d, d)
+	d3 := new(big.Int).Mul(d2, d)
+
+	a := NewFiniteField(new(big.Int).Mul(ec.a.Value, d2), p)
+	b := NewFiniteField(new(big.Int).Mul(ec.b.Value, d3), p)
+	return NewEllipticCurve(a, b, p, nil, ec.bitSize, ec.name+" twist", nil)
+}
+
+// randomPoint() : 曲線上のランダムな点 (無限遠点以外)
+func (ec *EllipticCurve) randomPoint(r *rand.Rand) *EllipticCurvePoint {
+	for {
+		x := new(big.Int).Rand(r, ec.prime)
+		if p, ok := ec.LiftX(x, r.Intn(2) == 1); ok {
+			return p
+		}
+	}
+}
+
+// pointOrderInInterval() : N*P == 0 となるNを [lo, hi] から BSGS で探し、それを使ってPの位数を求める
+// m = ceil(sqrt(hi - lo)) として、baby step で j*P (1 <= j <= m) を覚えておき、
+// giant step で Q_i = (lo + i*m)*P が ±j*P になるiを探す
+func (ec *EllipticCurve) pointOrderInInterval(P *EllipticCurvePoint, lo, hi *big.Int) (*big.Int, error) {
+	width := new(big.Int).Sub(hi, lo)
+	m := new(big.Int).Sqrt(width).Int64() + 1
+
+	baby := make(map[string]int64, m)
+	jP := NewEllipticCurvePoint(nil, nil, true)
+	var mP *EllipticCurvePoint
+	for j := int64(1); j <= m; j++ {
+		jP = ec.AddP(jP, P)
+		if jP.IsZero {
+			// Pの位数は j 以下なので、ここで決まる
+			return big.NewInt(j), nil
+		}
+		if _, ok := baby[jP.X.Value.String()]; !ok {
+			baby[jP.X.Value.String()] = j
+		}
+		mP = jP
+	}
+
+	Q := ec.ScalarMultP(P, lo.Bytes())
+	for i := int64(0); i <= m; i++ {
+		base := new(big.Int).Add(lo, big.NewInt(i*m))
+		if Q.IsZero {
+			return ec.PointOrder(P, base)
+		}
+		if j, ok := baby[Q.X.Value.String()]; ok {
+			jP := ec.ScalarMultP(P, big.NewInt(j).Bytes())
+			n := new(big.Int)
+			if jP.Y.Equals(Q.Y) {
+				// Q == j*P なので (base - j)*P == 0
+				n.Sub(base, big.NewInt(j))
+			} else {
+				// Q == -j*P なので (base + j)*P == 0
+				n.Add(base, big.NewInt(j))
+			}
+			return ec.PointOrder(P, n)
+		}
+		Q = ec.AddP(Q, mP)
+	}
+
+	// p > 229 の曲線なら Hasse の範囲に必ず #E があるので、ここには来ない
+	return nil, fmt.Errorf("%w: no multiple of the order of %v in [%v, %v]", ErrInvalidCurve, P, lo, hi)
+}
+
+// PointOrder() : 点Pの位数を求める
+// n は n*P == 0 となる正の整数 (群の位数など) で、nの素因数で割れるだけ割っていく
+// nが正でない場合は ErrNotPositive
+func (ec *EllipticCurve) PointOrder(P *EllipticCurvePoint, n *big.Int) (*big.Int, error) {
+	factors, err := Factorize(n)
+	if err != nil {
+		return nil, err
+	}
+
+	order := new(big.Int).Set(n)
+	for _, f := range factors {
+		for e := 0; e < f.Exponent; e++ {
+			m := new(big.Int).Quo(order, f.Prime)
+			if !ec.ScalarMultP(P, m.Bytes()).IsZero {
+				break
+			}
+			order = m
+		}
+	}
+	return order, nil
+}
+
+// Subgroup : 巡回部分群
+type Subgroup struct {
+	Order     *big.Int
+	Generator *EllipticCurvePoint
+	Count     int // この位数の巡回部分群の数
+}
+
+// Subgroups() : 曲線上の点が生成する巡回部分群を、位数ごとに小さい順で返す
+// 群が巡回群なら、最後の部分群の位数が #E で、その生成元が群全体の生成元になる
+// 群の構造は E ≅ Z/n1 × Z/n2 (n2 | n1) で、n1 は最後の部分群の位数になる
+// pが maxEnumerateBits ビットより大きい場合は ErrCurveTooLarge、奇素数でない場合は ErrInvalidCurve
+func (ec *EllipticCurve) Subgroups() ([]Subgroup, error) {
+	points, err := ec.Points()
+	if err != nil {
+		return nil, err
+	}
+
+	c := ec.countingCurve()
+	n := big.NewInt(int64(len(points)))
+
+	var subgroups []Subgroup
+	index := make(map[string]int)
+	for _, P := range points {
+		ord, err := c.PointOrder(P, n)
+		if err != nil {
+			return nil, err
+		}
+		key := ord.String()
+		if i, ok := index[key]; ok {
+			subgroups[i].Count++
+			continue
+		}
+		index[key] = len(subgroups)
+		subgroups = append(subgroups, Subgroup{Order: ord, Generator: P, Count: 1})
+	}
+
+	// Count はここまで位数dの点の数なので、1つの巡回部分群に含まれる生成元の数 φ(d) で割る
+	for i := range subgroups {
+		factors, err := Factorize(subgroups[i].Order)
+		if err != nil {
+			return nil, err
+		}
+		phi := eulerPhi(factors)
+		subgroups[i].Count /= int(phi.Int64())
+	}
+	sort.Slice(subgroups, func(i, j int) bool {
+		return subgroups[i].Order.Cmp(subgroups[j].Order) < 0
+	})
+	return subgroups, nil
+}
+
+// eulerPhi() : オイラーのφ関数 φ(Π q^e) = Π q^(e-1) * (q - 1)
+func eulerPhi(factors []PrimePower) *big.Int {
+	phi := big.NewInt(1)
+	for _, f := range factors {
+		phi.Mul(phi, new(big.Int).Sub(f.Prime, big.NewInt(1)))
+		for e := 1; e < f.Exponent; e++ {
+			phi.Mul(phi, f.Prime)
+		}
+	}
+	return phi
+}
+
+// FindSubgroupGenerator() : 位数 order の点を1つ探す
+// groupOrder は群の位数 #E で、order はその約数でなければならない
+// ランダムな点Pの位数が order の倍数 m なら (m / order) * P の位数が order になるので、
+// 群が巡回群でなくても使え、pが大きくても #E が素因数分解できれば使える
+// pが奇素数でない場合は ErrInvalidCurve
+func (ec *EllipticCurve) FindSubgroupGenerator(order, groupOrder *big.Int) (*EllipticCurvePoint, error) {
+	if order.Sign() <= 0 || groupOrder.Sign() <= 0 || new(big.Int).Mod(groupOrder, order).Sign() != 0 {
+		return nil, fmt.Errorf("models: %v does not divide the group order %v", order, groupOrder)
+	}
+	if err := ec.checkOddPrime(); err != nil {
+		return nil, err
+	}
+
+	c := ec.countingCurve()
+	r := rand.New(rand.NewSource(1))
+	m, rem := new(big.Int), new(big.Int)
+	for i := 0; i < 100; i++ {
+		P := c.randomPoint(r)
+		ord, err := c.PointOrder(P, groupOrder)
+		if err != nil {
+			return nil, err
+		}
+		m.QuoRem(ord, order, rem)
+		if rem.Sign() == 0 {
+			return c.ScalarMultP(P, m.Bytes()), nil
+		}
+	}
+	return nil, fmt.Errorf("models: no point of order %v found", order)
+}
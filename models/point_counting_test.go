@@ -0,0 +1,284 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// toyCurve() : y^2 = x^3 + a*x + b over F_p (生成元も位数も持たない)
+func toyCurve(a, b, p int64) *EllipticCurve {
+	prime := big.NewInt(p)
+	return NewEllipticCurve(
+		NewFiniteField(new(big.Int).Mod(big.NewInt(a), prime), prime),
+		NewFiniteField(new(big.Int).Mod(big.NewInt(b), prime), prime),
+		prime, nil, prime.BitLen(), "toy", nil,
+	)
+}
+
+func Test_EllipticCurve_Points(t *testing.T) {
+	ec := toyCurve(0, 7, 223)
+	points, err := ec.Points()
+	if err != nil {
+		t.Fatalf("Points() err = %v", err)
+	}
+	if len(points) != 252 {
+		t.Fatalf("Points() has %v points, want 252", len(points))
+	}
+	if !points[0].IsZero {
+		t.Errorf("Points()[0] = %v, want zero", points[0])
+	}
+
+	seen := make(map[string]bool)
+	for _, P := range points {
+		if !P.IsZero && !ec.IsOnCurve(P.X.Value, P.Y.Value) {
+			t.Errorf("Points() contains %v, which is not on the curve", P)
+		}
+		if seen[P.String()] {
+			t.Errorf("Points() contains %v twice", P)
+		}
+		seen[P.String()] = true
+	}
+	for _, P := range allPoints(ec) {
+		if !seen[P.String()] {
+			t.Errorf("Points() does not contain %v", P)
+		}
+	}
+
+	if _, err := testSecp256k1().Points(); !errors.Is(err, ErrCurveTooLarge) {
+		t.Errorf("secp256k1 : Points() err = %v, want %v", err, ErrCurveTooLarge)
+	}
+}
+
+func Test_EllipticCurve_Order(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+		want int64
+	}{
+		{name: "y^2 = x^3 + 7 over F_223", ec: toyCurve(0, 7, 223), want: 252},
+		{name: "y^2 = x^3 + 7x + 1 over F_223", ec: toyCurve(7, 1, 223), want: 223},
+		{name: "y^2 = x^3 + x + 1 over F_1009", ec: toyCurve(1, 1, 1009), want: 1034},
+		{name: "y^2 = x^3 + 2x + 3 over F_10007", ec: toyCurve(2, 3, 10007), want: 9846},
+		{name: "y^2 = x^3 + 7 over F_65521", ec: toyCurve(0, 7, 65521), want: 65691},
+		{name: "y^2 = x^3 - 3x + 5 over F_65521", ec: toyCurve(-3, 5, 65521), want: 65649},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := big.NewInt(tt.want)
+			if got, err := tt.ec.OrderNaive(); err != nil || got.Cmp(want) != 0 {
+				t.Errorf("%v : OrderNaive() = %v, %v, want %v", tt.name, got, err, want)
+			}
+			if got, err := tt.ec.OrderBSGS(); err != nil || got.Cmp(want) != 0 {
+				t.Errorf("%v : OrderBSGS() = %v, %v, want %v", tt.name, got, err, want)
+			}
+			if got, err := tt.ec.Order(); err != nil || got.Cmp(want) != 0 {
+				t.Errorf("%v : Order() = %v, %v, want %v", tt.name, got, err, want)
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_OrderBSGS_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for _, p := range []int64{1009, 4093, 65521} {
+		for i := 0; i < 10; i++ {
+			ec := toyCurve(r.Int63n(p), r.Int63n(p), p)
+			want, _ := ec.OrderNaive()
+			if got, err := ec.OrderBSGS(); err != nil || got.Cmp(want) != 0 {
+				t.Errorf("%v : OrderBSGS() = %v, %v, want %v", ec.a, got, err, want)
+			}
+		}
+	}
+}
+
+func Test_EllipticCurve_OrderBSGS_Large(t *testing.T) {
+	// 2^40 + 15
+	ec := toyCurve(0, 7, 1099511627791)
+	n, err := ec.Order()
+	if err != nil {
+		t.Fatalf("Order() err = %v", err)
+	}
+
+	lo, hi := hasseInterval(ec.prime)
+	if n.Cmp(lo) < 0 || n.Cmp(hi) > 0 {
+		t.Errorf("Order() = %v, not in [%v, %v]", n, lo, hi)
+	}
+	r := rand.New(rand.NewSource(4))
+	c := ec.countingCurve()
+	for i := 0; i < 10; i++ {
+		P := c.randomPoint(r)
+		if nP := c.ScalarMultP(P, n.Bytes()); !nP.IsZero {
+			t.Errorf("Order() = %v, but %v * %v = %v", n, n, P, nP)
+		}
+	}
+
+	// secp256k1 の p は大きすぎる
+	if _, err := testSecp256k1().OrderBSGS(); !errors.Is(err, ErrCurveTooLarge) {
+		t.Errorf("secp256k1 : OrderBSGS() err = %v, want %v", err, ErrCurveTooLarge)
+	}
+}
+
+func Test_EllipticCurve_PointOrder(t *testing.T) {
+	ec := toyCurve(0, 7, 223)
+	point := func(x, y int64) *EllipticCurvePoint {
+		return NewEllipticCurvePoint(NewFiniteField(big.NewInt(x), ec.prime), NewFiniteField(big.NewInt(y), ec.prime), false)
+	}
+
+	tests := []struct {
+		name string
+		P    *EllipticCurvePoint
+		want int64
+	}{
+		{name: "zero", P: NewEllipticCurvePoint(nil, nil, true), want: 1},
+		{name: "(15, 86)", P: point(15, 86), want: 7},
+		{name: "(47, 71)", P: point(47, 71), want: 21},
+		{name: "(17, 56)", P: point(17, 56), want: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ec.PointOrder(tt.P, big.NewInt(252)); err != nil || got.Int64() != tt.want {
+				t.Errorf("%v : PointOrder() = %v, %v, want %v", tt.name, got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := ec.PointOrder(point(15, 86), big.NewInt(0)); !errors.Is(err, ErrNotPositive) {
+		t.Errorf("PointOrder(n = 0) err = %v, want %v", err, ErrNotPositive)
+	}
+}
+
+func Test_EllipticCurve_Subgroups(t *testing.T) {
+	tests := []struct {
+		name       string
+		ec         *EllipticCurve
+		wantOrders []int64
+		wantCounts []int
+	}{
+		{
+			// E ≅ Z/42 × Z/6 なので巡回群ではない
+			name:       "y^2 = x^3 + 7 over F_223",
+			ec:         toyCurve(0, 7, 223),
+			wantOrders: []int64{1, 2, 3, 6, 7, 14, 21, 42},
+			wantCounts: []int{1, 3, 4, 12, 1, 3, 4, 12},
+		},
+		{
+			name:       "y^2 = x^3 + 7x + 1 over F_223",
+			ec:         toyCurve(7, 1, 223),
+			wantOrders: []int64{1, 223},
+			wantCounts: []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subgroups, err := tt.ec.Subgroups()
+			if err != nil {
+				t.Fatalf("%v : Subgroups() err = %v", tt.name, err)
+			}
+			if len(subgroups) != len(tt.wantOrders) {
+				t.Fatalf("%v : Subgroups() has %v subgroups, want %v", tt.name, len(subgroups), len(tt.wantOrders))
+			}
+			for i, s := range subgroups {
+				if s.Order.Int64() != tt.wantOrders[i] || s.Count != tt.wantCounts[i] {
+					t.Errorf("%v : Subgroups()[%d] order, count = %v, %v, want %v, %v", tt.name, i, s.Order, s.Count, tt.wantOrders[i], tt.wantCounts[i])
+				}
+				if got, err := tt.ec.PointOrder(s.Generator, s.Order); err != nil || got.Cmp(s.Order) != 0 {
+					t.Errorf("%v : Subgroups()[%d].Generator %v has order %v, %v, want %v", tt.name, i, s.Generator, got, err, s.Order)
+				}
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_FindSubgroupGenerator(t *testing.T) {
+	ec := toyCurve(0, 7, 223)
+	groupOrder := big.NewInt(252)
+
+	tests := []struct {
+		name    string
+		order   int64
+		wantErr bool
+	}{
+		{name: "order 7", order: 7},
+		{name: "order 42", order: 42},
+		{name: "order 1", order: 1},
+		{name: "not a divisor", order: 5, wantErr: true},
+		// 巡回群ではないので、位数 #E の点はない
+		{name: "group order", order: 252, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := big.NewInt(tt.order)
+			G, err := ec.FindSubgroupGenerator(order, groupOrder)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%v : FindSubgroupGenerator() err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, err := ec.PointOrder(G, groupOrder); err != nil || got.Cmp(order) != 0 {
+				t.Errorf("%v : FindSubgroupGenerator() = %v with order %v, %v, want %v", tt.name, G, got, err, order)
+			}
+		})
+	}
+}
+
+// pが奇素数でない曲線は、点を数える前に ErrInvalidCurve になる
+func Test_EllipticCurve_PointCounting_NotOddPrime(t *testing.T) {
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "p = 2", ec: toyCurve(1, 1, 2)},
+		{name: "p = 221 = 13 * 17", ec: toyCurve(0, 7, 221)},
+		{name: "p = 10403 = 101 * 103", ec: toyCurve(2, 3, 10403)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.ec.Points(); !errors.Is(err, ErrInvalidCurve) {
+				t.Errorf("%v : Points() err = %v, want %v", tt.name, err, ErrInvalidCurve)
+			}
+			if _, err := tt.ec.OrderNaive(); !errors.Is(err, ErrInvalidCurve) {
+				t.Errorf("%v : OrderNaive() err = %v, want %v", tt.name, err, ErrInvalidCurve)
+			}
+			if _, err := tt.ec.OrderBSGS(); !errors.Is(err, ErrInvalidCurve) {
+				t.Errorf("%v : OrderBSGS() err = %v, want %v", tt.name, err, ErrInvalidCurve)
+			}
+			if _, err := tt.ec.Subgroups(); !errors.Is(err, ErrInvalidCurve) {
+				t.Errorf("%v : Subgroups() err = %v, want %v", tt.name, err, ErrInvalidCurve)
+			}
+			if _, err := tt.ec.FindSubgroupGenerator(big.NewInt(1), big.NewInt(4)); !errors.Is(err, ErrInvalidCurve) {
+				t.Errorf("%v : FindSubgroupGenerator() err = %v, want %v", tt.name, err, ErrInvalidCurve)
+			}
+		})
+	}
+}
+
+// 自分で選んだパラメータから、素数位数の部分群を持つ曲線を作る
+func Test_EllipticCurve_DeriveTeachingCurve(t *testing.T) {
+	ec := toyCurve(2, 3, 10007)
+	n, err := ec.Order()
+	if err != nil {
+		t.Fatalf("Order() err = %v", err)
+	}
+	factors, err := Factorize(n)
+	if err != nil {
+		t.Fatalf("Factorize(%v) err = %v", n, err)
+	}
+	q := factors[len(factors)-1].Prime
+
+	G, err := ec.FindSubgroupGenerator(q, n)
+	if err != nil {
+		t.Fatalf("FindSubgroupGenerator(%v, %v) err = %v", q, n, err)
+	}
+	_, report, err := NewEllipticCurveValidated(ec.a, ec.b, ec.prime, G, ec.bitSize, "teaching", q)
+	if err != nil {
+		t.Errorf("NewEllipticCurveValidated() err = %v\n%v", err, report)
+	}
+}
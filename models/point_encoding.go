@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidPointEncoding = errors.New("models: invalid point encoding")
+
+// SEC1 2.3.3 のプレフィックス
+const (
+	pointInfinity     = 0x00
+	pointCompressedY0 = 0x02
+	pointCompressedY1 = 0x03
+	pointUncompressed = 0x04
+	pointHybridY0     = 0x06
+	pointHybridY1     = 0x07
+)
+
+// coordinateLen() : 座標1つ分のバイト長
+func coordinateLen(prime *big.Int) int {
+	return (prime.BitLen() + 7) / 8
+}
+
+// MarshalCompressed() : 点を SEC1 の圧縮形式 (0x02 or 0x03) || x にエンコードする
+// 無限遠点は 0x00 の1バイトになる
+func (p *EllipticCurvePoint) MarshalCompressed() []byte {
+	if p.IsZero {
+		return []byte{pointInfinity}
+	}
+
+	size := coordinateLen(p.X.Prime)
+	b := make([]byte, 1+size)
+	b[0] = pointCompressedY0 | byte(p.Y.Value.Bit(0))
+	p.X.Value.FillBytes(b[1:])
+	return b
+}
+
+// MarshalUncompressed() : 点を SEC1 の非圧縮形式 0x04 || x || y にエンコードする
+// 無限遠点は 0x00 の1バイトになる
+func (p *EllipticCurvePoint) MarshalUncompressed() []byte {
+	if p.IsZero {
+		return []byte{pointInfinity}
+	}
+
+	size := coordinateLen(p.X.Prime)
+	b := make([]byte, 1+2*size)
+	b[0] = pointUncompressed
+	p.X.Value.FillBytes(b[1 : 1+size])
+	p.Y.Value.FillBytes(b[1+size:])
+	return b
+}
+
+// ParsePointOption : ParsePoint() で受け付けるエンコードを増やすオプション
+type ParsePointOption func(*parsePointConfig)
+
+type parsePointConfig struct {
+	allowInfinity bool
+	allowHybrid   bool
+}
+
+// AllowInfinity() : 0x00 の1バイトを無限遠点として受け付ける
+func AllowInfinity() ParsePointOption {
+	return func(c *parsePointConfig) {
+		c.allowInfinity = true
+	}
+}
+
+// AllowHybrid() : ハイブリッド形式 (0x06 or 0x07) || x || y を受け付ける
+func AllowHybrid() ParsePointOption {
+	return func(c *parsePointConfig) {
+		c.allowHybrid = true
+	}
+}
+
+// ParsePoint() : SEC1 の圧縮形式・非圧縮形式でエンコードされた点をパースする
+// 圧縮形式の場合は y^2 = x^3 + a*x + b の平方根を計算してyを復元する
+// 曲線上にない点はエラーになる
+func (ec *EllipticCurve) ParsePoint(b []byte, opts ...ParsePointOption) (*EllipticCurvePoint, error) {
+	var config parsePointConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if len(b) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidPointEncoding)
+	}
+
+	size := coordinateLen(ec.prime)
+
+	switch b[0] {
+	case pointInfinity:
+		if !config.allowInfinity {
+			return nil, fmt.Errorf("%w: point at infinity", ErrInvalidPointEncoding)
+		}
+		if len(b) != 1 {
+			return nil, fmt.Errorf("%w: invalid length %d for infinity", ErrInvalidPointEncoding, len(b))
+		}
+		return NewEllipticCurvePoint(nil, nil, true), nil
+
+	case pointCompressedY0, pointCompressedY1:
+		if len(b) != 1+size {
+			return nil, fmt.Errorf("%w: invalid length %d for compressed point", ErrInvalidPointEncoding, len(b))
+		}
+		x := new(big.Int).SetBytes(b[1:])
+		if x.Cmp(ec.prime) >= 0 {
+			return nil, fmt.Errorf("%w: x out of range", ErrInvalidPointEncoding)
+		}
+		p, ok := ec.LiftX(x, b[0] == pointCompressedY1)
+		if !ok {
+			return nil, fmt.Errorf("%w: point not on curve", ErrInvalidPointEncoding)
+		}
+		return p, nil
+
+	case pointUncompressed, pointHybridY0, pointHybridY1:
+		if b[0] != pointUncompressed && !config.allowHybrid {
+			return nil, fmt.Errorf("%w: hybrid encoding", ErrInvalidPointEncoding)
+		}
+		if len(b) != 1+2*size {
+			return nil, fmt.Errorf("%w: invalid length %d for uncompressed point", ErrInvalidPointEncoding, len(b))
+		}
+		x := new(big.Int).SetBytes(b[1 : 1+size])
+		y := new(big.Int).SetBytes(b[1+size:])
+		if x.Cmp(ec.prime) >= 0 || y.Cmp(ec.prime) >= 0 {
+			return nil, fmt.Errorf("%w: coordinate out of range", ErrInvalidPointEncoding)
+		}
+		if b[0] != pointUncompressed && y.Bit(0) != uint(b[0]&1) {
+			return nil, fmt.Errorf("%w: hybrid prefix does not match y", ErrInvalidPointEncoding)
+		}
+
+		p := NewEllipticCurvePoint(NewFiniteField(x, ec.prime), NewFiniteField(y, ec.prime), false)
+		if !ec.IsOnCurveP(p) {
+			return nil, fmt.Errorf("%w: point not on curve", ErrInvalidPointEncoding)
+		}
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown prefix 0x%02x", ErrInvalidPointEncoding, b[0])
+}
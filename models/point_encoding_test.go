@@ -0,0 +1,184 @@
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func testHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex: " + s)
+	}
+	return n
+}
+
+func testSecp256k1() *EllipticCurve {
+	prime := testHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F")
+	G := NewEllipticCurvePoint(
+		NewFiniteField(testHex("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798"), prime),
+		NewFiniteField(testHex("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8"), prime),
+		false,
+	)
+	return NewEllipticCurve(
+		NewFiniteField(big.NewInt(0), prime),
+		NewFiniteField(big.NewInt(7), prime),
+		prime,
+		G,
+		256,
+		"secp256k1",
+		testHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141"),
+	)
+}
+
+func Test_EllipticCurvePoint_Marshal(t *testing.T) {
+	secp256k1 := testSecp256k1()
+	prime := secp256k1.prime
+	toy := big.NewInt(223)
+
+	tests := []struct {
+		name             string
+		p                *EllipticCurvePoint
+		wantCompressed   string
+		wantUncompressed string
+	}{
+		{
+			name:             "secp256k1 G",
+			p:                secp256k1.g,
+			wantCompressed:   "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+			wantUncompressed: "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+		},
+		{
+			name: "secp256k1 2G",
+			p: NewEllipticCurvePoint(
+				NewFiniteField(testHex("C6047F9441ED7D6D3045406E95C07CD85C778E4B8CEF3CA7ABAC09B95C709EE5"), prime),
+				NewFiniteField(testHex("1AE168FEA63DC339A3C58419466CEAEEF7F632653266D0E1236431A950CFE52A"), prime),
+				false,
+			),
+			wantCompressed:   "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+			wantUncompressed: "04c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee51ae168fea63dc339a3c58419466ceaeef7f632653266d0e1236431a950cfe52a",
+		},
+		{
+			name: "toy curve (47, 71)",
+			p: NewEllipticCurvePoint(
+				NewFiniteField(big.NewInt(47), toy),
+				NewFiniteField(big.NewInt(71), toy),
+				false,
+			),
+			wantCompressed:   "032f",
+			wantUncompressed: "042f47",
+		},
+		{
+			name:             "infinity",
+			p:                NewEllipticCurvePoint(nil, nil, true),
+			wantCompressed:   "00",
+			wantUncompressed: "00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(tt.p.MarshalCompressed()); got != tt.wantCompressed {
+				t.Errorf("%v : MarshalCompressed() = %v, want %v", tt.name, got, tt.wantCompressed)
+			}
+			if got := hex.EncodeToString(tt.p.MarshalUncompressed()); got != tt.wantUncompressed {
+				t.Errorf("%v : MarshalUncompressed() = %v, want %v", tt.name, got, tt.wantUncompressed)
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_ParsePoint(t *testing.T) {
+	ec := testSecp256k1()
+	G := ec.g
+
+	gx := "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	gy := "483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+	negGy := hex.EncodeToString(new(FiniteField).Neg(G.Y).Value.Bytes())
+	p := "fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f"
+
+	tests := []struct {
+		name    string
+		hex     string
+		opts    []ParsePointOption
+		want    *EllipticCurvePoint
+		wantErr bool
+	}{
+		{name: "compressed G", hex: "02" + gx, want: G},
+		{name: "compressed -G", hex: "03" + gx, want: NewEllipticCurvePoint(G.X, new(FiniteField).Neg(G.Y), false)},
+		{name: "uncompressed G", hex: "04" + gx + gy, want: G},
+		{name: "uncompressed -G", hex: "04" + gx + negGy, want: NewEllipticCurvePoint(G.X, new(FiniteField).Neg(G.Y), false)},
+		{name: "hybrid G", hex: "06" + gx + gy, opts: []ParsePointOption{AllowHybrid()}, want: G},
+		{name: "hybrid G not allowed", hex: "06" + gx + gy, wantErr: true},
+		{name: "hybrid prefix mismatch", hex: "07" + gx + gy, opts: []ParsePointOption{AllowHybrid()}, wantErr: true},
+		{name: "infinity", hex: "00", opts: []ParsePointOption{AllowInfinity()}, want: NewEllipticCurvePoint(nil, nil, true)},
+		{name: "infinity not allowed", hex: "00", wantErr: true},
+		{name: "infinity with trailing bytes", hex: "0000", opts: []ParsePointOption{AllowInfinity()}, wantErr: true},
+		{name: "empty", hex: "", wantErr: true},
+		{name: "unknown prefix", hex: "05" + gx, wantErr: true},
+		{name: "compressed too short", hex: "02" + gx[2:], wantErr: true},
+		{name: "uncompressed too short", hex: "04" + gx, wantErr: true},
+		{name: "compressed x = p", hex: "02" + p, wantErr: true},
+		{name: "compressed x not on curve", hex: "02" + "0000000000000000000000000000000000000000000000000000000000000005", wantErr: true},
+		{name: "uncompressed y = p", hex: "04" + gx + p, wantErr: true},
+		{name: "uncompressed not on curve", hex: "04" + gx + gx, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ec.ParsePoint(b, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%v : EllipticCurve.ParsePoint() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidPointEncoding) {
+					t.Errorf("%v : EllipticCurve.ParsePoint() error = %v, want ErrInvalidPointEncoding", tt.name, err)
+				}
+				return
+			}
+			if !got.equals(tt.want) {
+				t.Errorf("%v : EllipticCurve.ParsePoint() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_ParsePoint_RoundTrip(t *testing.T) {
+	prime := big.NewInt(223)
+	ec := NewEllipticCurve(
+		NewFiniteField(big.NewInt(0), prime),
+		NewFiniteField(big.NewInt(7), prime),
+		prime,
+		nil,
+		0,
+		"test elliptic curve",
+		nil,
+	)
+
+	// F_223 上の y^2 = x^3 + 7 の点をすべて試す
+	for x := int64(0); x < 223; x++ {
+		for _, odd := range []bool{false, true} {
+			p, ok := ec.LiftX(big.NewInt(x), odd)
+			if !ok {
+				continue
+			}
+
+			for _, b := range [][]byte{p.MarshalCompressed(), p.MarshalUncompressed()} {
+				got, err := ec.ParsePoint(b)
+				if err != nil {
+					t.Fatalf("EllipticCurve.ParsePoint(%x) error = %v", b, err)
+				}
+				if !got.equals(p) {
+					t.Errorf("EllipticCurve.ParsePoint(%x) = %v, want %v", b, got, p)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package models
+
+// secp256k1Point : fieldVal で表したヤコビアン座標の点 (secp256k1 専用)
+// jacobianPoint と同じく (X / Z^2, Y / Z^3) を表し、Z == 0 のときは無限遠点
+// 値で持ち回せるので、演算のたびにメモリを確保しない
+type secp256k1Point struct {
+	x, y, z fieldVal
+}
+
+func (p *secp256k1Point) isZero() bool {
+	return p.z.isZero()
+}
+
+// secp256k1Arithmetic : secp256k1 (a = 0) 専用の pointArithmetic
+type secp256k1Arithmetic struct{}
+
+// zero() : 無限遠点 (1 : 1 : 0)
+func (secp256k1Arithmetic) zero() secp256k1Point {
+	return secp256k1Point{x: fieldVal{1}, y: fieldVal{1}}
+}
+
+// fromAffine() : (x, y) -> (x : y : 1)
+func (g secp256k1Arithmetic) fromAffine(p *EllipticCurvePoint) secp256k1Point {
+	if p.IsZero {
+		return g.zero()
+	}
+	var r secp256k1Point
+	r.x.setBig(p.X.Value)
+	r.y.setBig(p.Y.Value)
+	r.z.setInt(1)
+	return r
+}
+
+// toAffine() : (X : Y : Z) -> (X / Z^2, Y / Z^3)
+func (secp256k1Arithmetic) toAffine(p secp256k1Point) *EllipticCurvePoint {
+	if p.isZero() {
+		return NewEllipticCurvePoint(nil, nil, true)
+	}
+
+	var zInv, zInv2, x, y fieldVal
+	zInv.inverse(&p.z)
+	zInv2.square(&zInv)
+	x.mul(&p.x, &zInv2)
+	y.mul(&p.y, zInv2.mul(&zInv2, &zInv))
+
+	return NewEllipticCurvePoint(
+		NewFiniteField(x.toBig(), secp256k1Prime),
+		NewFiniteField(y.toBig(), secp256k1Prime),
+		false,
+	)
+}
+
+// double() : 2P を求める (dbl-2009-l)
+// secp256k1 には位数2の点がないので、Y == 0 の場合を考えなくてよい
+// 無限遠点を渡すと Z3 = 0 になり、そのまま無限遠点が返る
+func (secp256k1Arithmetic) double(p secp256k1Point) secp256k1Point {
+	var a, b, c, d, e, f fieldVal
+
+	// A = X1^2, B = Y1^2, C = B^2
+	a.square(&p.x)
+	b.square(&p.y)
+	c.square(&b)
+
+	// D = 2 * ((X1 + B)^2 - A - C)
+	d.add(&p.x, &b)
+	d.square(&d)
+	d.sub(&d, &a)
+	d.sub(&d, &c)
+	d.add(&d, &d)
+
+	// E = 3 * A, F = E^2
+	e.add(&a, &a)
+	e.add(&e, &a)
+	f.square(&e)
+
+	var r secp256k1Point
+
+	// Z3 = 2 * Y1 * Z1
+	r.z.mul(&p.y, &p.z)
+	r.z.add(&r.z, &r.z)
+
+	// X3 = F - 2 * D
+	r.x.sub(&f, &d)
+	r.x.sub(&r.x, &d)
+
+	// Y3 = E * (D - X3) - 8 * C
+	c.add(&c, &c)
+	c.add(&c, &c)
+	c.add(&c, &c)
+	r.y.sub(&d, &r.x)
+	r.y.mul(&r.y, &e)
+	r.y.sub(&r.y, &c)
+
+	return r
+}
+
+// add() : P + Q を求める (add-2007-bl)
+// Q の Z == 1 の場合は乗算の少ない addMixed() を使う
+func (g secp256k1Arithmetic) add(p, q secp256k1Point) secp256k1Point {
+	if p.isZero() {
+		return q
+	}
+	if q.isZero() {
+		return p
+	}
+	if q.z.isOne() {
+		return g.addMixed(p, q)
+	}
+
+	var z1z1, z2z2, u1, u2, s1, s2, h, r fieldVal
+
+	// Z1Z1 = Z1^2, Z2Z2 = Z2^2
+	z1z1.square(&p.z)
+	z2z2.square(&q.z)
+
+	// U1 = X1 * Z2Z2, U2 = X2 * Z1Z1
+	u1.mul(&p.x, &z2z2)
+	u2.mul(&q.x, &z1z1)
+
+	// S1 = Y1 * Z2 * Z2Z2, S2 = Y2 * Z1 * Z1Z1
+	s1.mul(&p.y, &q.z)
+	s1.mul(&s1, &z2z2)
+	s2.mul(&q.y, &p.z)
+	s2.mul(&s2, &z1z1)
+
+	// H = U2 - U1, r = 2 * (S2 - S1)
+	h.sub(&u2, &u1)
+	r.sub(&s2, &s1)
+	r.add(&r, &r)
+
+	if h.isZero() {
+		// P == Q なら2倍算、P == -Q なら無限遠点
+		if r.isZero() {
+			return g.double(p)
+		}
+		return g.zero()
+	}
+
+	// I = (2 * H)^2, J = H * I, V = U1 * I
+	var i, j, v fieldVal
+	i.add(&h, &h)
+	i.square(&i)
+	j.mul(&h, &i)
+	v.mul(&u1, &i)
+
+	var res secp256k1Point
+
+	// X3 = r^2 - J - 2 * V
+	res.x.square(&r)
+	res.x.sub(&res.x, &j)
+	res.x.sub(&res.x, &v)
+	res.x.sub(&res.x, &v)
+
+	// Y3 = r * (V - X3) - 2 * S1 * J
+	s1.mul(&s1, &j)
+	res.y.sub(&v, &res.x)
+	res.y.mul(&res.y, &r)
+	res.y.sub(&res.y, &s1)
+	res.y.sub(&res.y, &s1)
+
+	// Z3 = ((Z1 + Z2)^2 - Z1Z1 - Z2Z2) * H
+	res.z.add(&p.z, &q.z)
+	res.z.square(&res.z)
+	res.z.sub(&res.z, &z1z1)
+	res.z.sub(&res.z, &z2z2)
+	res.z.mul(&res.z, &h)
+
+	return res
+}
+
+// addMixed() : Z2 == 1 の点Qとの和を求める (madd-2007-bl)
+func (g secp256k1Arithmetic) addMixed(p, q secp256k1Point) secp256k1Point {
+	if p.isZero() {
+		return q
+	}
+
+	var z1z1, u2, s2, h, r fieldVal
+
+	// Z1Z1 = Z1^2, U2 = X2 * Z1Z1, S2 = Y2 * Z1 * Z1Z1
+	z1z1.square(&p.z)
+	u2.mul(&q.x, &z1z1)
+	s2.mul(&q.y, &p.z)
+	s2.mul(&s2, &z1z1)
+
+	// H = U2 - X1, r = 2 * (S2 - Y1)
+	h.sub(&u2, &p.x)
+	r.sub(&s2, &p.y)
+	r.add(&r, &r)
+
+	if h.isZero() {
+		if r.isZero() {
+			return g.double(p)
+		}
+		return g.zero()
+	}
+
+	// HH = H^2, I = 4 * HH, J = H * I, V = X1 * I
+	var hh, i, j, v fieldVal
+	hh.square(&h)
+	i.add(&hh, &hh)
+	i.add(&i, &i)
+	j.mul(&h, &i)
+	v.mul(&p.x, &i)
+
+	var res secp256k1Point
+
+	// X3 = r^2 - J - 2 * V
+	res.x.square(&r)
+	res.x.sub(&res.x, &j)
+	res.x.sub(&res.x, &v)
+	res.x.sub(&res.x, &v)
+
+	// Y3 = r * (V - X3) - 2 * Y1 * J
+	var y1j fieldVal
+	y1j.mul(&p.y, &j)
+	res.y.sub(&v, &res.x)
+	res.y.mul(&res.y, &r)
+	res.y.sub(&res.y, &y1j)
+	res.y.sub(&res.y, &y1j)
+
+	// Z3 = (Z1 + H)^2 - Z1Z1 - HH
+	res.z.add(&p.z, &h)
+	res.z.square(&res.z)
+	res.z.sub(&res.z, &z1z1)
+	res.z.sub(&res.z, &hh)
+
+	return res
+}
+
+func (secp256k1Arithmetic) neg(p secp256k1Point) secp256k1Point {
+	p.y.neg(&p.y)
+	return p
+}
+
+// normalize() : Montgomery の方法で、逆元の計算1回ですべての点を Z == 1 にそろえる
+// acc[i] = Z_0 * Z_1 * ... * Z_{i-1} とすると、1/Z_i = acc[i] / (Z_0 * ... * Z_i)
+func (secp256k1Arithmetic) normalize(ps []secp256k1Point) {
+	acc := make([]fieldVal, len(ps))
+	var prod fieldVal
+	prod.setInt(1)
+	for i := range ps {
+		acc[i] = prod
+		if !ps[i].isZero() {
+			prod.mul(&prod, &ps[i].z)
+		}
+	}
+
+	var inv fieldVal
+	inv.inverse(&prod)
+	for i := len(ps) - 1; i >= 0; i-- {
+		p := &ps[i]
+		if p.isZero() {
+			continue
+		}
+
+		// zInv = 1/Z_i, inv = 1/(Z_0 * ... * Z_{i-1})
+		var zInv, zInv2 fieldVal
+		zInv.mul(&inv, &acc[i])
+		inv.mul(&inv, &p.z)
+
+		zInv2.square(&zInv)
+		p.x.mul(&p.x, &zInv2)
+		p.y.mul(&p.y, zInv2.mul(&zInv2, &zInv))
+		p.z.setInt(1)
+	}
+}
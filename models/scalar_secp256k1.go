@@ -0,0 +1,196 @@
+package models
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// scalarVal : secp256k1 の位数 N を法とする整数 (スカラー)
+// fieldVal と同じく 64ビット×4 (リトルエンディアン) で、常に [0, N) に正規化しておく
+type scalarVal [4]uint64
+
+var (
+	scalarOrder = scalarVal{0xBFD25E8CD0364141, 0xBAAEDCE6AF48A03B, 0xFFFFFFFFFFFFFFFE, 0xFFFFFFFFFFFFFFFF}
+
+	// 2^256 - N (2^256 = 2^256 - N (mod N) を使って畳み込む)
+	scalarReduction = [3]uint64{0x402DA1732FC9BEBF, 0x4551231950B75FC4, 0x1}
+
+	// N - 2 (逆元: x^(N-2))
+	scalarNMinus2 = [4]uint64{0xBFD25E8CD036413F, 0xBAAEDCE6AF48A03B, 0xFFFFFFFFFFFFFFFE, 0xFFFFFFFFFFFFFFFF}
+
+	secp256k1Order = new(big.Int).SetBytes(scalarOrder.bytes())
+)
+
+// setBig() : z = x mod N
+func (z *scalarVal) setBig(x *big.Int) *scalarVal {
+	var b [32]byte
+	new(big.Int).Mod(x, secp256k1Order).FillBytes(b[:])
+	return z.setBytes(&b)
+}
+
+// setBytes() : z = b mod N (bはbig-endian)
+func (z *scalarVal) setBytes(b *[32]byte) *scalarVal {
+	var t scalarVal
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			t[i] |= uint64(b[31-8*i-j]) << (8 * j)
+		}
+	}
+	// 2^256 < 2N なので1回引けば十分
+	return z.reduce(&t, 0)
+}
+
+// bytes() : 32バイトのbig-endianに変換する
+func (z *scalarVal) bytes() []byte {
+	b := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			b[31-8*i-j] = byte(z[i] >> (8 * j))
+		}
+	}
+	return b
+}
+
+func (z *scalarVal) toBig() *big.Int {
+	return new(big.Int).SetBytes(z.bytes())
+}
+
+func (z *scalarVal) isZero() bool {
+	return z[0]|z[1]|z[2]|z[3] == 0
+}
+
+func (z *scalarVal) equal(x *scalarVal) bool {
+	return (z[0]^x[0])|(z[1]^x[1])|(z[2]^x[2])|(z[3]^x[3]) == 0
+}
+
+// bit() : 下からi番目のビット
+func (z *scalarVal) bit(i int) uint64 {
+	return z[i/64] >> (uint(i) % 64) & 1
+}
+
+// reduce() : z = t + carry * 2^256 mod N (t + carry * 2^256 < 2N であること)
+func (z *scalarVal) reduce(t *scalarVal, carry uint64) *scalarVal {
+	var u scalarVal
+	var b uint64
+	u[0], b = bits.Sub64(t[0], scalarOrder[0], 0)
+	u[1], b = bits.Sub64(t[1], scalarOrder[1], b)
+	u[2], b = bits.Sub64(t[2], scalarOrder[2], b)
+	u[3], b = bits.Sub64(t[3], scalarOrder[3], b)
+
+	mask := -(carry | (b ^ 1))
+	for i := 0; i < 4; i++ {
+		z[i] = t[i] ^ ((t[i] ^ u[i]) & mask)
+	}
+	return z
+}
+
+// add() : z = x + y mod N
+func (z *scalarVal) add(x, y *scalarVal) *scalarVal {
+	var t scalarVal
+	var c uint64
+	t[0], c = bits.Add64(x[0], y[0], 0)
+	t[1], c = bits.Add64(x[1], y[1], c)
+	t[2], c = bits.Add64(x[2], y[2], c)
+	t[3], c = bits.Add64(x[3], y[3], c)
+	return z.reduce(&t, c)
+}
+
+// sub() : z = x - y mod N
+func (z *scalarVal) sub(x, y *scalarVal) *scalarVal {
+	var t scalarVal
+	var b uint64
+	t[0], b = bits.Sub64(x[0], y[0], 0)
+	t[1], b = bits.Sub64(x[1], y[1], b)
+	t[2], b = bits.Sub64(x[2], y[2], b)
+	t[3], b = bits.Sub64(x[3], y[3], b)
+
+	// 桁借りがあれば N を足す
+	mask := -b
+	var c uint64
+	z[0], c = bits.Add64(t[0], scalarOrder[0]&mask, 0)
+	z[1], c = bits.Add64(t[1], scalarOrder[1]&mask, c)
+	z[2], c = bits.Add64(t[2], scalarOrder[2]&mask, c)
+	z[3], _ = bits.Add64(t[3], scalarOrder[3]&mask, c)
+	return z
+}
+
+// neg() : z = -x mod N
+func (z *scalarVal) neg(x *scalarVal) *scalarVal {
+	var zero scalarVal
+	return z.sub(&zero, x)
+}
+
+// mul() : z = x * y mod N
+func (z *scalarVal) mul(x, y *scalarVal) *scalarVal {
+	var r [8]uint64
+	for i := 0; i < 4; i++ {
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(x[i], y[j])
+			var cc uint64
+			lo, cc = bits.Add64(lo, r[i+j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			r[i+j] = lo
+			c = hi
+		}
+		r[i+4] = c
+	}
+	return z.reduceWide(&r)
+}
+
+// reduceWide() : 512ビットの r を N で割った余りを z に入れる
+// 2^256 - N は129ビットしかないので、上位の桁を3回畳み込めば 2N 未満になる
+func (z *scalarVal) reduceWide(r *[8]uint64) *scalarVal {
+	var t1 [7]uint64 // < 2^256 + 2^385
+	scalarFold(t1[:], r[:])
+	var t2 [5]uint64 // < 2^256 + 2^259
+	scalarFold(t2[:], t1[:])
+	var t3 [5]uint64 // < 2^256 + 2^133
+	scalarFold(t3[:], t2[:])
+
+	t := scalarVal{t3[0], t3[1], t3[2], t3[3]}
+	return z.reduce(&t, t3[4])
+}
+
+// scalarFold() : out = v[:4] + v[4:] * (2^256 - N)
+// outの長さは結果が収まるように呼び出し側で決める
+func scalarFold(out, v []uint64) {
+	for i := range out {
+		out[i] = 0
+	}
+	copy(out, v[:4])
+
+	for i, h := range v[4:] {
+		var c uint64
+		for j, n := range scalarReduction {
+			hi, lo := bits.Mul64(h, n)
+			var cc uint64
+			lo, cc = bits.Add64(lo, out[i+j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			out[i+j] = lo
+			c = hi
+		}
+		for k := i + len(scalarReduction); k < len(out); k++ {
+			out[k], c = bits.Add64(out[k], c, 0)
+		}
+	}
+}
+
+// inverse() : z = 1/x mod N (x == 0 のときは 0)
+func (z *scalarVal) inverse(x *scalarVal) *scalarVal {
+	base := *x
+	var r scalarVal
+	r[0] = 1
+	for i := 255; i >= 0; i-- {
+		r.mul(&r, &r)
+		if scalarNMinus2[i/64]>>(uint(i)%64)&1 == 1 {
+			r.mul(&r, &base)
+		}
+	}
+	*z = r
+	return z
+}
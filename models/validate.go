@@ -0,0 +1,318 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidCurve = errors.New("models: invalid curve parameters")
+
+// CurveCheck : 曲線のパラメータの検証項目
+type CurveCheck int
+
+const (
+	CheckPrimeP           CurveCheck = iota // pが素数
+	CheckPrimeOrder                         // 位数nが素数
+	CheckDiscriminant                       // 4a^3 + 27b^2 != 0 (mod p)
+	CheckGeneratorOnCurve                   // Gが無限遠点でない曲線上の点
+	CheckGeneratorOrder                     // n*G == 無限遠点
+	CheckHasseBound                         // |#E - (p+1)| <= 2*sqrt(p) となる #E = h*n が存在する
+	CheckMOV                                // 埋め込み次数が大きい (MOV/FR攻撃)
+	CheckAnomalous                          // #E != p (SSSA攻撃)
+)
+
+func (c CurveCheck) String() string {
+	switch c {
+	case CheckPrimeP:
+		return "prime p"
+	case CheckPrimeOrder:
+		return "prime order"
+	case CheckDiscriminant:
+		return "discriminant"
+	case CheckGeneratorOnCurve:
+		return "generator on curve"
+	case CheckGeneratorOrder:
+		return "generator order"
+	case CheckHasseBound:
+		return "Hasse bound"
+	case CheckMOV:
+		return "MOV"
+	case CheckAnomalous:
+		return "anomalous"
+	}
+	return fmt.Sprintf("CurveCheck(%d)", int(c))
+}
+
+// CheckResult : 1つの検証項目の結果
+// 前提となる検証に失敗して確認できなかった場合は Skipped == true で、Passed == false になる
+type CheckResult struct {
+	Check   CurveCheck
+	Passed  bool
+	Skipped bool
+	Message string
+}
+
+func (r CheckResult) String() string {
+	status := "ok"
+	switch {
+	case r.Skipped:
+		status = "skipped"
+	case !r.Passed:
+		status = "failed"
+	}
+	if r.Message == "" {
+		return fmt.Sprintf("%v: %v", r.Check, status)
+	}
+	return fmt.Sprintf("%v: %v (%v)", r.Check, status, r.Message)
+}
+
+// ValidationReport : Validate() の結果
+type ValidationReport struct {
+	Results []CheckResult
+
+	// 群の位数 #E = Cofactor * n
+	// Hasse の定理の範囲にある n の倍数が1つに決まらない場合は nil
+	Cofactor *big.Int
+}
+
+// OK() : すべての検証項目に通ったかどうか
+func (r *ValidationReport) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed() : 通らなかった (確認できなかったものも含む) 検証項目
+func (r *ValidationReport) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, res := range r.Results {
+		if !res.Passed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Result() : 指定した検証項目の結果 (実行していなければ ok == false)
+func (r *ValidationReport) Result(c CurveCheck) (res CheckResult, ok bool) {
+	for _, res := range r.Results {
+		if res.Check == c {
+			return res, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+func (r *ValidationReport) String() string {
+	lines := make([]string, len(r.Results))
+	for i, res := range r.Results {
+		lines[i] = res.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateOption : Validate() で行う検証を増やすオプション
+type ValidateOption func(*validateConfig)
+
+type validateConfig struct {
+	movBound  int // 0 なら MOV の検証をしない
+	anomalous bool
+}
+
+// WithMOVCheck() : 埋め込み次数 k (n | p^k - 1 となる最小のk) が bound 以下ならエラーにする
+// SEC 1 (3.1.1.2.1) では bound = 100 としている
+func WithMOVCheck(bound int) ValidateOption {
+	return func(c *validateConfig) {
+		c.movBound = bound
+	}
+}
+
+// WithAnomalousCheck() : #E == p (anomalous curve) ならエラーにする
+func WithAnomalousCheck() ValidateOption {
+	return func(c *validateConfig) {
+		c.anomalous = true
+	}
+}
+
+// NewEllipticCurveValidated() : パラメータを検証してから曲線を作る
+// どれかの検証に通らなければ、曲線は nil で ErrInvalidCurve を返す
+// 検証の結果は、エラーの有無にかかわらず report に入る
+func NewEllipticCurveValidated(a, b *FiniteField, prime *big.Int, G *EllipticCurvePoint, bitSize int, name string, order *big.Int, opts ...ValidateOption) (ec *EllipticCurve, report *ValidationReport, err error) {
+	if a == nil || b == nil || prime == nil || prime.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("%w: missing a, b or p", ErrInvalidCurve)
+	}
+	if a.Prime.Cmp(prime) != 0 || b.Prime.Cmp(prime) != 0 {
+		return nil, nil, fmt.Errorf("%w: a and b are not in F_p: %v", ErrInvalidCurve, ErrPrimeMismatch)
+	}
+
+	ec = NewEllipticCurve(a, b, prime, G, bitSize, name, order)
+	report = ec.Validate(opts...)
+	if !report.OK() {
+		names := make([]string, 0, len(report.Failed()))
+		for _, res := range report.Failed() {
+			names = append(names, res.Check.String())
+		}
+		return nil, report, fmt.Errorf("%w: %v", ErrInvalidCurve, strings.Join(names, ", "))
+	}
+	return ec, report, nil
+}
+
+// Validate() : 曲線のパラメータを検証する
+// pが素数でない場合は、体の演算が必要な検証は確認できないので Skipped にする
+// n*G == 0 が確かめられた曲線は、以降の ScalarBaseMultP() で k を n で割った余りにして事前計算テーブルを使う
+func (ec *EllipticCurve) Validate(opts ...ValidateOption) *ValidationReport {
+	var config validateConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	report := &ValidationReport{}
+	add := func(c CurveCheck, passed bool, format string, args ...interface{}) {
+		report.Results = append(report.Results, CheckResult{Check: c, Passed: passed, Message: fmt.Sprintf(format, args...)})
+	}
+	skip := func(c CurveCheck, reason string) {
+		report.Results = append(report.Results, CheckResult{Check: c, Skipped: true, Message: reason})
+	}
+
+	p, n := ec.prime, ec.order
+	pPrime := p.Cmp(big.NewInt(3)) > 0 && p.ProbablyPrime(20)
+	nPrime := n != nil && n.ProbablyPrime(20)
+
+	if pPrime {
+		add(CheckPrimeP, true, "")
+	} else {
+		add(CheckPrimeP, false, "p = %v is not a prime greater than 3", p)
+	}
+
+	switch {
+	case n == nil:
+		add(CheckPrimeOrder, false, "order is not set")
+	case nPrime:
+		add(CheckPrimeOrder, true, "")
+	default:
+		add(CheckPrimeOrder, false, "n = %v is not a prime", n)
+	}
+
+	// 4a^3 + 27b^2
+	d := new(big.Int).Exp(ec.a.Value, big.NewInt(3), p)
+	d.Mul(d, big.NewInt(4))
+	d.Add(d, new(big.Int).Mul(big.NewInt(27), new(big.Int).Mul(ec.b.Value, ec.b.Value)))
+	if d.Mod(d, p).Sign() != 0 {
+		add(CheckDiscriminant, true, "")
+	} else {
+		add(CheckDiscriminant, false, "4a^3 + 27b^2 = 0, the curve is singular")
+	}
+
+	if !pPrime {
+		for _, c := range []CurveCheck{CheckGeneratorOnCurve, CheckGeneratorOrder, CheckHasseBound} {
+			skip(c, "p is not a prime")
+		}
+	} else {
+		ec.validateGenerator(add, skip)
+		report.Cofactor = ec.validateHasse(add, skip)
+	}
+
+	if config.movBound > 0 {
+		if !pPrime || !nPrime {
+			skip(CheckMOV, "p or n is not a prime")
+		} else if k, ok := embeddingDegree(p, n, config.movBound); ok {
+			add(CheckMOV, false, "embedding degree %d <= %d", k, config.movBound)
+		} else {
+			add(CheckMOV, true, "embedding degree > %d", config.movBound)
+		}
+	}
+
+	if config.anomalous {
+		// 位数nの部分群があれば n | #E なので、#E == p (素数) なら n == p になる
+		// 逆に n == p なら、Hasse の範囲にある p の倍数は p だけなので #E == p
+		switch {
+		case n == nil:
+			skip(CheckAnomalous, "order is not set")
+		case n.Cmp(p) == 0:
+			add(CheckAnomalous, false, "n == p, the curve is anomalous")
+		default:
+			add(CheckAnomalous, true, "")
+		}
+	}
+
+	return report
+}
+
+// validateGenerator() : G が曲線上の点で、n*G == 無限遠点 となるか
+func (ec *EllipticCurve) validateGenerator(add func(CurveCheck, bool, string, ...interface{}), skip func(CurveCheck, string)) {
+	g := ec.g
+	if g == nil || g.IsZero {
+		add(CheckGeneratorOnCurve, false, "generator is not set")
+		skip(CheckGeneratorOrder, "generator is not set")
+		return
+	}
+	if err := ec.checkPoint(g); err != nil {
+		add(CheckGeneratorOnCurve, false, "%v", err)
+		skip(CheckGeneratorOrder, "generator is not on the curve")
+		return
+	}
+	add(CheckGeneratorOnCurve, true, "")
+
+	if ec.order == nil || ec.order.Sign() <= 0 {
+		skip(CheckGeneratorOrder, "order is not set")
+		return
+	}
+	// ScalarMultP() は secp256k1 などでは k を n で割ってしまうので、汎用の実装で計算する
+	j := jacobianArithmetic{ec: ec}
+	if nG := scalarMult[*jacobianPoint](j, j.fromAffine(g), ec.order.Bytes()); !nG.isZero() {
+		add(CheckGeneratorOrder, false, "n*G is not the point at infinity")
+		return
+	}
+	add(CheckGeneratorOrder, true, "")
+	ec.orderChecked.Store(true)
+}
+
+// validateHasse() : p+1-2*sqrt(p) <= h*n <= p+1+2*sqrt(p) となる h >= 1 が存在するか
+// n > 4*sqrt(p) なら範囲の幅より n が大きいので h は1つに決まり、それを余因子として返す
+func (ec *EllipticCurve) validateHasse(add func(CurveCheck, bool, string, ...interface{}), skip func(CurveCheck, string)) *big.Int {
+	n := ec.order
+	if n == nil || n.Sign() <= 0 {
+		skip(CheckHasseBound, "order is not set")
+		return nil
+	}
+
+	p := ec.prime
+	// floor(2*sqrt(p)) = floor(sqrt(4p))
+	t := new(big.Int).Sqrt(new(big.Int).Lsh(p, 2))
+	pPlus1 := new(big.Int).Add(p, big.NewInt(1))
+	lo := new(big.Int).Sub(pPlus1, t)
+	hi := new(big.Int).Add(pPlus1, t)
+
+	// h = ceil(lo / n)
+	h := new(big.Int).Add(lo, new(big.Int).Sub(n, big.NewInt(1)))
+	h.Div(h, n)
+	if h.Sign() == 0 {
+		h.SetInt64(1)
+	}
+	if new(big.Int).Mul(h, n).Cmp(hi) > 0 {
+		add(CheckHasseBound, false, "no multiple of n in [%v, %v]", lo, hi)
+		return nil
+	}
+	add(CheckHasseBound, true, "")
+
+	// 次の倍数も範囲に入るなら、余因子は決まらない
+	next := new(big.Int).Add(h, big.NewInt(1))
+	if next.Mul(next, n).Cmp(hi) <= 0 {
+		return nil
+	}
+	return h
+}
+
+// embeddingDegree() : n | p^k - 1 となる最小の k (<= bound) を探す
+func embeddingDegree(p, n *big.Int, bound int) (k int, ok bool) {
+	pn := new(big.Int).Mod(p, n)
+	t := new(big.Int).Set(pn)
+	for k := 1; k <= bound; k++ {
+		if t.Cmp(big.NewInt(1)) == 0 {
+			return k, true
+		}
+		t.Mul(t, pn)
+		t.Mod(t, n)
+	}
+	return 0, false
+}
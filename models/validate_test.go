@@ -0,0 +1,172 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func Test_EllipticCurve_Validate(t *testing.T) {
+	toy := func(a, b, p int64, gx, gy int64, n int64) *EllipticCurve {
+		prime := big.NewInt(p)
+		var G *EllipticCurvePoint
+		if gx >= 0 {
+			G = NewEllipticCurvePoint(NewFiniteField(big.NewInt(gx), prime), NewFiniteField(big.NewInt(gy), prime), false)
+		}
+		var order *big.Int
+		if n > 0 {
+			order = big.NewInt(n)
+		}
+		return NewEllipticCurve(NewFiniteField(big.NewInt(a), prime), NewFiniteField(big.NewInt(b), prime), prime, G, 8, "toy", order)
+	}
+
+	all := []ValidateOption{WithMOVCheck(100), WithAnomalousCheck()}
+
+	tests := []struct {
+		name         string
+		ec           *EllipticCurve
+		opts         []ValidateOption
+		wantFailed   []CurveCheck
+		wantSkipped  []CurveCheck
+		wantCofactor *big.Int
+	}{
+		{
+			name:         "secp256k1",
+			ec:           testSecp256k1(),
+			opts:         all,
+			wantCofactor: big.NewInt(1),
+		},
+		{
+			name:         "P-256",
+			ec:           testP256(),
+			opts:         all,
+			wantCofactor: big.NewInt(1),
+		},
+		{
+			// 群の位数は 252 なので、位数7の部分群の余因子は1つに決まらない
+			name: "y^2 = x^3 + 7 over F_223, G = (15, 86)",
+			ec:   toy(0, 7, 223, 15, 86, 7),
+		},
+		{
+			// 223 = 6 (mod 7), 6^2 = 1 (mod 7) なので埋め込み次数は2
+			name:       "y^2 = x^3 + 7 over F_223, embedding degree 2",
+			ec:         toy(0, 7, 223, 15, 86, 7),
+			opts:       all,
+			wantFailed: []CurveCheck{CheckMOV},
+		},
+		{
+			// #E = 223 = p
+			name:         "anomalous y^2 = x^3 + 7x + 1 over F_223",
+			ec:           toy(7, 1, 223, 0, 1, 223),
+			opts:         all,
+			wantFailed:   []CurveCheck{CheckAnomalous},
+			wantCofactor: big.NewInt(1),
+		},
+		{
+			// 尖点を除いた点は加法群 F_p と同型なので、(1, 1) の位数は223
+			name:       "singular y^2 = x^3",
+			ec:         toy(0, 0, 223, 1, 1, 7),
+			wantFailed: []CurveCheck{CheckDiscriminant, CheckGeneratorOrder},
+		},
+		{
+			name:        "p = 221 = 13 * 17",
+			ec:          toy(0, 7, 221, 15, 86, 7),
+			wantFailed:  []CurveCheck{CheckPrimeP},
+			wantSkipped: []CurveCheck{CheckGeneratorOnCurve, CheckGeneratorOrder, CheckHasseBound},
+		},
+		{
+			name:        "generator not on curve",
+			ec:          toy(0, 7, 223, 15, 85, 7),
+			wantFailed:  []CurveCheck{CheckGeneratorOnCurve},
+			wantSkipped: []CurveCheck{CheckGeneratorOrder},
+		},
+		{
+			// (15, 86) の位数は7
+			name:       "wrong order",
+			ec:         toy(0, 7, 223, 15, 86, 11),
+			wantFailed: []CurveCheck{CheckGeneratorOrder},
+		},
+		{
+			name:       "order out of Hasse bound",
+			ec:         toy(0, 7, 223, 15, 86, 263),
+			wantFailed: []CurveCheck{CheckGeneratorOrder, CheckHasseBound},
+		},
+		{
+			name:       "order not prime",
+			ec:         toy(0, 7, 223, 47, 71, 21),
+			wantFailed: []CurveCheck{CheckPrimeOrder},
+		},
+		{
+			name:        "no generator and order",
+			ec:          toy(0, 7, 223, -1, -1, 0),
+			wantFailed:  []CurveCheck{CheckPrimeOrder, CheckGeneratorOnCurve},
+			wantSkipped: []CurveCheck{CheckGeneratorOrder, CheckHasseBound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := tt.ec.Validate(tt.opts...)
+
+			var failed, skipped []CurveCheck
+			for _, res := range report.Failed() {
+				if res.Skipped {
+					skipped = append(skipped, res.Check)
+				} else {
+					failed = append(failed, res.Check)
+				}
+			}
+			if !equalChecks(failed, tt.wantFailed) || !equalChecks(skipped, tt.wantSkipped) {
+				t.Errorf("%v : Validate() failed = %v, skipped = %v, want %v, %v\n%v", tt.name, failed, skipped, tt.wantFailed, tt.wantSkipped, report)
+			}
+			if report.OK() != (len(tt.wantFailed)+len(tt.wantSkipped) == 0) {
+				t.Errorf("%v : Validate().OK() = %v", tt.name, report.OK())
+			}
+
+			if (report.Cofactor == nil) != (tt.wantCofactor == nil) ||
+				(report.Cofactor != nil && report.Cofactor.Cmp(tt.wantCofactor) != 0) {
+				t.Errorf("%v : Validate().Cofactor = %v, want %v", tt.name, report.Cofactor, tt.wantCofactor)
+			}
+		})
+	}
+}
+
+func equalChecks(a, b []CurveCheck) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_NewEllipticCurveValidated(t *testing.T) {
+	prime := big.NewInt(223)
+	a := NewFiniteField(big.NewInt(0), prime)
+	b := NewFiniteField(big.NewInt(7), prime)
+	G := NewEllipticCurvePoint(NewFiniteField(big.NewInt(15), prime), NewFiniteField(big.NewInt(86), prime), false)
+
+	ec, report, err := NewEllipticCurveValidated(a, b, prime, G, 8, "toy", big.NewInt(7))
+	if err != nil || ec == nil || !report.OK() {
+		t.Fatalf("NewEllipticCurveValidated() = %v, %v, %v", ec, report, err)
+	}
+	if ec.Params().Name != "toy" || ec.Params().BitSize != 8 {
+		t.Errorf("NewEllipticCurveValidated() Params() = %+v", ec.Params())
+	}
+
+	ec, report, err = NewEllipticCurveValidated(a, b, prime, G, 8, "toy", big.NewInt(7), WithMOVCheck(10))
+	if !errors.Is(err, ErrInvalidCurve) || ec != nil {
+		t.Fatalf("NewEllipticCurveValidated() with MOV check = %v, %v, want %v", ec, err, ErrInvalidCurve)
+	}
+	if res, ok := report.Result(CheckMOV); !ok || res.Passed {
+		t.Errorf("NewEllipticCurveValidated() report MOV = %v, %v", res, ok)
+	}
+
+	other := NewFiniteField(big.NewInt(7), big.NewInt(227))
+	if _, _, err := NewEllipticCurveValidated(a, other, prime, G, 8, "toy", big.NewInt(7)); !errors.Is(err, ErrInvalidCurve) {
+		t.Errorf("NewEllipticCurveValidated() with b in another field err = %v, want %v", err, ErrInvalidCurve)
+	}
+}
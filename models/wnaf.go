@@ -0,0 +1,181 @@
+package models
+
+import "math/big"
+
+// wnafWindow : wNAF のウィンドウ幅
+// 点ごとに 2^(w-2) 個の奇数倍を事前計算し、加算はおよそ w+1 ビットに1回になる
+const wnafWindow = 5
+
+// wnaf() : k (>= 0) の幅wのNAF表現を下位の桁から返す
+// 各桁は0か、|d| < 2^(w-1) の奇数で、0でない桁の後には少なくとも w-1 個の0が続く
+// k = Σ d_i * 2^i
+func wnaf(k *big.Int, w uint) []int8 {
+	var naf []int8
+	k = new(big.Int).Set(k)
+	mod := int64(1) << w
+	d := new(big.Int)
+
+	for k.Sign() > 0 {
+		var digit int64
+		if k.Bit(0) == 1 {
+			// d = k mod 2^w を (-2^(w-1), 2^(w-1)) に寄せる
+			digit = int64(k.Bits()[0]) & (mod - 1)
+			if digit >= mod/2 {
+				digit -= mod
+			}
+			k.Sub(k, d.SetInt64(digit))
+		}
+		naf = append(naf, int8(digit))
+		k.Rsh(k, 1)
+	}
+	return naf
+}
+
+// oddMultiples() : [P, 3P, 5P, ..., (2^(w-1) - 1)P]
+func oddMultiples[P any](g pointArithmetic[P], p P, w uint) []P {
+	table := make([]P, 1<<(w-2))
+	table[0] = p
+	p2 := g.double(p)
+	for i := 1; i < len(table); i++ {
+		table[i] = g.add(table[i-1], p2)
+	}
+	return table
+}
+
+// strausWNAF() : Σ k_i * P_i を、すべての点で2倍算を共有して求める (interleaved wNAF)
+// 上位の桁から、1回2倍してから各 k_i のwNAFの桁に応じて ±(奇数倍の点) を足す
+// k_i は0以上でなければならない
+func strausWNAF[P any](g pointArithmetic[P], points []P, scalars []*big.Int) P {
+	nafs := make([][]int8, len(points))
+	tables := make([][]P, len(points))
+	maxLen := 0
+	for i := range points {
+		nafs[i] = wnaf(scalars[i], wnafWindow)
+		tables[i] = oddMultiples(g, points[i], wnafWindow)
+		if len(nafs[i]) > maxLen {
+			maxLen = len(nafs[i])
+		}
+	}
+
+	sum := g.zero()
+	for j := maxLen - 1; j >= 0; j-- {
+		sum = g.double(sum)
+		for i, naf := range nafs {
+			if j >= len(naf) || naf[j] == 0 {
+				continue
+			}
+			if d := naf[j]; d > 0 {
+				sum = g.add(sum, tables[i][d/2])
+			} else {
+				sum = g.add(sum, g.neg(tables[i][-d/2]))
+			}
+		}
+	}
+	return sum
+}
+
+// combine() : アフィン座標の点を変換して Σ k_i * P_i を求める
+// 項数が pippengerThreshold 以上なら pippenger()、そうでなければ strausWNAF() を使う
+func combine[P any](g pointArithmetic[P], points []*EllipticCurvePoint, scalars []*big.Int) *EllipticCurvePoint {
+	ps := make([]P, len(points))
+	for i, p := range points {
+		ps[i] = g.fromAffine(p)
+	}
+	if len(ps) >= pippengerThreshold {
+		return g.toAffine(pippenger(g, ps, scalars))
+	}
+	return g.toAffine(strausWNAF(g, ps, scalars))
+}
+
+// linearCombination() : Σ k_i * P_i を求める (k_iはbig-endian)
+// 自己準同型写像が設定されていれば、各項を k1*P + k2*φ(P) の2項に分けてから計算する
+func (ec *EllipticCurve) linearCombination(points []*EllipticCurvePoint, scalars [][]byte) *EllipticCurvePoint {
+	// secp256k1 やGLV法を使う曲線は余因子が1なので、kを位数で割った余りにしてよい
+	reduce := ec.isSecp256k1 || ec.endomorphism != nil
+
+	ps := make([]*EllipticCurvePoint, 0, len(points))
+	ks := make([]*big.Int, 0, len(points))
+	appendTerm := func(p *EllipticCurvePoint, k *big.Int) {
+		if p.IsZero || k.Sign() == 0 {
+			return
+		}
+		// kが負の場合は点の方を反転させる
+		if k.Sign() < 0 {
+			k = new(big.Int).Neg(k)
+			p = NewEllipticCurvePoint(p.X, new(FiniteField).Neg(p.Y), false)
+		}
+		ps = append(ps, p)
+		ks = append(ks, k)
+	}
+
+	for i, p := range points {
+		k := new(big.Int).SetBytes(scalars[i])
+		if reduce {
+			k.Mod(k, ec.order)
+		}
+
+		if ec.endomorphism != nil {
+			k1, k2 := ec.endomorphism.split(k, ec.order)
+			appendTerm(p, k1)
+			appendTerm(ec.endomorphism.apply(p), k2)
+			continue
+		}
+		appendTerm(p, k)
+	}
+
+	if len(ps) == 0 {
+		return NewEllipticCurvePoint(nil, nil, true)
+	}
+	if ec.isSecp256k1 {
+		return combine[secp256k1Point](secp256k1Arithmetic{}, ps, ks)
+	}
+	return combine[*jacobianPoint](jacobianArithmetic{ec: ec}, ps, ks)
+}
+
+// DoubleScalarMult() : u1*(x1, y1) + u2*(x2, y2) を求める (u1, u2はbig-endian)
+func (ec *EllipticCurve) DoubleScalarMult(u1 []byte, x1, y1 *big.Int, u2 []byte, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	return mustCoordinates(ec.DoubleScalarMultChecked(u1, x1, y1, u2, x2, y2))
+}
+
+// DoubleScalarMultChecked() : DoubleScalarMult() と同じだが、panic する代わりにエラーを返す (無限遠点は (0, 0))
+func (ec *EllipticCurve) DoubleScalarMultChecked(u1 []byte, x1, y1 *big.Int, u2 []byte, x2, y2 *big.Int) (*big.Int, *big.Int, error) {
+	p1, err := ec.toPointChecked(x1, y1)
+	if err != nil {
+		return nil, nil, err
+	}
+	p2, err := ec.toPointChecked(x2, y2)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := ec.DoubleScalarMultPChecked(u1, p1, u2, p2)
+	if err != nil {
+		return nil, nil, err
+	}
+	x, y := fromPoint(result)
+	return x, y, nil
+}
+
+// DoubleScalarMultP() : u1*P1 + u2*P2 を求める (u1, u2はbig-endian)
+// 署名検証の u1*G + u2*Q のように、2つのスカラー倍算の2倍算を共有して1回で計算する
+// 2倍算を共有するので、ScalarMultP() を2回呼んで AddP() するよりも速い
+// 実行時間はスカラーに依存するので、秘密のスカラーには使わないこと
+func (ec *EllipticCurve) DoubleScalarMultP(u1 []byte, p1 *EllipticCurvePoint, u2 []byte, p2 *EllipticCurvePoint) *EllipticCurvePoint {
+	return mustPoint(ec.DoubleScalarMultPChecked(u1, p1, u2, p2))
+}
+
+// DoubleScalarMultPChecked() : DoubleScalarMultP() と同じだが、点が曲線上にない場合は panic する代わりにエラーを返す
+func (ec *EllipticCurve) DoubleScalarMultPChecked(u1 []byte, p1 *EllipticCurvePoint, u2 []byte, p2 *EllipticCurvePoint) (*EllipticCurvePoint, error) {
+	if err := ec.checkPoint(p1); err != nil {
+		return nil, err
+	}
+	if err := ec.checkPoint(p2); err != nil {
+		return nil, err
+	}
+
+	return ec.linearCombination([]*EllipticCurvePoint{p1, p2}, [][]byte{u1, u2}), nil
+}
+
+// Generator() : 生成元Gを返す
+func (ec *EllipticCurve) Generator() *EllipticCurvePoint {
+	return new(EllipticCurvePoint).deepCopy(ec.g)
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func Test_wnaf(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	ks := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(15), big.NewInt(16), big.NewInt(31), big.NewInt(0xffff)}
+	for i := 0; i < 50; i++ {
+		ks = append(ks, new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), 256)))
+	}
+
+	for _, w := range []uint{2, 4, 5} {
+		for _, k := range ks {
+			naf := wnaf(k, w)
+
+			got := new(big.Int)
+			zeros := int(w) - 1 // 最初の桁の前には制約がない
+			for i := len(naf) - 1; i >= 0; i-- {
+				d := naf[i]
+				got.Lsh(got, 1)
+				got.Add(got, big.NewInt(int64(d)))
+
+				if d == 0 {
+					zeros++
+					continue
+				}
+				if d%2 == 0 || d >= 1<<(w-1) || d <= -(1<<(w-1)) {
+					t.Fatalf("wnaf(%x, %v) : digit %v is out of range", k, w, d)
+				}
+				if zeros < int(w)-1 {
+					t.Fatalf("wnaf(%x, %v) : nonzero digits are too close", k, w)
+				}
+				zeros = 0
+			}
+
+			if got.Cmp(k) != 0 {
+				t.Errorf("wnaf(%x, %v) = %v, which represents %x", k, w, naf, got)
+			}
+		}
+	}
+}
+
+func Test_EllipticCurve_DoubleScalarMultP(t *testing.T) {
+	glv := testSecp256k1()
+	if err := glv.SetEndomorphism(testSecp256k1Endomorphism()); err != nil {
+		t.Fatal(err)
+	}
+	generic := testSecp256k1()
+	generic.isSecp256k1 = false
+
+	tests := []struct {
+		name string
+		ec   *EllipticCurve
+	}{
+		{name: "secp256k1", ec: testSecp256k1()},
+		{name: "secp256k1 GLV", ec: glv},
+		{name: "secp256k1 FiniteField", ec: generic},
+		{name: "P-256", ec: testP256()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := tt.ec
+			n := ec.order
+			G := ec.Generator()
+			Q := ec.ScalarBaseMultP([]byte{0x12, 0x34})
+			negG := NewEllipticCurvePoint(G.X, new(FiniteField).Neg(G.Y), false)
+			zero := NewEllipticCurvePoint(nil, nil, true)
+
+			r := rand.New(rand.NewSource(8))
+			random := func() []byte {
+				return new(big.Int).Rand(r, n).Bytes()
+			}
+
+			cases := []struct {
+				u1 []byte
+				p1 *EllipticCurvePoint
+				u2 []byte
+				p2 *EllipticCurvePoint
+			}{
+				{u1: random(), p1: G, u2: random(), p2: Q},
+				{u1: random(), p1: G, u2: random(), p2: Q},
+				{u1: random(), p1: Q, u2: random(), p2: Q},
+				{u1: []byte{}, p1: G, u2: random(), p2: Q},
+				{u1: random(), p1: G, u2: []byte{0}, p2: Q},
+				{u1: random(), p1: zero, u2: random(), p2: Q},
+				{u1: n.Bytes(), p1: G, u2: []byte{1}, p2: Q},
+				{u1: []byte{5}, p1: G, u2: []byte{5}, p2: negG}, // 5G - 5G = 0
+				{u1: []byte{5}, p1: G, u2: []byte{3}, p2: G},
+				{u1: new(big.Int).Sub(n, big.NewInt(1)).Bytes(), p1: G, u2: []byte{1}, p2: G}, // (N-1)G + G = 0
+			}
+
+			for _, c := range cases {
+				want := ec.AddP(ec.ScalarMultP(c.p1, c.u1), ec.ScalarMultP(c.p2, c.u2))
+				if got := ec.DoubleScalarMultP(c.u1, c.p1, c.u2, c.p2); !got.equals(want) {
+					t.Errorf("%v : DoubleScalarMultP(%x, %v, %x, %v) = %v, want %v", tt.name, c.u1, c.p1, c.u2, c.p2, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_EllipticCurve_DoubleScalarMultP_ToyCurve(t *testing.T) {
+	prime := big.NewInt(223)
+	ec := NewEllipticCurve(
+		NewFiniteField(big.NewInt(0), prime),
+		NewFiniteField(big.NewInt(7), prime),
+		prime,
+		nil,
+		0,
+		"test elliptic curve",
+		nil,
+	)
+	points := allPoints(ec)
+
+	r := rand.New(rand.NewSource(9))
+	for i := 0; i < 200; i++ {
+		p1 := points[r.Intn(len(points))]
+		p2 := points[r.Intn(len(points))]
+		u1 := big.NewInt(r.Int63n(1000)).Bytes()
+		u2 := big.NewInt(r.Int63n(1000)).Bytes()
+
+		want := ec.AddP(ec.ScalarMultP(p1, u1), ec.ScalarMultP(p2, u2))
+		if got := ec.DoubleScalarMultP(u1, p1, u2, p2); !got.equals(want) {
+			t.Fatalf("DoubleScalarMultP(%x, %v, %x, %v) = %v, want %v", u1, p1, u2, p2, got, want)
+		}
+	}
+}
+
+func BenchmarkEllipticCurve_DoubleScalarMultP(b *testing.B) {
+	u1 := testHex("83ecb3984a4f9ff03e84d5f9c0d7f888a81833643047acc58eb6431e01d9bac8").Bytes()
+	u2 := testHex("5363ad4cc05c30e0a5261c028812645a122e22ea20816678df02967c1b23bd72").Bytes()
+
+	ec := testSecp256k1()
+	glv := testSecp256k1()
+	if err := glv.SetEndomorphism(testSecp256k1Endomorphism()); err != nil {
+		b.Fatal(err)
+	}
+	G := ec.Generator()
+	Q := ec.ScalarBaseMultP([]byte{0x2a})
+	ec.initBaseTable()
+
+	b.Run("wNAF", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ec.DoubleScalarMultP(u1, G, u2, Q)
+		}
+	})
+	b.Run("wNAF GLV", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			glv.DoubleScalarMultP(u1, G, u2, Q)
+		}
+	})
+	b.Run("separate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ec.AddP(ec.ScalarBaseMultP(u1), ec.ScalarMultP(Q, u2))
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package schnorr
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+var (
+	ErrInvalidPrivateKey = errors.New("schnorr: invalid private key")
+	ErrInvalidPublicKey  = errors.New("schnorr: invalid public key")
+	ErrInvalidSignature  = errors.New("schnorr: invalid signature")
+)
+
+// PublicKey : BIP340 の x-only 公開鍵
+// y座標は常に偶数のものとして扱うので、x座標だけを持つ
+type PublicKey struct {
+	Curve *models.EllipticCurve
+	X     *models.FiniteField
+}
+
+// Signature : BIP340 の署名 (R.x, s)
+// R := nonceから作った点 (y座標は偶数)
+// s := k + e*d (mod N)
+type Signature struct {
+	R *models.FiniteField // Rのx座標 (pを法とする)
+	S *models.FiniteField // Nを法とする
+}
+
+// TaggedHash() : BIP340 のタグ付きハッシュ
+// hash_tag(x) = SHA256(SHA256(tag) || SHA256(tag) || x)
+func TaggedHash(tag string, msgs ...[]byte) [32]byte {
+	tagHash := sha256.Sum256([]byte(tag))
+
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, m := range msgs {
+		h.Write(m)
+	}
+
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// coordinateLen() : 座標・スカラー1つ分のバイト長 (secp256k1 では32バイト)
+func coordinateLen(ec *models.EllipticCurve) int {
+	return (ec.Params().P.BitLen() + 7) / 8
+}
+
+// toBytes() : xを固定長のbig-endianバイト列にする
+func toBytes(ec *models.EllipticCurve, x *big.Int) []byte {
+	return x.FillBytes(make([]byte, coordinateLen(ec)))
+}
+
+// isZero() : バイト列がすべて0かどうか (途中で分岐しない)
+func isZero(b []byte) bool {
+	var acc byte
+	for _, c := range b {
+		acc |= c
+	}
+	return acc == 0
+}
+
+// NewPublicKey() : 秘密鍵に対応する x-only 公開鍵を返す
+func NewPublicKey(priv *ecdsa.PrivateKey) *PublicKey {
+	return &PublicKey{Curve: priv.Curve, X: priv.Q.X}
+}
+
+// ParsePublicKey() : 32バイトの x-only 公開鍵をパースする
+// xがp以上の場合や、xを持つ曲線上の点がない場合はエラーになる
+func ParsePublicKey(ec *models.EllipticCurve, b []byte) (*PublicKey, error) {
+	if len(b) != coordinateLen(ec) {
+		return nil, fmt.Errorf("%w: must be %d bytes", ErrInvalidPublicKey, coordinateLen(ec))
+	}
+
+	// lift_x(x)
+	P, ok := ec.LiftX(new(big.Int).SetBytes(b), false)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an x coordinate on the curve", ErrInvalidPublicKey)
+	}
+	return &PublicKey{Curve: ec, X: P.X}, nil
+}
+
+// Bytes() : x-only 公開鍵を32バイトにエンコードする
+func (pub *PublicKey) Bytes() []byte {
+	return toBytes(pub.Curve, pub.X.Value)
+}
+
+// point() : y座標が偶数の点 lift_x(x) を返す
+func (pub *PublicKey) point() (*models.EllipticCurvePoint, bool) {
+	return pub.Curve.LiftX(pub.X.Value, false)
+}
+
+// ParseSignature() : 64バイトの署名 bytes(R.x) || bytes(s) をパースする
+// R.x >= p または s >= N の場合はエラーになる
+func ParseSignature(ec *models.EllipticCurve, b []byte) (*Signature, error) {
+	size := coordinateLen(ec)
+	if len(b) != 2*size {
+		return nil, fmt.Errorf("%w: must be %d bytes", ErrInvalidSignature, 2*size)
+	}
+
+	params := ec.Params()
+	r := new(big.Int).SetBytes(b[:size])
+	s := new(big.Int).SetBytes(b[size:])
+	if r.Cmp(params.P) >= 0 {
+		return nil, fmt.Errorf("%w: r is not less than the field size", ErrInvalidSignature)
+	}
+	if s.Cmp(params.N) >= 0 {
+		return nil, fmt.Errorf("%w: s is not less than the curve order", ErrInvalidSignature)
+	}
+
+	return &Signature{
+		R: models.NewFiniteField(r, params.P),
+		S: models.NewFiniteField(s, params.N),
+	}, nil
+}
+
+// InRange() : xが m を法とする元で、0 <= x < m かどうか
+// ParseSignature() を通さずに作った署名の r, s を確かめるのに使う
+func InRange(x *models.FiniteField, m *big.Int) bool {
+	if x == nil || x.Value == nil || x.Prime == nil || x.Prime.Cmp(m) != 0 {
+		return false
+	}
+	return x.Value.Sign() >= 0 && x.Value.Cmp(m) < 0
+}
+
+// Bytes() : 署名を64バイトにエンコードする
+func (sig *Signature) Bytes() []byte {
+	size := (sig.R.Prime.BitLen() + 7) / 8
+	b := make([]byte, 2*size)
+	sig.R.Value.FillBytes(b[:size])
+	sig.S.Value.FillBytes(b[size:])
+	return b
+}
+
+// challenge() : e = int(hash_BIP0340/challenge(bytes(R.x) || bytes(P.x) || m)) mod N
+func challenge(ec *models.EllipticCurve, rx, px *big.Int, msg []byte) *models.FiniteField {
+	h := TaggedHash("BIP0340/challenge", toBytes(ec, rx), toBytes(ec, px), msg)
+	return models.NewFiniteField(new(big.Int).SetBytes(h[:]), ec.Params().N)
+}
+
+// Sign() : BIP340 の署名を生成する
+// auxRand := nonceに混ぜる32バイトの補助乱数 (用意できない場合は32バイトの0でもよい)
+// d' := 秘密鍵, P := d'*G
+// d := Pのy座標が偶数なら d'、奇数なら N - d'
+// t := bytes(d) xor hash_BIP0340/aux(auxRand)
+// k' := hash_BIP0340/nonce(t || bytes(P.x) || m) mod N, R := k'*G
+// k := Rのy座標が偶数なら k'、奇数なら N - k'
+// e := hash_BIP0340/challenge(bytes(R.x) || bytes(P.x) || m) mod N
+// sig := bytes(R.x) || bytes((k + e*d) mod N)
+// BIP340 は secp256k1 の上でしか定義されていないので、それ以外の曲線の鍵は ErrInvalidPrivateKey
+func Sign(priv *ecdsa.PrivateKey, msg, auxRand []byte) (*Signature, error) {
+	if priv == nil || priv.D == nil || priv.D.IsZero() || priv.Curve == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	if !priv.Curve.IsSecp256k1() {
+		return nil, fmt.Errorf("%w: BIP340 is only defined over secp256k1", ErrInvalidPrivateKey)
+	}
+
+	ec := priv.Curve
+	size := coordinateLen(ec)
+	if len(auxRand) != size {
+		return nil, fmt.Errorf("schnorr: auxRand must be %d bytes", size)
+	}
+
+	// 秘密鍵dとnonce kの計算は、定数時間のスカラー演算で行う
+	// 符号を反転するかどうかは公開されている P, R のy座標で決まるので、分岐してよい
+	n := ec.Params().N
+	minusOne := toBytes(ec, new(big.Int).Sub(n, big.NewInt(1)))
+	P := priv.Q
+	d := toBytes(ec, priv.D.Value)
+	if P.Y.Value.Bit(0) == 1 {
+		d = toBytes(ec, ec.ScalarMulAddConstTime(d, minusOne, nil))
+	}
+
+	t := make([]byte, size)
+	aux := TaggedHash("BIP0340/aux", auxRand)
+	for i := range t {
+		t[i] = d[i] ^ aux[i]
+	}
+
+	// k' = rand mod N
+	rand := TaggedHash("BIP0340/nonce", t, toBytes(ec, P.X.Value), msg)
+	k := toBytes(ec, ec.ScalarMulAddConstTime(rand[:], []byte{1}, nil))
+	if isZero(k) {
+		return nil, errors.New("schnorr: nonce is zero")
+	}
+
+	R := ec.ScalarBaseMultConstTimeP(k)
+	if R.Y.Value.Bit(0) == 1 {
+		k = toBytes(ec, ec.ScalarMulAddConstTime(k, minusOne, nil))
+	}
+
+	e := challenge(ec, R.X.Value, P.X.Value, msg)
+	s := ec.ScalarMulAddConstTime(toBytes(ec, e.Value), d, k)
+
+	sig := &Signature{R: R.X, S: models.NewFiniteField(s, n)}
+
+	// 故障攻撃などで誤った署名を出力しないように検証しておく
+	if !Verify(NewPublicKey(priv), msg, sig) {
+		return nil, errors.New("schnorr: created signature does not verify")
+	}
+	return sig, nil
+}
+
+// Verify() : BIP340 の署名検証
+// P := lift_x(pub)
+// e := hash_BIP0340/challenge(bytes(r) || bytes(P.x) || m) mod N
+// R := s*G - e*P
+//
+//	Rが無限遠点でなく、Rのy座標が偶数で、Rのx座標 == r -> OK
+//
+// secp256k1 以外の曲線の公開鍵や、r >= p, s >= N の署名は false
+func Verify(pub *PublicKey, msg []byte, sig *Signature) bool {
+	if pub == nil || pub.Curve == nil || pub.X == nil || sig == nil {
+		return false
+	}
+
+	ec := pub.Curve
+	if !ec.IsSecp256k1() {
+		return false
+	}
+	params := ec.Params()
+	if !InRange(sig.R, params.P) || !InRange(sig.S, params.N) {
+		return false
+	}
+
+	P, ok := pub.point()
+	if !ok {
+		return false
+	}
+
+	e := challenge(ec, sig.R.Value, P.X.Value, msg)
+	e.Neg(e)
+
+	R := ec.DoubleScalarMultP(sig.S.Value.Bytes(), ec.Generator(), e.Value.Bytes(), P)
+
+	if R.IsZero || R.Y.Value.Bit(0) == 1 {
+		return false
+	}
+	return R.X.Equals(sig.R)
+}
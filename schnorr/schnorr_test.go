@@ -0,0 +1,238 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/matumoto1234/secp256k1/curves"
+	"github.com/matumoto1234/secp256k1/ecdsa"
+	"github.com/matumoto1234/secp256k1/models"
+)
+
+type testVector struct {
+	index     string
+	secretKey string
+	publicKey string
+	auxRand   string
+	message   string
+	signature string
+	result    bool
+	comment   string
+}
+
+// readTestVectors() : BIP340 の公式テストベクタ (test-vectors.csv) を読み込む
+func readTestVectors(t *testing.T) []testVector {
+	f, err := os.Open("testdata/bip340_vectors.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vectors []testVector
+	for _, r := range records[1:] {
+		vectors = append(vectors, testVector{
+			index:     r[0],
+			secretKey: r[1],
+			publicKey: r[2],
+			auxRand:   r[3],
+			message:   r[4],
+			signature: r[5],
+			result:    r[6] == "TRUE",
+			comment:   r[7],
+		})
+	}
+	return vectors
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func Test_Sign_BIP340(t *testing.T) {
+	ec := curves.Secp256k1()
+
+	for _, tt := range readTestVectors(t) {
+		if tt.secretKey == "" {
+			continue
+		}
+
+		t.Run(tt.index, func(t *testing.T) {
+			priv, err := ecdsa.NewPrivateKeyFromHex(ec, tt.secretKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.ToUpper(hex.EncodeToString(NewPublicKey(priv).Bytes())); got != tt.publicKey {
+				t.Errorf("vector %v : public key = %v, want %v", tt.index, got, tt.publicKey)
+			}
+
+			sig, err := Sign(priv, decodeHex(t, tt.message), decodeHex(t, tt.auxRand))
+			if err != nil {
+				t.Fatalf("vector %v : Sign() error = %v", tt.index, err)
+			}
+			if got := strings.ToUpper(hex.EncodeToString(sig.Bytes())); got != tt.signature {
+				t.Errorf("vector %v : Sign() = %v, want %v", tt.index, got, tt.signature)
+			}
+		})
+	}
+}
+
+func Test_Verify_BIP340(t *testing.T) {
+	ec := curves.Secp256k1()
+
+	for _, tt := range readTestVectors(t) {
+		t.Run(tt.index, func(t *testing.T) {
+			got := func() bool {
+				pub, err := ParsePublicKey(ec, decodeHex(t, tt.publicKey))
+				if err != nil {
+					return false
+				}
+				sig, err := ParseSignature(ec, decodeHex(t, tt.signature))
+				if err != nil {
+					return false
+				}
+				return Verify(pub, decodeHex(t, tt.message), sig)
+			}()
+
+			if got != tt.result {
+				t.Errorf("vector %v : Verify() = %v, want %v (%v)", tt.index, got, tt.result, tt.comment)
+			}
+		})
+	}
+}
+
+func Test_SignAndVerify(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := ecdsa.GenerateKey(ec, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aux := make([]byte, 32)
+	if _, err := rand.Read(aux); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("hello")
+	sig, err := Sign(priv, msg, aux)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		msg  []byte
+		want bool
+	}{
+		{name: "same message", msg: msg, want: true},
+		{name: "different message", msg: []byte("hollo"), want: false},
+		{name: "empty message", msg: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(NewPublicKey(priv), tt.msg, sig); got != tt.want {
+				t.Errorf("%v : Verify() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sign_InvalidAuxRand(t *testing.T) {
+	ec := curves.Secp256k1()
+	priv, err := ecdsa.NewPrivateKey(ec, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, aux := range [][]byte{nil, make([]byte, 31), make([]byte, 33)} {
+		if _, err := Sign(priv, []byte("hello"), aux); err == nil {
+			t.Errorf("Sign() with %d bytes of auxRand error = nil, want error", len(aux))
+		}
+	}
+}
+
+// NewFiniteField() や ParseSignature() を通さずに作った範囲外の r, s や、secp256k1 以外の曲線の鍵は受け付けない
+func Test_Verify_Invalid(t *testing.T) {
+	ec := curves.Secp256k1()
+	params := ec.Params()
+	priv, err := ecdsa.NewPrivateKey(ec, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("hello")
+	sig, err := Sign(priv, msg, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := NewPublicKey(priv)
+
+	tests := []struct {
+		name string
+		pub  *PublicKey
+		sig  *Signature
+	}{
+		{name: "s + N", pub: pub, sig: &Signature{R: sig.R, S: &models.FiniteField{Value: new(big.Int).Add(sig.S.Value, params.N), Prime: params.N}}},
+		{name: "r + p", pub: pub, sig: &Signature{R: &models.FiniteField{Value: new(big.Int).Add(sig.R.Value, params.P), Prime: params.P}, S: sig.S}},
+		{name: "negative s", pub: pub, sig: &Signature{R: sig.R, S: &models.FiniteField{Value: new(big.Int).Sub(sig.S.Value, params.N), Prime: params.N}}},
+		{name: "nil r", pub: pub, sig: &Signature{S: sig.S}},
+		{name: "P-256", pub: &PublicKey{Curve: curves.P256(), X: pub.X}, sig: sig},
+	}
+
+	if !Verify(pub, msg, sig) {
+		t.Fatalf("Verify() = false, want true")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if Verify(tt.pub, msg, tt.sig) {
+				t.Errorf("%v : Verify() = true, want false", tt.name)
+			}
+		})
+	}
+}
+
+func Test_Sign_NotSecp256k1(t *testing.T) {
+	priv, err := ecdsa.NewPrivateKey(curves.P256(), big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Sign(priv, []byte("hello"), make([]byte, 32)); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("P-256 : Sign() err = %v, want %v", err, ErrInvalidPrivateKey)
+	}
+}
+
+func Test_TaggedHash(t *testing.T) {
+	tagHash := sha256.Sum256([]byte("BIP0340/challenge"))
+	want := sha256.Sum256(append(append(tagHash[:], tagHash[:]...), "abc"...))
+
+	tests := []struct {
+		name string
+		msgs [][]byte
+	}{
+		{name: "single message", msgs: [][]byte{[]byte("abc")}},
+		{name: "split message", msgs: [][]byte{[]byte("ab"), []byte("c")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TaggedHash("BIP0340/challenge", tt.msgs...); got != want {
+				t.Errorf("%v : TaggedHash() = %x, want %x", tt.name, got, want)
+			}
+		})
+	}
+}